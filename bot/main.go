@@ -1,54 +1,214 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"gofitness/src/api"
+	"gofitness/src/config"
 	"gofitness/src/database"
 	bot "gofitness/src/handler"
-	"log"
+	"gofitness/src/logging"
+	"gofitness/src/metrics"
+	"gofitness/src/ratelimit"
+	"gofitness/src/service/admin"
+	"gofitness/src/service/report"
+	"gofitness/src/service/session"
+	"gofitness/src/web"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
-	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"gopkg.in/telebot.v3"
 )
 
-func init() {
-	_ = godotenv.Load(".env.local", ".env")
-}
-
 func main() {
-	// Загрузка .env файла
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using environment variables")
+	// Настройки из .env, CONFIG_FILE и окружения
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// Уровень (LOG_LEVEL) и формат (LOG_FORMAT=text|json) логов
+	if err := logging.Setup(cfg.Log.Level, cfg.Log.Format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// Управление схемой: bot migrate up|down|status
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(cfg, os.Args[2:]))
+	}
+
+	// Без токена, базы и согласованных настроек не запускаемся
+	if err := cfg.Validate(); err != nil {
+		logging.Fatal("Ошибка в настройках", "err", err)
+	}
+
+	// Ошибки после подключения к базе возвращаются из run, чтобы отработали defer
+	if err := run(cfg); err != nil {
+		logging.Fatal("Бот остановлен с ошибкой", "err", err)
 	}
+	slog.Info("Бот остановлен")
+}
+
+func run(cfg *config.Config) error {
+	// SIGTERM от docker compose down и Ctrl+C запускают плавную остановку
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Дни, недели и месяцы считаем в часовом поясе из настроек
+	time.Local = cfg.Defaults.Location
 
 	// Инициализация базы данных
-	db, err := database.NewPostgres(os.Getenv("DATABASE_URL"))
+	db, err := database.Open(cfg.DatabaseURL, cfg.Pool())
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		return fmt.Errorf("не удалось подключиться к базе данных: %w", err)
 	}
 	defer db.Close()
 
-	// Создание таблиц
+	// Применение миграций
 	if err := db.Init(); err != nil {
-		fmt.Println(err)
-		log.Fatal("Failed to init database:", err)
+		return fmt.Errorf("не удалось применить миграции: %w", err)
 	}
 
+	// Каждая операция с хранилищем ограничена по времени и попадает в метрики
+	storage := metrics.WithStorage(database.WithTimeouts(db, cfg.Database.QueryTimeout, cfg.Database.BulkTimeout))
+
+	// Long polling или вебхук
+	poller, hook, err := newPoller(cfg)
+	if err != nil {
+		return fmt.Errorf("не удалось настроить получение обновлений: %w", err)
+	}
+	// Считаем начатые обработки, чтобы дождаться их при остановке
+	dispatcher := bot.NewDispatcher(poller)
+
+	// Связь с Telegram и хранилищем для /healthz и /readyz
+	health := metrics.NewHealth(storage, hook != nil)
+
+	// Исходящие сообщения ждут своей очереди в пределах лимитов Telegram
+	transport := health.Transport(ratelimit.Transport(http.DefaultTransport, cfg.Limits.OutboundPerSecond, cfg.Limits.ChatPerMinute))
+
 	// Настройки бота
 	pref := telebot.Settings{
-		Token:  os.Getenv("BOT_TOKEN"),
-		Poller: &telebot.LongPoller{Timeout: 10},
+		Token:       cfg.BotToken,
+		Poller:      dispatcher,
+		Synchronous: true,
+		Client:      &http.Client{Timeout: time.Minute, Transport: transport},
+		OnError:     bot.OnError,
 	}
 
 	// Создание бота
 	b, err := telebot.NewBot(pref)
 	if err != nil {
-		log.Fatal("Failed to create bot:", err)
+		return fmt.Errorf("не удалось создать бота: %w", err)
+	}
+
+	// Зарегистрированный ранее вебхук не даёт получать обновления через long polling
+	if hook == nil {
+		if err := b.RemoveWebhook(); err != nil {
+			slog.Warn("Не удалось удалить вебхук", "err", err)
+		}
 	}
 
-	// Обработчики
-	bot.SetupHandlers(b, db)
+	// Вход в веб-кабинет по одноразовым ссылкам из /web
+	sessions := session.NewSessionService(storage, cfg.PublicURL)
+
+	// Рассылки администраторов идут в фоне, по очереди
+	broadcaster := admin.NewBroadcaster(b, storage, cfg.Limits.BroadcastPerSecond)
+	broadcaster.Start()
+
+	// Обработчики и диалоги, прерванные прошлой остановкой
+	bot.SetupHandlers(b, storage, sessions, broadcaster, cfg)
+	if err := bot.RegisterCommands(b, cfg); err != nil {
+		// Без меню бот работает, команды по-прежнему перечислены в /start
+		slog.Warn("Не удалось зарегистрировать меню команд", "err", err)
+	}
+	if n, err := bot.RestoreConversations(ctx, storage); err != nil {
+		slog.Error("Не удалось восстановить диалоги", "err", err)
+	} else if n > 0 {
+		slog.Info("Диалоги восстановлены", "count", n)
+	}
+
+	// Еженедельные и ежемесячные отчёты
+	var reports *report.Scheduler
+	if cfg.Features.Reports {
+		reports = report.NewScheduler(b, report.NewReportService(storage))
+		reports.Start()
+	}
+
+	// HTTP API, веб-кабинет, метрики и проверки состояния
+	mux := http.NewServeMux()
+	if cfg.Features.API {
+		mux.Handle("/api/", api.NewServer(storage))
+	}
+	if cfg.Features.Web {
+		mux.Handle("/", web.NewServer(storage, sessions))
+	}
+	if cfg.Features.Metrics {
+		mux.Handle("/metrics", promhttp.Handler())
+	}
+	mux.HandleFunc("/healthz", health.Liveness)
+	mux.HandleFunc("/readyz", health.Readiness)
+	if hook != nil && hook.Listen() == "" {
+		mux.Handle(hook.Path(), hook)
+	}
+	server := &http.Server{
+		Addr:              cfg.HTTPAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("HTTP-сервер запущен", "addr", cfg.HTTPAddr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	go b.Start()
+	slog.Info("Бот запущен")
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		slog.Info("Получен сигнал остановки", "timeout", cfg.ShutdownTimeout)
+	case err := <-serverErr:
+		runErr = fmt.Errorf("HTTP-сервер остановился: %w", err)
+	}
+	// Повторный сигнал завершает процесс сразу, не дожидаясь обработчиков
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	// Больше не берём обновления: необработанные Telegram пришлёт после перезапуска
+	b.Stop()
+	if reports != nil {
+		reports.Stop()
+	}
+	broadcaster.Stop()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("HTTP-сервер не дождался завершения запросов", "err", err)
+	}
+	if err := dispatcher.Wait(shutdownCtx); err != nil {
+		slog.Warn("Не все обработчики успели завершиться", "err", err)
+	}
+
+	// Диалоги сохраняем после обработчиков, с отдельным сроком:
+	// общий мог истечь в ожидании
+	saveCtx, cancelSave := context.WithTimeout(context.Background(), cfg.Database.QueryTimeout)
+	defer cancelSave()
+	if n, err := bot.SaveConversations(saveCtx, storage); err != nil {
+		slog.Error("Не удалось сохранить диалоги", "err", err)
+	} else if n > 0 {
+		slog.Info("Диалоги сохранены", "count", n)
+	}
 
-	log.Println("Bot started...")
-	b.Start()
+	return runErr
 }
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"gofitness/src/config"
+	"gofitness/src/database"
+	"os"
+)
+
+const migrateUsage = "использование: bot migrate up|down|status"
+
+// runMigrate выполняет подкоманду migrate и возвращает код выхода
+func runMigrate(cfg *config.Config, args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	if err := cfg.ValidateDatabase(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	storage, err := database.Open(cfg.DatabaseURL, cfg.Pool())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to connect to database:", err)
+		return 1
+	}
+	defer storage.Close()
+
+	db, ok := storage.(database.Migrator)
+	if !ok {
+		fmt.Fprintln(os.Stderr, "Это хранилище не использует миграции")
+		return 1
+	}
+
+	switch args[0] {
+	case "up":
+		count, err := db.MigrateUp()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Printf("Применено миграций: %d\n", count)
+	case "down":
+		m, err := db.MigrateDown()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if m == nil {
+			fmt.Println("Нет применённых миграций")
+			return 0
+		}
+		fmt.Printf("Откачена миграция %04d_%s\n", m.Version, m.Name)
+	case "status":
+		statuses, err := db.MigrationStatus()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		for _, s := range statuses {
+			applied := "не применена"
+			if s.Applied {
+				applied = "применена " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-24s %s\n", s.Version, s.Name, applied)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	return 0
+}
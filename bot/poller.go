@@ -0,0 +1,22 @@
+package main
+
+import (
+	"gofitness/src/config"
+	"gofitness/src/webhook"
+
+	"gopkg.in/telebot.v3"
+)
+
+// newPoller выбирает источник обновлений по настройкам: polling (по умолчанию)
+// или webhook. Для вебхука возвращается и он сам, чтобы подключить его к HTTP-серверу.
+func newPoller(cfg *config.Config) (telebot.Poller, *webhook.Poller, error) {
+	if cfg.Poller.Mode != "webhook" {
+		return &telebot.LongPoller{Timeout: cfg.Poller.Timeout}, nil, nil
+	}
+
+	hook, err := webhook.New(cfg.WebhookConfig())
+	if err != nil {
+		return nil, nil, err
+	}
+	return hook, hook, nil
+}
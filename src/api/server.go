@@ -0,0 +1,435 @@
+package api
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"gofitness/src/database"
+	"gofitness/src/model"
+	"gofitness/src/service/token"
+	userservice "gofitness/src/service/user"
+	"gofitness/src/service/workout"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Описание API в формате OpenAPI 3
+//
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// Максимальный размер тела запроса
+const maxBodySize = 1 << 20
+
+// Server — JSON API поверх тех же сервисов, что использует бот.
+// Все маршруты, кроме описания API, требуют заголовок Authorization: Bearer <токен из /token>.
+type Server struct {
+	tokens   *token.TokenService
+	workouts *workout.WorkoutService
+	users    *userservice.UserService
+	mux      *http.ServeMux
+}
+
+// Repository — хранилище, нужное API: токены, пользователи, упражнения и подходы
+type Repository interface {
+	database.TokenRepository
+	userservice.Repository
+}
+
+func NewServer(db Repository) *Server {
+	s := &Server{
+		tokens:   token.NewTokenService(db),
+		workouts: workout.NewWorkoutService(db),
+		users:    userservice.NewUserService(db),
+		mux:      http.NewServeMux(),
+	}
+	s.mux.HandleFunc("/api/v1/openapi.yaml", s.handleSpec)
+	s.mux.Handle("/api/v1/", s.authenticate(http.HandlerFunc(s.route)))
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+type userKey struct{}
+
+// Владелец токена, проверенного authenticate
+func userFrom(ctx context.Context) *model.User {
+	user, _ := ctx.Value(userKey{}).(*model.User)
+	return user
+}
+
+// Проверяем токен и кладём пользователя в контекст запроса
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || raw == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="gofitness"`)
+			writeError(w, http.StatusUnauthorized, "нужен заголовок Authorization: Bearer <токен>; получить токен — команда /token в боте")
+			return
+		}
+
+		user, err := s.tokens.Authenticate(r.Context(), strings.TrimSpace(raw))
+		if err != nil {
+			s.fail(w, err)
+			return
+		}
+		if user == nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="gofitness", error="invalid_token"`)
+			writeError(w, http.StatusUnauthorized, "токен недействителен или отозван")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userKey{}, user)))
+	})
+}
+
+// Разбираем путь вручную: маршрутизатор Go 1.21 не знает параметров в пути
+func (s *Server) route(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/"), "/"), "/")
+
+	switch {
+	case len(parts) == 1 && parts[0] == "exercises":
+		allow(w, r, map[string]http.HandlerFunc{http.MethodGet: s.listExercises})
+	case len(parts) == 3 && parts[0] == "exercises" && parts[2] == "progress":
+		allow(w, r, map[string]http.HandlerFunc{http.MethodGet: withID(parts[1], s.exerciseProgress)})
+	case len(parts) == 1 && parts[0] == "sets":
+		allow(w, r, map[string]http.HandlerFunc{
+			http.MethodGet:  s.listSets,
+			http.MethodPost: s.createSet,
+		})
+	case len(parts) == 2 && parts[0] == "sets":
+		allow(w, r, map[string]http.HandlerFunc{
+			http.MethodGet:    withID(parts[1], s.getSet),
+			http.MethodPut:    withID(parts[1], s.updateSet),
+			http.MethodDelete: withID(parts[1], s.deleteSet),
+		})
+	case len(parts) == 1 && parts[0] == "records":
+		allow(w, r, map[string]http.HandlerFunc{http.MethodGet: s.listRecords})
+	case len(parts) == 1 && parts[0] == "stats":
+		allow(w, r, map[string]http.HandlerFunc{http.MethodGet: s.getStats})
+	default:
+		writeError(w, http.StatusNotFound, "маршрут не найден")
+	}
+}
+
+// Выбираем обработчик по методу; для остальных методов — 405
+func allow(w http.ResponseWriter, r *http.Request, handlers map[string]http.HandlerFunc) {
+	if h, ok := handlers[r.Method]; ok {
+		h(w, r)
+		return
+	}
+
+	methods := make([]string, 0, len(handlers))
+	for _, m := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete} {
+		if _, ok := handlers[m]; ok {
+			methods = append(methods, m)
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeError(w, http.StatusMethodNotAllowed, "метод не поддерживается")
+}
+
+// Обработчик ресурса с числовым ID из пути
+func withID(raw string, h func(w http.ResponseWriter, r *http.Request, id int)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(raw)
+		if err != nil || id <= 0 {
+			writeError(w, http.StatusNotFound, "некорректный ID")
+			return
+		}
+		h(w, r, id)
+	}
+}
+
+func (s *Server) handleSpec(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeError(w, http.StatusMethodNotAllowed, "метод не поддерживается")
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(openAPISpec)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		slog.Error("Ошибка записи ответа API", "err", err)
+	}
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
+
+// Ответ на ошибку сервиса
+func (s *Server) fail(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, workout.ErrNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, workout.ErrInvalid):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, database.ErrTimeout) || errors.Is(err, context.DeadlineExceeded):
+		w.Header().Set("Retry-After", "60")
+		writeError(w, http.StatusServiceUnavailable, "сервер сейчас отвечает слишком долго, попробуй позже")
+	default:
+		slog.Error("Ошибка API", "err", err)
+		writeError(w, http.StatusInternalServerError, "внутренняя ошибка")
+	}
+}
+
+// Числовой параметр запроса; отсутствующий — 0
+func queryInt(r *http.Request, name string) (int, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return 0, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		return 0, errors.New("параметр " + name + " должен быть неотрицательным целым числом")
+	}
+	return value, nil
+}
+
+type exerciseResponse struct {
+	ID               int      `json:"id"`
+	Name             string   `json:"name"`
+	Description      string   `json:"description"`
+	IsStandard       bool     `json:"is_standard"`
+	PrimaryMuscles   []string `json:"primary_muscles"`
+	SecondaryMuscles []string `json:"secondary_muscles"`
+}
+
+func muscles(groups []model.MuscleGroup) []string {
+	names := make([]string, 0, len(groups))
+	for _, g := range groups {
+		names = append(names, string(g))
+	}
+	return names
+}
+
+func newExerciseResponse(ex model.Exercise) exerciseResponse {
+	return exerciseResponse{
+		ID:               ex.ID,
+		Name:             ex.Name,
+		Description:      ex.Description,
+		IsStandard:       ex.IsStandard,
+		PrimaryMuscles:   muscles(ex.PrimaryMuscles),
+		SecondaryMuscles: muscles(ex.SecondaryMuscles),
+	}
+}
+
+func (s *Server) listExercises(w http.ResponseWriter, r *http.Request) {
+	exercises, err := s.workouts.Exercises(r.Context(), userFrom(r.Context()).ID)
+	if err != nil {
+		s.fail(w, err)
+		return
+	}
+
+	resp := make([]exerciseResponse, 0, len(exercises))
+	for _, ex := range exercises {
+		resp = append(resp, newExerciseResponse(ex))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+type progressResponse struct {
+	Exercise exerciseResponse      `json:"exercise"`
+	Days     int                   `json:"days"`
+	Points   []model.ProgressPoint `json:"points"`
+}
+
+func (s *Server) exerciseProgress(w http.ResponseWriter, r *http.Request, id int) {
+	days, err := queryInt(r, "days")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if days == 0 {
+		days = workout.DefaultDays
+	}
+	if days > workout.MaxDays {
+		days = workout.MaxDays
+	}
+
+	ex, points, err := s.workouts.Progress(r.Context(), userFrom(r.Context()).ID, id, days)
+	if err != nil {
+		s.fail(w, err)
+		return
+	}
+	if points == nil {
+		points = []model.ProgressPoint{}
+	}
+	writeJSON(w, http.StatusOK, progressResponse{Exercise: newExerciseResponse(*ex), Days: days, Points: points})
+}
+
+type setResponse struct {
+	ID           int       `json:"id"`
+	ExerciseID   int       `json:"exercise_id"`
+	ExerciseName string    `json:"exercise_name"`
+	Weight       float64   `json:"weight"`
+	Reps         int       `json:"reps"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func newSetResponse(set model.WorkoutSet) setResponse {
+	return setResponse{
+		ID:           set.ID,
+		ExerciseID:   set.ExerciseID,
+		ExerciseName: set.ExerciseName,
+		Weight:       set.Weight,
+		Reps:         set.Reps,
+		CreatedAt:    set.CreatedAt,
+	}
+}
+
+// Тело POST и PUT /sets; created_at можно не указывать
+type setRequest struct {
+	ExerciseID int        `json:"exercise_id"`
+	Weight     float64    `json:"weight"`
+	Reps       int        `json:"reps"`
+	CreatedAt  *time.Time `json:"created_at"`
+}
+
+// Читаем подход из тела запроса
+func decodeSet(w http.ResponseWriter, r *http.Request) (model.WorkoutSet, bool) {
+	var req setRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "некорректный JSON: "+err.Error())
+		return model.WorkoutSet{}, false
+	}
+
+	set := model.WorkoutSet{
+		UserID:     userFrom(r.Context()).ID,
+		ExerciseID: req.ExerciseID,
+		Weight:     req.Weight,
+		Reps:       req.Reps,
+	}
+	if req.CreatedAt != nil {
+		set.CreatedAt = req.CreatedAt.Local()
+	}
+	return set, true
+}
+
+func (s *Server) listSets(w http.ResponseWriter, r *http.Request) {
+	limit, err := queryInt(r, "limit")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sets, err := s.workouts.Sets(r.Context(), userFrom(r.Context()).ID, limit)
+	if err != nil {
+		s.fail(w, err)
+		return
+	}
+
+	resp := make([]setResponse, 0, len(sets))
+	for _, set := range sets {
+		resp = append(resp, newSetResponse(set))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) createSet(w http.ResponseWriter, r *http.Request) {
+	set, ok := decodeSet(w, r)
+	if !ok {
+		return
+	}
+
+	created, err := s.workouts.CreateSet(r.Context(), set)
+	if err != nil {
+		s.fail(w, err)
+		return
+	}
+	w.Header().Set("Location", "/api/v1/sets/"+strconv.Itoa(created.ID))
+	writeJSON(w, http.StatusCreated, newSetResponse(*created))
+}
+
+func (s *Server) getSet(w http.ResponseWriter, r *http.Request, id int) {
+	set, err := s.workouts.Set(r.Context(), userFrom(r.Context()).ID, id)
+	if err != nil {
+		s.fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newSetResponse(*set))
+}
+
+func (s *Server) updateSet(w http.ResponseWriter, r *http.Request, id int) {
+	set, ok := decodeSet(w, r)
+	if !ok {
+		return
+	}
+	set.ID = id
+
+	updated, err := s.workouts.UpdateSet(r.Context(), set)
+	if err != nil {
+		s.fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newSetResponse(*updated))
+}
+
+func (s *Server) deleteSet(w http.ResponseWriter, r *http.Request, id int) {
+	if err := s.workouts.DeleteSet(r.Context(), userFrom(r.Context()).ID, id); err != nil {
+		s.fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type recordResponse struct {
+	ExerciseID   int     `json:"exercise_id"`
+	ExerciseName string  `json:"exercise_name"`
+	MaxWeight    float64 `json:"max_weight"`
+	MaxReps      int     `json:"max_reps"`
+	BestE1RM     float64 `json:"best_e1rm"`
+	Sets         int     `json:"sets"`
+	Tonnage      float64 `json:"tonnage"`
+}
+
+func (s *Server) listRecords(w http.ResponseWriter, r *http.Request) {
+	bests, err := s.workouts.Records(r.Context(), userFrom(r.Context()).ID)
+	if err != nil {
+		s.fail(w, err)
+		return
+	}
+
+	resp := make([]recordResponse, 0, len(bests))
+	for _, b := range bests {
+		resp = append(resp, recordResponse{
+			ExerciseID:   b.ExerciseID,
+			ExerciseName: b.ExerciseName,
+			MaxWeight:    b.MaxWeight,
+			MaxReps:      b.MaxReps,
+			BestE1RM:     b.BestE1RM,
+			Sets:         b.Sets,
+			Tonnage:      b.Tonnage,
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) getStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := s.users.GetUserStats(r.Context(), userFrom(r.Context()).ID)
+	if err != nil {
+		s.fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
@@ -0,0 +1,254 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"gofitness/src/database"
+	"gofitness/src/model"
+	"gofitness/src/service/token"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Тестовое окружение: API поверх хранилища в памяти
+type harness struct {
+	t      *testing.T
+	db     *database.Memory
+	server *Server
+}
+
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+	db := database.NewMemory()
+	if err := db.Init(); err != nil {
+		t.Fatalf("не удалось подготовить хранилище: %v", err)
+	}
+	return &harness{t: t, db: db, server: NewServer(db)}
+}
+
+// Пользователь с выданным токеном
+func (h *harness) user(chatID int64) (*model.User, string) {
+	h.t.Helper()
+	ctx := context.Background()
+	user, err := h.db.GetOrCreateUser(ctx, chatID, "user"+strconv.FormatInt(chatID, 10))
+	if err != nil {
+		h.t.Fatalf("не удалось создать пользователя: %v", err)
+	}
+	raw, err := token.NewTokenService(h.db).Issue(ctx, user)
+	if err != nil {
+		h.t.Fatalf("не удалось выдать токен: %v", err)
+	}
+	return user, raw
+}
+
+// Любое стандартное упражнение
+func (h *harness) exerciseID() int {
+	h.t.Helper()
+	exercises, err := h.db.GetExercises(context.Background(), 0)
+	if err != nil || len(exercises) == 0 {
+		h.t.Fatalf("нет стандартных упражнений: %v", err)
+	}
+	return exercises[0].ID
+}
+
+func (h *harness) do(method, path, bearer, body string) *httptest.ResponseRecorder {
+	h.t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	h.server.ServeHTTP(rec, req)
+	return rec
+}
+
+// Создаём подход через API и возвращаем его ID
+func (h *harness) createSet(bearer string, exerciseID int) int {
+	h.t.Helper()
+	rec := h.do(http.MethodPost, "/api/v1/sets", bearer, `{"exercise_id": `+strconv.Itoa(exerciseID)+`, "weight": 60, "reps": 8}`)
+	if rec.Code != http.StatusCreated {
+		h.t.Fatalf("POST /sets: код %d, тело %s", rec.Code, rec.Body)
+	}
+	var set setResponse
+	if err := json.NewDecoder(rec.Body).Decode(&set); err != nil {
+		h.t.Fatalf("некорректный ответ POST /sets: %v", err)
+	}
+	return set.ID
+}
+
+func TestAuthentication(t *testing.T) {
+	h := newHarness(t)
+	_, raw := h.user(100)
+
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{"без заголовка", ""},
+		{"не Bearer", "Basic dXNlcjpwYXNz"},
+		{"пустой токен", "Bearer "},
+		{"чужой префикс", "Bearer xx_" + strings.TrimPrefix(raw, token.Prefix)},
+		{"неизвестный токен", "Bearer " + token.Prefix + "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/sets", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			h.server.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("код %d, ожидался 401", rec.Code)
+			}
+			if rec.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("нет заголовка WWW-Authenticate")
+			}
+		})
+	}
+
+	if rec := h.do(http.MethodGet, "/api/v1/sets", raw, ""); rec.Code != http.StatusOK {
+		t.Fatalf("с действующим токеном код %d, ожидался 200", rec.Code)
+	}
+}
+
+func TestBannedOwner(t *testing.T) {
+	h := newHarness(t)
+	user, raw := h.user(100)
+
+	if err := h.db.SetUserBanned(context.Background(), user.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if rec := h.do(http.MethodGet, "/api/v1/sets", raw, ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("токен заблокированного пользователя: код %d, ожидался 401", rec.Code)
+	}
+
+	// После снятия блокировки токен снова действует
+	if err := h.db.SetUserBanned(context.Background(), user.ID, false); err != nil {
+		t.Fatal(err)
+	}
+	if rec := h.do(http.MethodGet, "/api/v1/sets", raw, ""); rec.Code != http.StatusOK {
+		t.Fatalf("после разблокировки код %d, ожидался 200", rec.Code)
+	}
+}
+
+func TestForeignSet(t *testing.T) {
+	h := newHarness(t)
+	_, owner := h.user(100)
+	_, other := h.user(200)
+	path := "/api/v1/sets/" + strconv.Itoa(h.createSet(owner, h.exerciseID()))
+	body := `{"exercise_id": ` + strconv.Itoa(h.exerciseID()) + `, "weight": 100, "reps": 1}`
+
+	tests := []struct {
+		method string
+		body   string
+	}{
+		{http.MethodGet, ""},
+		{http.MethodPut, body},
+		{http.MethodDelete, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			if rec := h.do(tt.method, path, other, tt.body); rec.Code != http.StatusNotFound {
+				t.Fatalf("чужой подход: код %d, ожидался 404, тело %s", rec.Code, rec.Body)
+			}
+		})
+	}
+
+	// Подход владельца не изменился и не удалён
+	rec := h.do(http.MethodGet, path, owner, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("владелец: код %d, ожидался 200", rec.Code)
+	}
+	var set setResponse
+	if err := json.NewDecoder(rec.Body).Decode(&set); err != nil {
+		t.Fatal(err)
+	}
+	if set.Weight != 60 || set.Reps != 8 {
+		t.Errorf("подход владельца изменён: %+v", set)
+	}
+
+	if rec := h.do(http.MethodGet, "/api/v1/sets/999999", owner, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("несуществующий подход: код %d, ожидался 404", rec.Code)
+	}
+	if rec := h.do(http.MethodGet, "/api/v1/sets/abc", owner, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("некорректный ID: код %d, ожидался 404", rec.Code)
+	}
+}
+
+func TestCreateSetValidation(t *testing.T) {
+	h := newHarness(t)
+	user, raw := h.user(100)
+	exerciseID := strconv.Itoa(h.exerciseID())
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"некорректный JSON", `{"exercise_id": `},
+		{"неизвестное поле", `{"exercise_id": ` + exerciseID + `, "weight": 60, "reps": 8, "sets": 3}`},
+		{"без повторений", `{"exercise_id": ` + exerciseID + `, "weight": 60, "reps": 0}`},
+		{"отрицательный вес", `{"exercise_id": ` + exerciseID + `, "weight": -5, "reps": 8}`},
+		{"время в будущем", `{"exercise_id": ` + exerciseID + `, "weight": 60, "reps": 8, "created_at": "2999-01-01T00:00:00Z"}`},
+		{"неизвестное упражнение", `{"exercise_id": 999999, "weight": 60, "reps": 8}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := h.do(http.MethodPost, "/api/v1/sets", raw, tt.body)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("код %d, ожидался 400, тело %s", rec.Code, rec.Body)
+			}
+			var resp errorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || resp.Error == "" {
+				t.Errorf("нет описания ошибки в ответе: %v", err)
+			}
+		})
+	}
+
+	sets, err := h.db.GetUserWorkoutHistory(context.Background(), user.ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sets) != 0 {
+		t.Errorf("сохранены некорректные подходы: %+v", sets)
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	h := newHarness(t)
+	_, raw := h.user(100)
+
+	tests := []struct {
+		method string
+		path   string
+		allow  string
+	}{
+		{http.MethodDelete, "/api/v1/sets", "GET, POST"},
+		{http.MethodPost, "/api/v1/sets/1", "GET, PUT, DELETE"},
+		{http.MethodPost, "/api/v1/exercises", "GET"},
+		{http.MethodPut, "/api/v1/records", "GET"},
+		{http.MethodPost, "/api/v1/openapi.yaml", "GET"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			rec := h.do(tt.method, tt.path, raw, "")
+			if rec.Code != http.StatusMethodNotAllowed {
+				t.Fatalf("код %d, ожидался 405", rec.Code)
+			}
+			if got := rec.Header().Get("Allow"); got != tt.allow {
+				t.Errorf("Allow %q, ожидался %q", got, tt.allow)
+			}
+		})
+	}
+}
@@ -0,0 +1,401 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"gofitness/src/database"
+	"gofitness/src/logging"
+	"gofitness/src/webhook"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Единицы веса по умолчанию
+const (
+	UnitsKg  = "kg"
+	UnitsLbs = "lbs"
+)
+
+// Config — настройки бота. Значения берутся по умолчанию, затем из YAML-файла
+// (CONFIG_FILE), затем из переменных окружения: окружение важнее файла
+type Config struct {
+	BotToken    string `yaml:"bot_token"`
+	DatabaseURL string `yaml:"database_url"`
+	// Адрес HTTP API, веб-кабинета, метрик и проверок состояния
+	HTTPAddr string `yaml:"http_addr"`
+	// Внешний адрес для ссылок входа в веб-кабинет
+	PublicURL string `yaml:"public_url"`
+	// Сколько при остановке ждать начатые обработчики и HTTP-запросы
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// Chat ID администраторов: им доступна команда /admin
+	Admins []int64 `yaml:"admins"`
+
+	Log      Log      `yaml:"log"`
+	Poller   Poller   `yaml:"poller"`
+	Webhook  Webhook  `yaml:"webhook"`
+	Database Database `yaml:"database"`
+	Defaults Defaults `yaml:"defaults"`
+	Features Features `yaml:"features"`
+	Limits   Limits   `yaml:"limits"`
+}
+
+type Log struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+}
+
+// Poller — способ получения обновлений: polling или webhook
+type Poller struct {
+	Mode string `yaml:"mode"`
+	// Сколько Telegram держит запрос getUpdates без новых обновлений
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+type Webhook struct {
+	Listen      string `yaml:"listen"`
+	URL         string `yaml:"url"`
+	SecretToken string `yaml:"secret_token"`
+	TLSCert     string `yaml:"tls_cert"`
+	TLSKey      string `yaml:"tls_key"`
+}
+
+type Database struct {
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	// Ограничения времени одной операции и массовых операций
+	QueryTimeout time.Duration `yaml:"query_timeout"`
+	BulkTimeout  time.Duration `yaml:"bulk_timeout"`
+}
+
+// Defaults — единицы веса и часовой пояс, в котором считаются дни и недели
+type Defaults struct {
+	Units    string `yaml:"units"`
+	TimeZone string `yaml:"time_zone"`
+
+	Location *time.Location `yaml:"-"`
+}
+
+// Features — отключаемые части бота
+type Features struct {
+	API     bool `yaml:"api"`
+	Web     bool `yaml:"web"`
+	Reports bool `yaml:"reports"`
+	Metrics bool `yaml:"metrics"`
+}
+
+// Limits — ограничения частоты. Входящие: сколько обновлений в минуту
+// и сколько подряд принимаем от одного пользователя (0 — без ограничения).
+// Исходящие: сколько сообщений бот отправляет в секунду всего
+// и в минуту в один личный чат
+type Limits struct {
+	// Обычные сообщения, кнопки и команды
+	TextPerMinute int `yaml:"text_per_minute"`
+	TextBurst     int `yaml:"text_burst"`
+	// Графики, выгрузки и загрузка файлов
+	HeavyPerMinute int `yaml:"heavy_per_minute"`
+	HeavyBurst     int `yaml:"heavy_burst"`
+
+	OutboundPerSecond int `yaml:"outbound_per_second"`
+	ChatPerMinute     int `yaml:"chat_per_minute"`
+	// Рассылка администратора занимает только часть исходящего лимита,
+	// чтобы бот успевал отвечать остальным
+	BroadcastPerSecond int `yaml:"broadcast_per_second"`
+}
+
+// HTTP-клиент бота ждёт ответа Telegram не дольше минуты,
+// long polling должен укладываться в это время с запасом
+const maxPollTimeout = 50 * time.Second
+
+// Default возвращает настройки по умолчанию
+func Default() *Config {
+	return &Config{
+		HTTPAddr:        ":8080",
+		ShutdownTimeout: 20 * time.Second,
+		Log:             Log{Level: "info", Format: "text"},
+		Poller:          Poller{Mode: "polling", Timeout: 10 * time.Second},
+		Database: Database{
+			MaxOpenConns:    database.DefaultPool.MaxOpenConns,
+			MaxIdleConns:    database.DefaultPool.MaxIdleConns,
+			ConnMaxLifetime: database.DefaultPool.ConnMaxLifetime,
+			QueryTimeout:    database.QueryTimeout,
+			BulkTimeout:     database.BulkTimeout,
+		},
+		Defaults: Defaults{Units: UnitsKg, Location: time.Local},
+		Features: Features{API: true, Web: true, Reports: true, Metrics: true},
+		// Лимиты Telegram: 30 сообщений в секунду и около одного в секунду в чат
+		Limits: Limits{
+			TextPerMinute:      60,
+			TextBurst:          10,
+			HeavyPerMinute:     6,
+			HeavyBurst:         3,
+			OutboundPerSecond:  30,
+			ChatPerMinute:      60,
+			BroadcastPerSecond: 10,
+		},
+	}
+}
+
+// Load читает .env.local и .env (уже заданные переменные не перезаписываются),
+// YAML-файл из CONFIG_FILE, если он указан, и переменные окружения.
+// Обязательные значения не проверяются — для этого есть Validate
+func Load() (*Config, error) {
+	// Файлов может не быть: тогда настройки только в окружении.
+	// Грузим по одному, иначе без .env.local не прочитается и .env
+	for _, name := range []string{".env.local", ".env"} {
+		_ = godotenv.Load(name)
+	}
+
+	cfg := Default()
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+
+	if cfg.Defaults.TimeZone != "" {
+		loc, err := time.LoadLocation(cfg.Defaults.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("неизвестный часовой пояс %q: %w", cfg.Defaults.TimeZone, err)
+		}
+		cfg.Defaults.Location = loc
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения файла настроек: %w", err)
+	}
+	defer file.Close()
+
+	dec := yaml.NewDecoder(file)
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("ошибка разбора %s: %w", path, err)
+	}
+	return nil
+}
+
+// Переменные окружения, перекрывающие файл настроек
+func (c *Config) loadEnv() error {
+	var errs []error
+	str := func(name string, dst *string) {
+		if v, ok := os.LookupEnv(name); ok {
+			*dst = v
+		}
+	}
+	num := func(name string, dst *int) {
+		if v, ok := os.LookupEnv(name); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: ожидается целое число, получено %q", name, v))
+				return
+			}
+			*dst = n
+		}
+	}
+	duration := func(name string, dst *time.Duration) {
+		if v, ok := os.LookupEnv(name); ok {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: ожидается длительность вроде 10s или 5m, получено %q", name, v))
+				return
+			}
+			*dst = d
+		}
+	}
+	ids := func(name string, dst *[]int64) {
+		if v, ok := os.LookupEnv(name); ok {
+			var list []int64
+			for _, field := range strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' }) {
+				id, err := strconv.ParseInt(field, 10, 64)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s: ожидается список chat ID через запятую, получено %q", name, v))
+					return
+				}
+				list = append(list, id)
+			}
+			*dst = list
+		}
+	}
+	flag := func(name string, dst *bool) {
+		if v, ok := os.LookupEnv(name); ok {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: ожидается true или false, получено %q", name, v))
+				return
+			}
+			*dst = b
+		}
+	}
+
+	str("BOT_TOKEN", &c.BotToken)
+	str("DATABASE_URL", &c.DatabaseURL)
+	str("HTTP_ADDR", &c.HTTPAddr)
+	str("PUBLIC_URL", &c.PublicURL)
+	duration("SHUTDOWN_TIMEOUT", &c.ShutdownTimeout)
+	ids("ADMIN_IDS", &c.Admins)
+
+	str("LOG_LEVEL", &c.Log.Level)
+	str("LOG_FORMAT", &c.Log.Format)
+
+	str("BOT_MODE", &c.Poller.Mode)
+	duration("POLL_TIMEOUT", &c.Poller.Timeout)
+
+	str("WEBHOOK_LISTEN", &c.Webhook.Listen)
+	str("WEBHOOK_URL", &c.Webhook.URL)
+	str("WEBHOOK_SECRET", &c.Webhook.SecretToken)
+	str("WEBHOOK_TLS_CERT", &c.Webhook.TLSCert)
+	str("WEBHOOK_TLS_KEY", &c.Webhook.TLSKey)
+
+	num("DB_MAX_OPEN_CONNS", &c.Database.MaxOpenConns)
+	num("DB_MAX_IDLE_CONNS", &c.Database.MaxIdleConns)
+	duration("DB_CONN_MAX_LIFETIME", &c.Database.ConnMaxLifetime)
+	duration("DB_QUERY_TIMEOUT", &c.Database.QueryTimeout)
+	duration("DB_BULK_TIMEOUT", &c.Database.BulkTimeout)
+
+	str("DEFAULT_UNITS", &c.Defaults.Units)
+	str("TIME_ZONE", &c.Defaults.TimeZone)
+
+	flag("FEATURE_API", &c.Features.API)
+	flag("FEATURE_WEB", &c.Features.Web)
+	flag("FEATURE_REPORTS", &c.Features.Reports)
+	flag("FEATURE_METRICS", &c.Features.Metrics)
+
+	num("RATE_TEXT_PER_MINUTE", &c.Limits.TextPerMinute)
+	num("RATE_TEXT_BURST", &c.Limits.TextBurst)
+	num("RATE_HEAVY_PER_MINUTE", &c.Limits.HeavyPerMinute)
+	num("RATE_HEAVY_BURST", &c.Limits.HeavyBurst)
+	num("RATE_OUTBOUND_PER_SECOND", &c.Limits.OutboundPerSecond)
+	num("RATE_CHAT_PER_MINUTE", &c.Limits.ChatPerMinute)
+	num("RATE_BROADCAST_PER_SECOND", &c.Limits.BroadcastPerSecond)
+
+	return errors.Join(errs...)
+}
+
+// Токен вида 123456789:AAE...; шаблон {BOT_TOKEN} из примера .env сюда не подходит
+var botTokenPattern = regexp.MustCompile(`^[0-9]+:[A-Za-z0-9_-]+$`)
+
+// Validate проверяет обязательные значения и диапазоны и возвращает все ошибки сразу
+func (c *Config) Validate() error {
+	var errs []error
+
+	switch {
+	case c.BotToken == "":
+		errs = append(errs, fmt.Errorf("не задан BOT_TOKEN"))
+	case strings.ContainsAny(c.BotToken, "{}$"):
+		errs = append(errs, fmt.Errorf("BOT_TOKEN содержит шаблон %q вместо токена от @BotFather", c.BotToken))
+	case !botTokenPattern.MatchString(c.BotToken):
+		errs = append(errs, fmt.Errorf("BOT_TOKEN не похож на токен от @BotFather (ожидается 123456:ABC...)"))
+	}
+	if err := c.ValidateDatabase(); err != nil {
+		errs = append(errs, err)
+	}
+	if c.HTTPAddr == "" {
+		errs = append(errs, fmt.Errorf("не задан HTTP_ADDR"))
+	}
+	if c.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("SHUTDOWN_TIMEOUT должен быть больше нуля"))
+	}
+
+	if _, err := logging.New(io.Discard, c.Log.Level, c.Log.Format); err != nil {
+		errs = append(errs, err)
+	}
+
+	switch c.Poller.Mode {
+	case "polling":
+		if c.Poller.Timeout < time.Second || c.Poller.Timeout > maxPollTimeout {
+			errs = append(errs, fmt.Errorf("POLL_TIMEOUT должен быть от 1s до %s", maxPollTimeout))
+		}
+	case "webhook":
+		if err := c.WebhookConfig().Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	default:
+		errs = append(errs, fmt.Errorf("неизвестный BOT_MODE %q: ожидается polling или webhook", c.Poller.Mode))
+	}
+
+	if c.Database.QueryTimeout <= 0 || c.Database.BulkTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("DB_QUERY_TIMEOUT и DB_BULK_TIMEOUT должны быть больше нуля"))
+	}
+
+	if c.Defaults.Units != UnitsKg && c.Defaults.Units != UnitsLbs {
+		errs = append(errs, fmt.Errorf("DEFAULT_UNITS: ожидается %s или %s, получено %q", UnitsKg, UnitsLbs, c.Defaults.Units))
+	}
+
+	if c.Limits.TextPerMinute < 0 || c.Limits.HeavyPerMinute < 0 {
+		errs = append(errs, fmt.Errorf("RATE_TEXT_PER_MINUTE и RATE_HEAVY_PER_MINUTE не могут быть отрицательными"))
+	}
+	if c.Limits.TextBurst < 1 || c.Limits.HeavyBurst < 1 {
+		errs = append(errs, fmt.Errorf("RATE_TEXT_BURST и RATE_HEAVY_BURST должны быть не меньше 1"))
+	}
+	if c.Limits.OutboundPerSecond < 1 || c.Limits.ChatPerMinute < 1 {
+		errs = append(errs, fmt.Errorf("RATE_OUTBOUND_PER_SECOND и RATE_CHAT_PER_MINUTE должны быть не меньше 1"))
+	}
+	if c.Limits.BroadcastPerSecond < 1 || c.Limits.BroadcastPerSecond > c.Limits.OutboundPerSecond {
+		errs = append(errs, fmt.Errorf("RATE_BROADCAST_PER_SECOND должен быть от 1 до RATE_OUTBOUND_PER_SECOND"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateDatabase проверяет только подключение к хранилищу — этого
+// достаточно командам вроде bot migrate, которым не нужен токен бота
+func (c *Config) ValidateDatabase() error {
+	var errs []error
+	if c.DatabaseURL == "" {
+		errs = append(errs, fmt.Errorf("не задан DATABASE_URL"))
+	}
+	if c.Database.MaxOpenConns < 1 {
+		errs = append(errs, fmt.Errorf("DB_MAX_OPEN_CONNS должен быть не меньше 1"))
+	}
+	if c.Database.MaxIdleConns < 0 || c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		errs = append(errs, fmt.Errorf("DB_MAX_IDLE_CONNS должен быть от 0 до DB_MAX_OPEN_CONNS"))
+	}
+	if c.Database.ConnMaxLifetime < 0 {
+		errs = append(errs, fmt.Errorf("DB_CONN_MAX_LIFETIME не может быть отрицательным"))
+	}
+	return errors.Join(errs...)
+}
+
+// IsAdmin сообщает, входит ли chatID в список администраторов
+func (c *Config) IsAdmin(chatID int64) bool {
+	for _, id := range c.Admins {
+		if id == chatID {
+			return true
+		}
+	}
+	return false
+}
+
+// Pool — настройки пула подключений для database.Open
+func (c *Config) Pool() database.Pool {
+	return database.Pool{
+		MaxOpenConns:    c.Database.MaxOpenConns,
+		MaxIdleConns:    c.Database.MaxIdleConns,
+		ConnMaxLifetime: c.Database.ConnMaxLifetime,
+	}
+}
+
+// WebhookConfig — настройки вебхука для webhook.New
+func (c *Config) WebhookConfig() webhook.Config {
+	return webhook.Config{
+		Listen:      c.Webhook.Listen,
+		PublicURL:   c.Webhook.URL,
+		SecretToken: c.Webhook.SecretToken,
+		TLSCert:     c.Webhook.TLSCert,
+		TLSKey:      c.Webhook.TLSKey,
+	}
+}
@@ -0,0 +1,228 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Переменные окружения, которые читают Load и loadEnv
+var envNames = []string{
+	"CONFIG_FILE", "BOT_TOKEN", "DATABASE_URL", "HTTP_ADDR", "PUBLIC_URL", "SHUTDOWN_TIMEOUT",
+	"ADMIN_IDS", "LOG_LEVEL", "LOG_FORMAT", "BOT_MODE", "POLL_TIMEOUT", "WEBHOOK_LISTEN",
+	"WEBHOOK_URL", "WEBHOOK_SECRET", "WEBHOOK_TLS_CERT", "WEBHOOK_TLS_KEY", "DB_MAX_OPEN_CONNS",
+	"DB_MAX_IDLE_CONNS", "DB_CONN_MAX_LIFETIME", "DB_QUERY_TIMEOUT", "DB_BULK_TIMEOUT",
+	"DEFAULT_UNITS", "TIME_ZONE", "FEATURE_API", "FEATURE_WEB", "FEATURE_REPORTS", "FEATURE_METRICS",
+	"RATE_TEXT_PER_MINUTE", "RATE_TEXT_BURST", "RATE_HEAVY_PER_MINUTE", "RATE_HEAVY_BURST",
+	"RATE_OUTBOUND_PER_SECOND", "RATE_CHAT_PER_MINUTE", "RATE_BROADCAST_PER_SECOND",
+}
+
+// Убираем из окружения все настройки бота, чтобы тест не зависел от машины,
+// на которой запущен; t.Setenv вернёт прежние значения после теста
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range envNames {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+}
+
+// Временный YAML-файл настроек, подключённый через CONFIG_FILE
+func writeConfig(t *testing.T, content string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+}
+
+// Настройки, которые проходят Validate
+func valid() *Config {
+	cfg := Default()
+	cfg.BotToken = "123456789:AAE-test_token"
+	cfg.DatabaseURL = "sqlite://gofitness.db"
+	return cfg
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	clearEnv(t)
+	writeConfig(t, `
+bot_token: "111:from_file"
+database_url: "postgres://file"
+http_addr: ":9000"
+admins: [1, 2]
+poller:
+  timeout: 30s
+limits:
+  text_per_minute: 20
+  heavy_burst: 5
+`)
+	t.Setenv("BOT_TOKEN", "222:from_env")
+	t.Setenv("ADMIN_IDS", "3, 4,5")
+	t.Setenv("RATE_TEXT_PER_MINUTE", "40")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Окружение важнее файла
+	if cfg.BotToken != "222:from_env" {
+		t.Errorf("BotToken %q, ожидалось значение из окружения", cfg.BotToken)
+	}
+	if len(cfg.Admins) != 3 || cfg.Admins[0] != 3 || cfg.Admins[2] != 5 {
+		t.Errorf("Admins %v, ожидалось [3 4 5]", cfg.Admins)
+	}
+	if cfg.Limits.TextPerMinute != 40 {
+		t.Errorf("TextPerMinute %d, ожидалось 40", cfg.Limits.TextPerMinute)
+	}
+
+	// Файл важнее значений по умолчанию
+	if cfg.DatabaseURL != "postgres://file" || cfg.HTTPAddr != ":9000" {
+		t.Errorf("DatabaseURL %q, HTTPAddr %q: ожидались значения из файла", cfg.DatabaseURL, cfg.HTTPAddr)
+	}
+	if cfg.Poller.Timeout != 30*time.Second || cfg.Limits.HeavyBurst != 5 {
+		t.Errorf("Poller.Timeout %s, HeavyBurst %d: ожидались значения из файла", cfg.Poller.Timeout, cfg.Limits.HeavyBurst)
+	}
+
+	// Не указанные нигде значения остаются по умолчанию
+	defaults := Default()
+	if cfg.Poller.Mode != defaults.Poller.Mode || cfg.Limits.TextBurst != defaults.Limits.TextBurst {
+		t.Errorf("Poller.Mode %q, TextBurst %d: ожидались значения по умолчанию", cfg.Poller.Mode, cfg.Limits.TextBurst)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		env  map[string]string
+		want []string
+	}{
+		{
+			name: "неизвестное поле в файле",
+			file: "bot_tokn: \"111:abc\"\n",
+			want: []string{"bot_tokn"},
+		},
+		{
+			name: "некорректные значения окружения",
+			env: map[string]string{
+				"RATE_TEXT_BURST":  "много",
+				"SHUTDOWN_TIMEOUT": "20",
+				"ADMIN_IDS":        "1,admin",
+				"FEATURE_WEB":      "да",
+			},
+			want: []string{"RATE_TEXT_BURST", "SHUTDOWN_TIMEOUT", "ADMIN_IDS", "FEATURE_WEB"},
+		},
+		{
+			name: "неизвестный часовой пояс",
+			env:  map[string]string{"TIME_ZONE": "Europe/Nowhere"},
+			want: []string{"Europe/Nowhere"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			if tt.file != "" {
+				writeConfig(t, tt.file)
+			}
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			_, err := Load()
+			if err == nil {
+				t.Fatalf("ошибка не возвращена")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("в ошибке нет %q: %v", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := valid().Validate(); err != nil {
+		t.Fatalf("корректные настройки не прошли проверку: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		change func(c *Config)
+		want   string
+	}{
+		{"нет токена", func(c *Config) { c.BotToken = "" }, "не задан BOT_TOKEN"},
+		{"шаблон токена", func(c *Config) { c.BotToken = "{BOT_TOKEN}" }, "шаблон"},
+		{"шаблон окружения", func(c *Config) { c.BotToken = "${BOT_TOKEN}" }, "шаблон"},
+		{"токен без двоеточия", func(c *Config) { c.BotToken = "AAE-test_token" }, "не похож на токен"},
+		{"нет базы", func(c *Config) { c.DatabaseURL = "" }, "DATABASE_URL"},
+		{"режим", func(c *Config) { c.Poller.Mode = "push" }, "BOT_MODE"},
+		{"таймаут опроса", func(c *Config) { c.Poller.Timeout = time.Minute }, "POLL_TIMEOUT"},
+		{"единицы", func(c *Config) { c.Defaults.Units = "stone" }, "DEFAULT_UNITS"},
+		{"рассылка быстрее лимита", func(c *Config) { c.Limits.BroadcastPerSecond = 31 }, "RATE_BROADCAST_PER_SECOND"},
+		{"пул соединений", func(c *Config) { c.Database.MaxIdleConns = 100 }, "DB_MAX_IDLE_CONNS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.change(cfg)
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("ошибка %v, ожидалось упоминание %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// Validate возвращает все ошибки сразу, по одной на строку
+func TestValidateCollectsErrors(t *testing.T) {
+	cfg := valid()
+	cfg.BotToken = "{BOT_TOKEN}"
+	cfg.DatabaseURL = ""
+	cfg.Poller.Mode = "push"
+	cfg.Limits.TextBurst = 0
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatalf("ошибка не возвращена")
+	}
+	lines := strings.Split(err.Error(), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("ошибок %d, ожидалось 4:\n%v", len(lines), err)
+	}
+	for i, want := range []string{"BOT_TOKEN", "DATABASE_URL", "BOT_MODE", "RATE_TEXT_BURST"} {
+		if !strings.Contains(lines[i], want) {
+			t.Errorf("строка %d %q, ожидалось упоминание %s", i+1, lines[i], want)
+		}
+	}
+}
+
+func TestLoadValidateFromFile(t *testing.T) {
+	clearEnv(t)
+	writeConfig(t, "bot_token: \"{BOT_TOKEN}\"\ndatabase_url: \"sqlite://gofitness.db\"\n")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "шаблон") {
+		t.Fatalf("шаблон {BOT_TOKEN} из файла не отклонён: %v", err)
+	}
+
+	// Настоящий токен из окружения перекрывает шаблон из файла
+	t.Setenv("BOT_TOKEN", "123456789:AAE-test_token")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("токен из окружения не перекрыл файл: %v", err)
+	}
+}
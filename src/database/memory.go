@@ -0,0 +1,855 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"gofitness/src/model"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Memory — хранилище в памяти процесса с тем же поведением, что и Postgres.
+// Данные теряются при перезапуске: подходит для тестов и локальной разработки.
+// Операции выполняются мгновенно, поэтому контекст проверяется только при выгрузке.
+type Memory struct {
+	mu sync.RWMutex
+
+	users     map[int64]*model.User
+	exercises map[int]*model.Exercise
+	sets      []model.WorkoutSet
+	// Хеш токена API → ID пользователя
+	tokens map[string]int64
+	// Сохранённые диалоги по chat_id
+	conversations map[int64][]byte
+
+	nextUserID     int64
+	nextExerciseID int
+	nextSetID      int
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		users:     make(map[int64]*model.User),
+		exercises: make(map[int]*model.Exercise),
+		tokens:    make(map[string]int64),
+	}
+}
+
+// Стандартные упражнения — те же, что создаёт миграция 0003_standard_exercises
+var standardExercises = []model.Exercise{
+	{Name: "Приседания", Description: "Приседания со штангой",
+		PrimaryMuscles:   []model.MuscleGroup{model.MuscleQuads, model.MuscleGlutes},
+		SecondaryMuscles: []model.MuscleGroup{model.MuscleHamstrings, model.MuscleCore}},
+	{Name: "Жим лежа", Description: "Жим штанги лежа",
+		PrimaryMuscles:   []model.MuscleGroup{model.MuscleChest},
+		SecondaryMuscles: []model.MuscleGroup{model.MuscleTriceps, model.MuscleShoulders}},
+	{Name: "Становая тяга", Description: "Классическая становая тяга",
+		PrimaryMuscles:   []model.MuscleGroup{model.MuscleBack, model.MuscleHamstrings, model.MuscleGlutes},
+		SecondaryMuscles: []model.MuscleGroup{model.MuscleQuads, model.MuscleCore}},
+	{Name: "Подтягивания", Description: "Подтягивания широким хватом",
+		PrimaryMuscles:   []model.MuscleGroup{model.MuscleBack},
+		SecondaryMuscles: []model.MuscleGroup{model.MuscleBiceps}},
+	{Name: "Отжимания", Description: "Отжимания от пола",
+		PrimaryMuscles:   []model.MuscleGroup{model.MuscleChest},
+		SecondaryMuscles: []model.MuscleGroup{model.MuscleTriceps, model.MuscleShoulders, model.MuscleCore}},
+	{Name: "Жим стоя", Description: "Армейский жим",
+		PrimaryMuscles:   []model.MuscleGroup{model.MuscleShoulders},
+		SecondaryMuscles: []model.MuscleGroup{model.MuscleTriceps, model.MuscleCore}},
+	{Name: "Тяга штанги", Description: "Тяга штанги в наклоне",
+		PrimaryMuscles:   []model.MuscleGroup{model.MuscleBack},
+		SecondaryMuscles: []model.MuscleGroup{model.MuscleBiceps, model.MuscleShoulders}},
+	{Name: "Бицепс", Description: "Подъем штанги на бицепс",
+		PrimaryMuscles: []model.MuscleGroup{model.MuscleBiceps}},
+	{Name: "Трицепс", Description: "Жим лежа узким хватом",
+		PrimaryMuscles:   []model.MuscleGroup{model.MuscleTriceps},
+		SecondaryMuscles: []model.MuscleGroup{model.MuscleChest, model.MuscleShoulders}},
+	{Name: "Планка", Description: "Упражнение на пресс",
+		PrimaryMuscles: []model.MuscleGroup{model.MuscleCore}},
+}
+
+// Init добавляет стандартные упражнения; повторный вызов ничего не дублирует
+func (m *Memory) Init() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, ex := range standardExercises {
+		if m.findExercise(0, ex.Name) != nil {
+			continue
+		}
+		ex.IsStandard = true
+		m.addExercise(ex)
+	}
+	return nil
+}
+
+func (m *Memory) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (m *Memory) Close() error {
+	return nil
+}
+
+// Копия упражнения, чтобы вызывающий код не менял данные хранилища
+func copyExercise(ex *model.Exercise) model.Exercise {
+	c := *ex
+	c.PrimaryMuscles = append([]model.MuscleGroup(nil), ex.PrimaryMuscles...)
+	c.SecondaryMuscles = append([]model.MuscleGroup(nil), ex.SecondaryMuscles...)
+	return c
+}
+
+func (m *Memory) addExercise(ex model.Exercise) *model.Exercise {
+	m.nextExerciseID++
+	ex.ID = m.nextExerciseID
+	ex.CreatedAt = time.Now()
+	m.exercises[ex.ID] = &ex
+	return &ex
+}
+
+// Упражнение по имени без учёта регистра: стандартное в приоритете над личным
+func (m *Memory) findExercise(userID int64, name string) *model.Exercise {
+	var found *model.Exercise
+	for _, ex := range m.exercises {
+		if !strings.EqualFold(ex.Name, name) || !(ex.IsStandard || ex.UserID == userID) {
+			continue
+		}
+		if found == nil || (ex.IsStandard && !found.IsStandard) || (ex.IsStandard == found.IsStandard && ex.ID < found.ID) {
+			found = ex
+		}
+	}
+	return found
+}
+
+func (m *Memory) GetUserByChatID(ctx context.Context, chatID int64) (*model.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, user := range m.users {
+		if user.ChatID == chatID {
+			u := *user
+			return &u, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *Memory) GetOrCreateUser(ctx context.Context, chatID int64, username string) (*model.User, error) {
+	user, err := m.GetUserByChatID(ctx, chatID)
+	if err != nil || user != nil {
+		return user, err
+	}
+	return m.SaveUser(ctx, chatID, username)
+}
+
+func (m *Memory) SaveUser(ctx context.Context, chatID int64, username string) (*model.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, user := range m.users {
+		if user.ChatID == chatID {
+			user.Username = username
+			u := *user
+			return &u, nil
+		}
+	}
+
+	m.nextUserID++
+	user := &model.User{ID: m.nextUserID, ChatID: chatID, Username: username, CreatedAt: time.Now()}
+	m.users[user.ID] = user
+	u := *user
+	return &u, nil
+}
+
+func (m *Memory) SetUserLanguage(ctx context.Context, userID int64, language string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[userID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	user.Language = language
+	return nil
+}
+
+func (m *Memory) GetAllUsers(ctx context.Context) ([]model.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	users := make([]model.User, 0, len(m.users))
+	for _, user := range m.users {
+		users = append(users, *user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+func (m *Memory) DeleteUser(ctx context.Context, userID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	custom := make(map[int]bool)
+	for id, ex := range m.exercises {
+		if ex.UserID == userID && !ex.IsStandard {
+			custom[id] = true
+			delete(m.exercises, id)
+		}
+	}
+
+	kept := m.sets[:0]
+	for _, set := range m.sets {
+		if set.UserID != userID && !custom[set.ExerciseID] {
+			kept = append(kept, set)
+		}
+	}
+	m.sets = kept
+
+	for hash, id := range m.tokens {
+		if id == userID {
+			delete(m.tokens, hash)
+		}
+	}
+	if user, ok := m.users[userID]; ok {
+		delete(m.conversations, user.ChatID)
+	}
+	delete(m.users, userID)
+	return nil
+}
+
+func (m *Memory) GetExercises(ctx context.Context, userID int64) ([]model.Exercise, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var exercises []model.Exercise
+	for _, ex := range m.exercises {
+		if ex.IsStandard || ex.UserID == userID {
+			exercises = append(exercises, copyExercise(ex))
+		}
+	}
+	sort.Slice(exercises, func(i, j int) bool { return exercises[i].Name < exercises[j].Name })
+	return exercises, nil
+}
+
+func (m *Memory) GetExerciseByID(ctx context.Context, id int) (*model.Exercise, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ex, ok := m.exercises[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	c := copyExercise(ex)
+	return &c, nil
+}
+
+func (m *Memory) GetExerciseByName(ctx context.Context, userID int64, name string) (*model.Exercise, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ex := m.findExercise(userID, name)
+	if ex == nil {
+		return nil, sql.ErrNoRows
+	}
+	c := copyExercise(ex)
+	return &c, nil
+}
+
+func (m *Memory) GetCustomExercises(ctx context.Context, userID int64) ([]model.Exercise, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var exercises []model.Exercise
+	for _, ex := range m.exercises {
+		if ex.UserID == userID && !ex.IsStandard {
+			exercises = append(exercises, copyExercise(ex))
+		}
+	}
+	sort.Slice(exercises, func(i, j int) bool { return exercises[i].Name < exercises[j].Name })
+	return exercises, nil
+}
+
+func (m *Memory) CountCustomExercises(ctx context.Context, userID int64) (int, error) {
+	exercises, err := m.GetCustomExercises(ctx, userID)
+	return len(exercises), err
+}
+
+func (m *Memory) addSet(set model.WorkoutSet) {
+	m.nextSetID++
+	set.ID = m.nextSetID
+	set.ExerciseName = ""
+	m.sets = append(m.sets, set)
+}
+
+func (m *Memory) SaveWorkoutSet(ctx context.Context, userID int64, exerciseID int, weight float64, reps int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.exercises[exerciseID]; !ok {
+		return fmt.Errorf("упражнение %d не найдено", exerciseID)
+	}
+	m.addSet(model.WorkoutSet{UserID: userID, ExerciseID: exerciseID, Weight: weight, Reps: reps, CreatedAt: time.Now()})
+	return nil
+}
+
+func (m *Memory) ImportWorkoutSets(ctx context.Context, userID int64, newExercises []string, sets []model.WorkoutSet) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Проверяем всё до изменений, чтобы импорт оставался атомарным
+	for _, set := range sets {
+		if set.ExerciseID != 0 {
+			continue
+		}
+		known := false
+		for _, name := range newExercises {
+			known = known || name == set.ExerciseName
+		}
+		if !known {
+			return fmt.Errorf("упражнение '%s' не найдено", set.ExerciseName)
+		}
+	}
+
+	created := make(map[string]int, len(newExercises))
+	for _, name := range newExercises {
+		created[name] = m.addExercise(model.Exercise{Name: name, UserID: userID}).ID
+	}
+
+	for _, set := range sets {
+		if set.ExerciseID == 0 {
+			set.ExerciseID = created[set.ExerciseName]
+		}
+		set.UserID = userID
+		m.addSet(set)
+	}
+	return nil
+}
+
+func (m *Memory) RestoreBackup(ctx context.Context, userID int64, exercises []model.Exercise, sets []model.WorkoutSet) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	resolve := func(name, description string) *model.Exercise {
+		if ex := m.findExercise(userID, name); ex != nil {
+			return ex
+		}
+		return m.addExercise(model.Exercise{Name: name, Description: description, UserID: userID})
+	}
+
+	addMuscles := func(list []model.MuscleGroup, muscle model.MuscleGroup) []model.MuscleGroup {
+		for _, existing := range list {
+			if existing == muscle {
+				return list
+			}
+		}
+		return append(list, muscle)
+	}
+
+	for _, backup := range exercises {
+		ex := resolve(backup.Name, backup.Description)
+		if ex.IsStandard {
+			continue
+		}
+		for _, muscle := range backup.PrimaryMuscles {
+			ex.PrimaryMuscles = addMuscles(ex.PrimaryMuscles, muscle)
+		}
+		for _, muscle := range backup.SecondaryMuscles {
+			ex.SecondaryMuscles = addMuscles(ex.SecondaryMuscles, muscle)
+		}
+	}
+
+	counter := make(restoreCounter)
+	inserted := 0
+	for _, set := range sets {
+		exerciseID := resolve(set.ExerciseName, "").ID
+
+		existing := 0
+		for _, stored := range m.sets {
+			if stored.UserID == userID && stored.ExerciseID == exerciseID && stored.Weight == set.Weight &&
+				stored.Reps == set.Reps && stored.CreatedAt.Equal(set.CreatedAt) {
+				existing++
+			}
+		}
+		if existing >= counter.next(exerciseID, set) {
+			continue
+		}
+
+		m.addSet(model.WorkoutSet{UserID: userID, ExerciseID: exerciseID, Weight: set.Weight, Reps: set.Reps, CreatedAt: set.CreatedAt})
+		inserted++
+	}
+
+	return inserted, nil
+}
+
+// Подходы пользователя за [from, to) в хронологическом порядке с названиями упражнений
+func (m *Memory) userSets(userID int64, from, to time.Time) []model.WorkoutSet {
+	var sets []model.WorkoutSet
+	for _, set := range m.sets {
+		if set.UserID != userID || set.CreatedAt.Before(from) || (!to.IsZero() && !set.CreatedAt.Before(to)) {
+			continue
+		}
+		if ex, ok := m.exercises[set.ExerciseID]; ok {
+			set.ExerciseName = ex.Name
+		}
+		sets = append(sets, set)
+	}
+	sort.SliceStable(sets, func(i, j int) bool {
+		if sets[i].CreatedAt.Equal(sets[j].CreatedAt) {
+			return sets[i].ID < sets[j].ID
+		}
+		return sets[i].CreatedAt.Before(sets[j].CreatedAt)
+	})
+	return sets
+}
+
+func (m *Memory) GetUserWorkoutHistory(ctx context.Context, userID int64, limit int) ([]model.WorkoutSet, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	sets := m.userSets(userID, time.Time{}, time.Time{})
+	var history []model.WorkoutSet
+	for i := len(sets) - 1; i >= 0 && len(history) < limit; i-- {
+		set := sets[i]
+		history = append(history, set)
+	}
+	return history, nil
+}
+
+func (m *Memory) GetWorkoutSetsBetween(ctx context.Context, userID int64, from, to time.Time) ([]model.WorkoutSet, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.userSets(userID, from, to), nil
+}
+
+func (m *Memory) StreamWorkoutSets(ctx context.Context, userID int64, fn func(model.WorkoutSet) error) error {
+	m.mu.RLock()
+	sets := m.userSets(userID, time.Time{}, time.Time{})
+	m.mu.RUnlock()
+
+	for _, set := range sets {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(set); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Индекс подхода пользователя в m.sets или -1
+func (m *Memory) findSet(userID int64, setID int) int {
+	for i, set := range m.sets {
+		if set.ID == setID && set.UserID == userID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *Memory) GetWorkoutSet(ctx context.Context, userID int64, setID int) (*model.WorkoutSet, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	i := m.findSet(userID, setID)
+	if i < 0 {
+		return nil, sql.ErrNoRows
+	}
+	set := m.sets[i]
+	if ex, ok := m.exercises[set.ExerciseID]; ok {
+		set.ExerciseName = ex.Name
+	}
+	return &set, nil
+}
+
+func (m *Memory) CreateWorkoutSet(ctx context.Context, set model.WorkoutSet) (*model.WorkoutSet, error) {
+	m.mu.Lock()
+	if _, ok := m.exercises[set.ExerciseID]; !ok {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("упражнение %d не найдено", set.ExerciseID)
+	}
+	if set.CreatedAt.IsZero() {
+		set.CreatedAt = time.Now()
+	}
+	m.addSet(set)
+	id := m.nextSetID
+	m.mu.Unlock()
+
+	return m.GetWorkoutSet(ctx, set.UserID, id)
+}
+
+func (m *Memory) UpdateWorkoutSet(ctx context.Context, set model.WorkoutSet) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.findSet(set.UserID, set.ID)
+	if i < 0 {
+		return sql.ErrNoRows
+	}
+	if _, ok := m.exercises[set.ExerciseID]; !ok {
+		return fmt.Errorf("упражнение %d не найдено", set.ExerciseID)
+	}
+	set.ExerciseName = ""
+	m.sets[i] = set
+	return nil
+}
+
+func (m *Memory) DeleteWorkoutSet(ctx context.Context, userID int64, setID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.findSet(userID, setID)
+	if i < 0 {
+		return sql.ErrNoRows
+	}
+	m.sets = append(m.sets[:i], m.sets[i+1:]...)
+	return nil
+}
+
+func (m *Memory) ReplaceAPIToken(ctx context.Context, userID int64, tokenHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for hash, id := range m.tokens {
+		if id == userID {
+			delete(m.tokens, hash)
+		}
+	}
+	m.tokens[tokenHash] = userID
+	return nil
+}
+
+func (m *Memory) GetUserByAPIToken(ctx context.Context, tokenHash string) (*model.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	user, ok := m.users[m.tokens[tokenHash]]
+	if !ok {
+		return nil, nil
+	}
+	u := *user
+	return &u, nil
+}
+
+func (m *Memory) DeleteAPITokens(ctx context.Context, userID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for hash, id := range m.tokens {
+		if id == userID {
+			delete(m.tokens, hash)
+		}
+	}
+	return nil
+}
+
+func (m *Memory) SaveConversations(ctx context.Context, states map[int64][]byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.conversations = make(map[int64][]byte, len(states))
+	for chatID, state := range states {
+		m.conversations[chatID] = append([]byte(nil), state...)
+	}
+	return nil
+}
+
+func (m *Memory) TakeConversations(ctx context.Context) (map[int64][]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	states := m.conversations
+	m.conversations = nil
+	if states == nil {
+		states = make(map[int64][]byte)
+	}
+	return states, nil
+}
+
+// Начало дня по местному времени, как DATE_TRUNC('day', …)
+func startOfDay(t time.Time) time.Time {
+	y, mo, d := t.Local().Date()
+	return time.Date(y, mo, d, 0, 0, 0, 0, time.Local)
+}
+
+// Дата в том виде, в каком драйвер Postgres возвращает колонку DATE
+func dateOf(t time.Time) time.Time {
+	y, mo, d := t.Local().Date()
+	return time.Date(y, mo, d, 0, 0, 0, 0, time.UTC)
+}
+
+func epley(weight float64, reps int) float64 {
+	return weight * (1 + float64(reps)/30.0)
+}
+
+func (m *Memory) GetProgressByExercise(ctx context.Context, userID int64, exerciseID int, days int) ([]model.ProgressPoint, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var points []model.ProgressPoint
+	var totalReps int
+	for _, set := range m.userSets(userID, time.Now().AddDate(0, 0, -days), time.Time{}) {
+		if set.ExerciseID != exerciseID {
+			continue
+		}
+
+		day := startOfDay(set.CreatedAt)
+		if len(points) == 0 || !points[len(points)-1].Date.Equal(day) {
+			points = append(points, model.ProgressPoint{Date: day})
+			totalReps = 0
+		}
+
+		p := &points[len(points)-1]
+		p.AvgWeight = (p.AvgWeight*float64(p.SetsCount) + set.Weight) / float64(p.SetsCount+1)
+		p.SetsCount++
+		totalReps += set.Reps
+		p.AvgReps = float64(totalReps) / float64(p.SetsCount)
+		p.TotalVolume += set.Weight * float64(set.Reps)
+		if e1rm := epley(set.Weight, set.Reps); e1rm > p.BestE1RM {
+			p.BestE1RM = e1rm
+		}
+		if set.Reps > p.MaxReps {
+			p.MaxReps = set.Reps
+		}
+	}
+
+	return points, nil
+}
+
+func (m *Memory) GetWorkoutSummary(ctx context.Context, userID int64, from, to time.Time) (*model.WorkoutSummary, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	summary := model.WorkoutSummary{From: from, To: to}
+	days := make(map[time.Time]bool)
+	for _, set := range m.userSets(userID, from, to) {
+		if summary.Sets == 0 {
+			summary.FirstAt = set.CreatedAt
+		}
+		summary.LastAt = set.CreatedAt
+		summary.Sets++
+		summary.Reps += set.Reps
+		summary.Tonnage += set.Weight * float64(set.Reps)
+		days[dateOf(set.CreatedAt)] = true
+	}
+	summary.Sessions = len(days)
+
+	return &summary, nil
+}
+
+func (m *Memory) GetExerciseBests(ctx context.Context, userID int64, from, to time.Time) ([]model.ExerciseBest, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	byExercise := make(map[int]*model.ExerciseBest)
+	for _, set := range m.userSets(userID, from, to) {
+		best, ok := byExercise[set.ExerciseID]
+		if !ok {
+			best = &model.ExerciseBest{ExerciseID: set.ExerciseID, ExerciseName: set.ExerciseName}
+			byExercise[set.ExerciseID] = best
+		}
+		if set.Weight > best.MaxWeight {
+			best.MaxWeight = set.Weight
+		}
+		if set.Reps > best.MaxReps {
+			best.MaxReps = set.Reps
+		}
+		if e1rm := epley(set.Weight, set.Reps); e1rm > best.BestE1RM {
+			best.BestE1RM = e1rm
+		}
+		best.Sets++
+		best.Tonnage += set.Weight * float64(set.Reps)
+	}
+
+	var bests []model.ExerciseBest
+	for _, best := range byExercise {
+		bests = append(bests, *best)
+	}
+	sort.Slice(bests, func(i, j int) bool { return bests[i].ExerciseName < bests[j].ExerciseName })
+	return bests, nil
+}
+
+func (m *Memory) GetDailyActivity(ctx context.Context, userID int64, from, to time.Time) ([]model.DayActivity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var days []model.DayActivity
+	for _, set := range m.userSets(userID, from, to) {
+		date := dateOf(set.CreatedAt)
+		if len(days) == 0 || !days[len(days)-1].Date.Equal(date) {
+			days = append(days, model.DayActivity{Date: date})
+		}
+		day := &days[len(days)-1]
+		day.Sets++
+		day.Reps += set.Reps
+		day.Tonnage += set.Weight * float64(set.Reps)
+	}
+	return days, nil
+}
+
+func (m *Memory) GetWeeklyMuscleVolume(ctx context.Context, userID int64, from time.Time) ([]model.MuscleVolume, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	sets := m.userSets(userID, from, time.Time{})
+
+	// Рабочий вес: максимум по упражнению за день
+	type dayKey struct {
+		exerciseID int
+		date       time.Time
+	}
+	dayMax := make(map[dayKey]float64)
+	for _, set := range sets {
+		key := dayKey{set.ExerciseID, dateOf(set.CreatedAt)}
+		if set.Weight > dayMax[key] {
+			dayMax[key] = set.Weight
+		}
+	}
+
+	type volumeKey struct {
+		week   time.Time
+		muscle model.MuscleGroup
+	}
+	volume := make(map[volumeKey]float64)
+	for _, set := range sets {
+		if set.Weight < 0.5*dayMax[dayKey{set.ExerciseID, dateOf(set.CreatedAt)}] {
+			continue
+		}
+		ex, ok := m.exercises[set.ExerciseID]
+		if !ok {
+			continue
+		}
+
+		// Неделя с понедельника, как DATE_TRUNC('week', …)
+		day := startOfDay(set.CreatedAt)
+		week := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		for _, muscle := range ex.PrimaryMuscles {
+			volume[volumeKey{week, muscle}] += 1
+		}
+		for _, muscle := range ex.SecondaryMuscles {
+			volume[volumeKey{week, muscle}] += 0.5
+		}
+	}
+
+	var volumes []model.MuscleVolume
+	for key, sets := range volume {
+		volumes = append(volumes, model.MuscleVolume{Week: key.week, Muscle: key.muscle, Sets: sets})
+	}
+	sort.Slice(volumes, func(i, j int) bool {
+		if !volumes[i].Week.Equal(volumes[j].Week) {
+			return volumes[i].Week.Before(volumes[j].Week)
+		}
+		return volumes[i].Muscle < volumes[j].Muscle
+	})
+	return volumes, nil
+}
+
+func (m *Memory) SetUserBanned(ctx context.Context, userID int64, banned bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[userID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	user.Banned = banned
+	return nil
+}
+
+func (m *Memory) SetBroadcastOptOut(ctx context.Context, userID int64, optOut bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[userID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	user.BroadcastOptOut = optOut
+	return nil
+}
+
+func (m *Memory) CountActiveUsers(ctx context.Context, since time.Time) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	active := make(map[int64]bool)
+	for _, set := range m.sets {
+		if !set.CreatedAt.Before(since) {
+			active[set.UserID] = true
+		}
+	}
+	return len(active), nil
+}
+
+func (m *Memory) GetTotalDailyActivity(ctx context.Context, from, to time.Time) ([]model.DayActivity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	byDate := make(map[time.Time]*model.DayActivity)
+	for _, set := range m.sets {
+		if set.CreatedAt.Before(from) || !set.CreatedAt.Before(to) {
+			continue
+		}
+		date := dateOf(set.CreatedAt)
+		day, ok := byDate[date]
+		if !ok {
+			day = &model.DayActivity{Date: date}
+			byDate[date] = day
+		}
+		day.Sets++
+		day.Reps += set.Reps
+		day.Tonnage += set.Weight * float64(set.Reps)
+	}
+
+	days := make([]model.DayActivity, 0, len(byDate))
+	for _, day := range byDate {
+		days = append(days, *day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Date.Before(days[j].Date) })
+	return days, nil
+}
+
+func (m *Memory) CreateStandardExercise(ctx context.Context, exercise model.Exercise) (*model.Exercise, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	exercise.IsStandard = true
+	exercise.UserID = 0
+	created := copyExercise(m.addExercise(copyExercise(&exercise)))
+	return &created, nil
+}
+
+func (m *Memory) UpdateStandardExercise(ctx context.Context, exercise model.Exercise) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ex, ok := m.exercises[exercise.ID]
+	if !ok || !ex.IsStandard {
+		return sql.ErrNoRows
+	}
+	ex.Name = exercise.Name
+	ex.Description = exercise.Description
+	ex.PrimaryMuscles = append([]model.MuscleGroup(nil), exercise.PrimaryMuscles...)
+	ex.SecondaryMuscles = append([]model.MuscleGroup(nil), exercise.SecondaryMuscles...)
+	return nil
+}
+
+func (m *Memory) DeleteStandardExercise(ctx context.Context, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ex, ok := m.exercises[id]
+	if !ok || !ex.IsStandard {
+		return sql.ErrNoRows
+	}
+	for _, set := range m.sets {
+		if set.ExerciseID == id {
+			return ErrExerciseInUse
+		}
+	}
+	delete(m.exercises, id)
+	return nil
+}
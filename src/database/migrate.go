@@ -0,0 +1,303 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Миграции схемы: migrations/<диалект>/NNNN_name.up.sql и парный NNNN_name.down.sql
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+// Ключ advisory lock, под которым выполняются миграции в Postgres:
+// при нескольких репликах схему обновляет только одна
+const migrationLockID = 7042025
+
+// Migrator — хранилище с версионированной схемой (команда migrate)
+type Migrator interface {
+	MigrateUp() (int, error)
+	MigrateDown() (*Migration, error)
+	MigrationStatus() ([]MigrationStatus, error)
+}
+
+// migrator применяет миграции одного диалекта
+type migrator struct {
+	db  *sql.DB
+	dir string
+
+	// Запросы блокировки на время миграций; пустые — блокировка не нужна
+	lock   string
+	unlock string
+}
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Читаем миграции из встроенных файлов, отсортированные по версии
+func loadMigrations(dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		fileName := entry.Name()
+
+		var direction string
+		switch {
+		case strings.HasSuffix(fileName, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(fileName, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("миграция %s: ожидается суффикс .up.sql или .down.sql", fileName)
+		}
+
+		base := strings.TrimSuffix(fileName, "."+direction+".sql")
+		versionStr, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("миграция %s: ожидается имя вида 0001_name", fileName)
+		}
+		version, err := strconv.Atoi(versionStr)
+		if err != nil {
+			return nil, fmt.Errorf("миграция %s: некорректная версия: %w", fileName, err)
+		}
+
+		body, err := migrationFiles.ReadFile(dir + "/" + fileName)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if m.Name != name {
+			return nil, fmt.Errorf("миграция %04d: разные имена %q и %q", version, m.Name, name)
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("миграция %04d_%s: нет файла .up.sql", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Выполняем fn на отдельном соединении под блокировкой миграций
+func (m *migrator) withLock(fn func(conn *sql.Conn) error) error {
+	ctx := context.Background()
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.lock != "" {
+		if _, err := conn.ExecContext(ctx, m.lock, migrationLockID); err != nil {
+			return fmt.Errorf("ошибка получения блокировки миграций: %w", err)
+		}
+		defer conn.ExecContext(ctx, m.unlock, migrationLockID)
+	}
+
+	query := `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+	if _, err := conn.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("ошибка создания таблицы schema_migrations: %w", err)
+	}
+
+	return fn(conn)
+}
+
+func appliedMigrations(conn *sql.Conn) (map[int]time.Time, error) {
+	rows, err := conn.QueryContext(context.Background(), `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+// Выполняем SQL миграции и отметку о ней в одной транзакции
+func runMigration(conn *sql.Conn, body, mark string, args ...interface{}) error {
+	ctx := context.Background()
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, body); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, mark, args...); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Up применяет все ещё не применённые миграции. Возвращает их количество.
+func (m *migrator) Up() (int, error) {
+	migrations, err := loadMigrations(m.dir)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err = m.withLock(func(conn *sql.Conn) error {
+		applied, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+
+			mark := `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`
+			if err := runMigration(conn, mig.Up, mark, mig.Version, mig.Name); err != nil {
+				return fmt.Errorf("ошибка миграции %04d_%s: %w", mig.Version, mig.Name, err)
+			}
+			slog.Info("Применена миграция", "version", mig.Version, "name", mig.Name)
+			count++
+		}
+		return nil
+	})
+
+	return count, err
+}
+
+// Down откатывает последнюю применённую миграцию. Возвращает nil, если откатывать нечего.
+func (m *migrator) Down() (*Migration, error) {
+	migrations, err := loadMigrations(m.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var rolledBack *Migration
+	err = m.withLock(func(conn *sql.Conn) error {
+		applied, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0; i-- {
+			mig := migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if mig.Down == "" {
+				return fmt.Errorf("миграция %04d_%s не поддерживает откат", mig.Version, mig.Name)
+			}
+
+			mark := `DELETE FROM schema_migrations WHERE version = $1`
+			if err := runMigration(conn, mig.Down, mark, mig.Version); err != nil {
+				return fmt.Errorf("ошибка отката %04d_%s: %w", mig.Version, mig.Name, err)
+			}
+			slog.Info("Откачена миграция", "version", mig.Version, "name", mig.Name)
+			rolledBack = &mig
+			return nil
+		}
+		return nil
+	})
+
+	return rolledBack, err
+}
+
+// Status — список всех миграций с отметкой, применены ли они
+func (m *migrator) Status() ([]MigrationStatus, error) {
+	migrations, err := loadMigrations(m.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []MigrationStatus
+	err = m.withLock(func(conn *sql.Conn) error {
+		applied, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range migrations {
+			appliedAt, ok := applied[mig.Version]
+			statuses = append(statuses, MigrationStatus{Migration: mig, Applied: ok, AppliedAt: appliedAt})
+		}
+		return nil
+	})
+
+	return statuses, err
+}
+
+func (p *Postgres) migrator() *migrator {
+	return &migrator{
+		db:     p.db,
+		dir:    "migrations/postgres",
+		lock:   `SELECT pg_advisory_lock($1)`,
+		unlock: `SELECT pg_advisory_unlock($1)`,
+	}
+}
+
+// MigrateUp применяет все ещё не применённые миграции. Возвращает их количество.
+func (p *Postgres) MigrateUp() (int, error) {
+	return p.migrator().Up()
+}
+
+// MigrateDown откатывает последнюю применённую миграцию. Возвращает nil, если откатывать нечего.
+func (p *Postgres) MigrateDown() (*Migration, error) {
+	return p.migrator().Down()
+}
+
+// MigrationStatus — список всех миграций с отметкой, применены ли они
+func (p *Postgres) MigrationStatus() ([]MigrationStatus, error) {
+	return p.migrator().Status()
+}
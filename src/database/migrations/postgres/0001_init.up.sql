@@ -24,4 +24,4 @@ CREATE TABLE IF NOT EXISTS workout_sets (
     weight DECIMAL(10,2) DEFAULT 0,
     reps INTEGER NOT NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-);
\ No newline at end of file
+);
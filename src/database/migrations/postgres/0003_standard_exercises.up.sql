@@ -0,0 +1,53 @@
+-- Стандартные упражнения (user_id = 0). Уже существующие не дублируются.
+INSERT INTO exercises (name, description, is_standard, user_id)
+SELECT v.name, v.description, TRUE, 0
+FROM (VALUES
+    ('Приседания', 'Приседания со штангой'),
+    ('Жим лежа', 'Жим штанги лежа'),
+    ('Становая тяга', 'Классическая становая тяга'),
+    ('Подтягивания', 'Подтягивания широким хватом'),
+    ('Отжимания', 'Отжимания от пола'),
+    ('Жим стоя', 'Армейский жим'),
+    ('Тяга штанги', 'Тяга штанги в наклоне'),
+    ('Бицепс', 'Подъем штанги на бицепс'),
+    ('Трицепс', 'Жим лежа узким хватом'),
+    ('Планка', 'Упражнение на пресс')
+) AS v(name, description)
+WHERE NOT EXISTS (SELECT 1 FROM exercises e WHERE e.name = v.name);
+
+-- Основные (TRUE) и вспомогательные (FALSE) группы мышц стандартных упражнений
+INSERT INTO exercise_muscles (exercise_id, muscle, is_primary)
+SELECT e.id, v.muscle, v.is_primary
+FROM (VALUES
+    ('Приседания', 'quads', TRUE),
+    ('Приседания', 'glutes', TRUE),
+    ('Приседания', 'hamstrings', FALSE),
+    ('Приседания', 'core', FALSE),
+    ('Жим лежа', 'chest', TRUE),
+    ('Жим лежа', 'triceps', FALSE),
+    ('Жим лежа', 'shoulders', FALSE),
+    ('Становая тяга', 'back', TRUE),
+    ('Становая тяга', 'hamstrings', TRUE),
+    ('Становая тяга', 'glutes', TRUE),
+    ('Становая тяга', 'quads', FALSE),
+    ('Становая тяга', 'core', FALSE),
+    ('Подтягивания', 'back', TRUE),
+    ('Подтягивания', 'biceps', FALSE),
+    ('Отжимания', 'chest', TRUE),
+    ('Отжимания', 'triceps', FALSE),
+    ('Отжимания', 'shoulders', FALSE),
+    ('Отжимания', 'core', FALSE),
+    ('Жим стоя', 'shoulders', TRUE),
+    ('Жим стоя', 'triceps', FALSE),
+    ('Жим стоя', 'core', FALSE),
+    ('Тяга штанги', 'back', TRUE),
+    ('Тяга штанги', 'biceps', FALSE),
+    ('Тяга штанги', 'shoulders', FALSE),
+    ('Бицепс', 'biceps', TRUE),
+    ('Трицепс', 'triceps', TRUE),
+    ('Трицепс', 'chest', FALSE),
+    ('Трицепс', 'shoulders', FALSE),
+    ('Планка', 'core', TRUE)
+) AS v(name, muscle, is_primary)
+JOIN exercises e ON e.name = v.name AND e.is_standard
+ON CONFLICT (exercise_id, muscle) DO NOTHING;
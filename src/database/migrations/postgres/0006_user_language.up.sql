@@ -0,0 +1,2 @@
+-- Язык, выбранный пользователем в /settings; NULL — по языку Telegram
+ALTER TABLE users ADD COLUMN IF NOT EXISTS language VARCHAR(8);
@@ -0,0 +1,2 @@
+ALTER TABLE users DROP COLUMN IF EXISTS broadcast_opt_out;
+ALTER TABLE users DROP COLUMN IF EXISTS banned;
@@ -0,0 +1,4 @@
+-- Подходы удаляются вместе с пользователем
+ALTER TABLE workout_sets DROP CONSTRAINT IF EXISTS workout_sets_user_id_fkey;
+ALTER TABLE workout_sets ADD CONSTRAINT workout_sets_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
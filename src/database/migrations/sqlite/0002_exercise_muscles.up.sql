@@ -0,0 +1,7 @@
+-- Группы мышц упражнений (основные и вспомогательные)
+CREATE TABLE IF NOT EXISTS exercise_muscles (
+    exercise_id INTEGER NOT NULL REFERENCES exercises(id) ON DELETE CASCADE,
+    muscle VARCHAR(32) NOT NULL,
+    is_primary BOOLEAN NOT NULL DEFAULT TRUE,
+    PRIMARY KEY (exercise_id, muscle)
+);
@@ -0,0 +1,8 @@
+-- Удаляем только стандартные упражнения, на которые нет подходов
+DELETE FROM exercises
+WHERE is_standard
+  AND name IN (
+      'Приседания', 'Жим лежа', 'Становая тяга', 'Подтягивания', 'Отжимания',
+      'Жим стоя', 'Тяга штанги', 'Бицепс', 'Трицепс', 'Планка'
+  )
+  AND NOT EXISTS (SELECT 1 FROM workout_sets ws WHERE ws.exercise_id = exercises.id);
@@ -0,0 +1,7 @@
+-- Незавершённые диалоги (ввод подхода, подтверждения), сохранённые
+-- при остановке бота и восстановленные при следующем запуске
+CREATE TABLE IF NOT EXISTS conversation_states (
+    chat_id BIGINT PRIMARY KEY,
+    state TEXT NOT NULL,
+    saved_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now', 'localtime'))
+);
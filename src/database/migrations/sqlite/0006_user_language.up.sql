@@ -0,0 +1,2 @@
+-- Язык, выбранный пользователем в /settings; NULL — по языку Telegram
+ALTER TABLE users ADD COLUMN language VARCHAR(8);
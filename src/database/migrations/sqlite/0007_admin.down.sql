@@ -0,0 +1,2 @@
+ALTER TABLE users DROP COLUMN broadcast_opt_out;
+ALTER TABLE users DROP COLUMN banned;
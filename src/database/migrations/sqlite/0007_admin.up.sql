@@ -0,0 +1,3 @@
+-- Блокировка пользователя администратором и отказ от рассылок
+ALTER TABLE users ADD COLUMN banned BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN broadcast_opt_out BOOLEAN NOT NULL DEFAULT FALSE;
@@ -1,14 +1,16 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"gofitness/src/model"
 
-	"log"
+	"log/slog"
+	"strings"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 type Postgres struct {
@@ -16,7 +18,7 @@ type Postgres struct {
 }
 
 
-func NewPostgres(connString string) (*Postgres, error) {
+func NewPostgres(connString string, pool Pool) (*Postgres, error) {
 	db, err := sql.Open("postgres", connString)
 	if err != nil {
 		return nil, err
@@ -27,26 +29,34 @@ func NewPostgres(connString string) (*Postgres, error) {
 	}
 
 	// Настройки пула подключений
-	db.SetMaxOpenConns(25)        // Максимум 25 одновременных подключений
-	db.SetMaxIdleConns(25)        // 25 подключений в пуле ожидания
-	db.SetConnMaxLifetime(5 * time.Minute) // Подключение живет 5 минут
+	db.SetMaxOpenConns(pool.MaxOpenConns)       // Максимум одновременных подключений
+	db.SetMaxIdleConns(pool.MaxIdleConns)       // Подключений в пуле ожидания
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime) // Сколько живет подключение
 
 	return &Postgres{db: db}, nil
 }
 
+// Init приводит схему к актуальной версии
 func (p *Postgres) Init() error {
-	return p.createStandardExercises()
+	if _, err := p.MigrateUp(); err != nil {
+		return fmt.Errorf("ошибка применения миграций: %w", err)
+	}
+	return nil
 }
 
-func (p *Postgres) GetUserByChatID(chatID int64) (*model.User, error) {
-    query := `SELECT id, chat_id, username
+func (p *Postgres) GetUserByChatID(ctx context.Context, chatID int64) (*model.User, error) {
+    query := `SELECT id, chat_id, COALESCE(username, ''), COALESCE(created_at, NOW()), COALESCE(language, ''), banned, broadcast_opt_out
               FROM users WHERE chat_id = $1`
     
     var user model.User
-    err := p.db.QueryRow(query, chatID).Scan(
+    err := p.db.QueryRowContext(ctx, query, chatID).Scan(
         &user.ID, 
 		&user.ChatID, 
 		&user.Username, 
+		&user.CreatedAt,
+		&user.Language,
+		&user.Banned,
+		&user.BroadcastOptOut,
     )
     
     if err == sql.ErrNoRows {
@@ -59,8 +69,8 @@ func (p *Postgres) GetUserByChatID(chatID int64) (*model.User, error) {
     return &user, nil
 }
 
-func (p *Postgres) GetOrCreateUser(chatID int64, username string) (*model.User, error) {
-    user, err := p.GetUserByChatID(chatID)
+func (p *Postgres) GetOrCreateUser(ctx context.Context, chatID int64, username string) (*model.User, error) {
+    user, err := p.GetUserByChatID(ctx, chatID)
     if err != nil {
         return nil, err
     }
@@ -68,22 +78,22 @@ func (p *Postgres) GetOrCreateUser(chatID int64, username string) (*model.User,
         return user, nil
     }
 
-    return p.SaveUser(chatID, username)
+    return p.SaveUser(ctx, chatID, username)
 }
 
 // Сохраняем или получаем пользователя
-func (p *Postgres) SaveUser(chatID int64, username string) (*model.User, error) {
+func (p *Postgres) SaveUser(ctx context.Context, chatID int64, username string) (*model.User, error) {
     query := `
         INSERT INTO users (chat_id, username) 
         VALUES ($1, $2) 
         ON CONFLICT (chat_id) 
         DO UPDATE SET 
             username = EXCLUDED.username
-        RETURNING id, chat_id, username, created_at
+        RETURNING id, chat_id, username, created_at, COALESCE(language, ''), banned, broadcast_opt_out
     `
     
     var user model.User
-    err := p.db.QueryRow(
+    err := p.db.QueryRowContext(ctx, 
         query, 
         chatID, 
 		username,
@@ -92,75 +102,107 @@ func (p *Postgres) SaveUser(chatID int64, username string) (*model.User, error)
         &user.ChatID, 
         &user.Username,
         &user.CreatedAt,
+        &user.Language,
+        &user.Banned,
+        &user.BroadcastOptOut,
     )
-	log.Printf("save user %d %d %s", user.ID, user.ChatID, user.Username)
 
     if err != nil {
         return nil, err
     }
+	slog.DebugContext(ctx, "Пользователь сохранён", "user_id", user.ID, "chat_id", user.ChatID)
     
     return &user, nil
 }
 
 
-// Создаем стандартные упражнения (user_id = 0 для стандартных)
-func (p *Postgres) createStandardExercises() error {
-    fmt.Println("🔄 Инициализация стандартных упражнений...")
-    
-    standardExercises := []struct {
-        name        string
-        description string
-    }{
-        {"Приседания", "Приседания со штангой"},
-        {"Жим лежа", "Жим штанги лежа"},
-        {"Становая тяга", "Классическая становая тяга"},
-        {"Подтягивания", "Подтягивания широким хватом"},
-        {"Отжимания", "Отжимания от пола"},
-        {"Жим стоя", "Армейский жим"},
-        {"Тяга штанги", "Тяга штанги в наклоне"},
-        {"Бицепс", "Подъем штанги на бицепс"},
-        {"Трицепс", "Жим лежа узким хватом"},
-        {"Планка", "Упражнение на пресс"},
-    }
+// Удаляем пользователя со всеми данными: подходами, личными упражнениями и их группами мышц
+func (p *Postgres) DeleteUser(ctx context.Context, userID int64) (err error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
 
-    successCount := 0
-    for _, exercise := range standardExercises {
-        // Сначала проверяем существует ли уже упражнение
-        var exists bool
-        checkQuery := `SELECT EXISTS(SELECT 1 FROM exercises WHERE name = $1)`
-        err := p.db.QueryRow(checkQuery, exercise.name).Scan(&exists)
-        
-        if err != nil {
-            fmt.Printf("❌ Ошибка при проверке упражнения '%s': %v\n", exercise.name, err)
-            continue
-        }
-        
-        if exists {
-            fmt.Printf("⚠️ Упражнение '%s' уже существует, пропускаем\n", exercise.name)
-            continue
-        }
-        
-        // Если не существует - добавляем
-        query := `INSERT INTO exercises (name, description, is_standard, user_id) VALUES ($1, $2, TRUE, 0)`
-        _, err = p.db.Exec(query, exercise.name, exercise.description)
-        if err != nil {
-            fmt.Printf("❌ Ошибка при добавлении упражнения '%s': %v\n", exercise.name, err)
-            continue
-        }
-        
-        fmt.Printf("✅ Добавлено упражнение: %s\n", exercise.name)
-        successCount++
-    }
-    
-    fmt.Printf("🎯 Инициализация завершена. Добавлено %d/%d упражнений\n", 
-        successCount, len(standardExercises))
-    return nil
+	queries := []string{
+		`DELETE FROM workout_sets WHERE user_id = $1`,
+		`DELETE FROM workout_sets WHERE exercise_id IN (SELECT id FROM exercises WHERE user_id = $1 AND NOT is_standard)`,
+		`DELETE FROM exercise_muscles WHERE exercise_id IN (SELECT id FROM exercises WHERE user_id = $1 AND NOT is_standard)`,
+		`DELETE FROM exercises WHERE user_id = $1 AND NOT is_standard`,
+		`DELETE FROM api_tokens WHERE user_id = $1`,
+		`DELETE FROM conversation_states WHERE chat_id IN (SELECT chat_id FROM users WHERE id = $1)`,
+		`DELETE FROM users WHERE id = $1`,
+	}
+	for _, query := range queries {
+		if _, err = tx.ExecContext(ctx, query, userID); err != nil {
+			return fmt.Errorf("ошибка удаления пользователя: %w", err)
+		}
+	}
+
+	return tx.Commit()
 }
 
-// Получаем список упражнений
-func (p *Postgres) GetExercises() ([]model.Exercise, error) {
-	query := `SELECT id, name, description FROM exercises ORDER BY name`
-	rows, err := p.db.Query(query)
+// Количество личных упражнений пользователя
+func (p *Postgres) CountCustomExercises(ctx context.Context, userID int64) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM exercises WHERE user_id = $1 AND NOT is_standard`
+	if err := p.db.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Подгружаем группы мышц для списка упражнений
+func (p *Postgres) attachMuscles(ctx context.Context, exercises []model.Exercise) error {
+	if len(exercises) == 0 {
+		return nil
+	}
+
+	rows, err := p.db.QueryContext(ctx, `SELECT exercise_id, muscle, is_primary FROM exercise_muscles ORDER BY muscle`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	index := make(map[int]int, len(exercises))
+	for i, ex := range exercises {
+		index[ex.ID] = i
+	}
+
+	for rows.Next() {
+		var exerciseID int
+		var muscle string
+		var primary bool
+		if err := rows.Scan(&exerciseID, &muscle, &primary); err != nil {
+			return err
+		}
+		i, ok := index[exerciseID]
+		if !ok {
+			continue
+		}
+		if primary {
+			exercises[i].PrimaryMuscles = append(exercises[i].PrimaryMuscles, model.MuscleGroup(muscle))
+		} else {
+			exercises[i].SecondaryMuscles = append(exercises[i].SecondaryMuscles, model.MuscleGroup(muscle))
+		}
+	}
+
+	return rows.Err()
+}
+
+// Получаем список упражнений: стандартные и личные упражнения пользователя
+func (p *Postgres) GetExercises(ctx context.Context, userID int64) ([]model.Exercise, error) {
+	query := `
+		SELECT id, name, description, is_standard, user_id
+		FROM exercises
+		WHERE is_standard OR user_id = $1
+		ORDER BY name
+	`
+	rows, err := p.db.QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -169,24 +211,217 @@ func (p *Postgres) GetExercises() ([]model.Exercise, error) {
 	var exercises []model.Exercise
 	for rows.Next() {
 		var ex model.Exercise
-		if err := rows.Scan(&ex.ID, &ex.Name, &ex.Description); err != nil {
+		var description sql.NullString
+		var ownerID sql.NullInt64
+		if err := rows.Scan(&ex.ID, &ex.Name, &description, &ex.IsStandard, &ownerID); err != nil {
 			return nil, err
 		}
+		ex.Description = description.String
+		ex.UserID = ownerID.Int64
 		exercises = append(exercises, ex)
 	}
 
+	if err := p.attachMuscles(ctx, exercises); err != nil {
+		return nil, err
+	}
+
 	return exercises, nil
 }
 
 // Сохраняем подход (вес может быть 0)
-func (p *Postgres) SaveWorkoutSet(userID int64, exerciseID int, weight float64, reps int) error {
+func (p *Postgres) SaveWorkoutSet(ctx context.Context, userID int64, exerciseID int, weight float64, reps int) error {
 	query := `INSERT INTO workout_sets (user_id, exercise_id, weight, reps) VALUES ($1, $2, $3, $4)`
-	_, err := p.db.Exec(query, userID, exerciseID, weight, reps)
+	_, err := p.db.ExecContext(ctx, query, userID, exerciseID, weight, reps)
 	return err
 }
 
+// Импорт истории одной транзакцией: создаём личные упражнения пользователя
+// и массово вставляем подходы с исходными временными метками.
+// Подходы с ExerciseID == 0 привязываются к созданному упражнению по ExerciseName.
+func (p *Postgres) ImportWorkoutSets(ctx context.Context, userID int64, newExercises []string, sets []model.WorkoutSet) (err error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	created := make(map[string]int, len(newExercises))
+	for _, name := range newExercises {
+		var id int
+		query := `INSERT INTO exercises (name, description, is_standard, user_id) VALUES ($1, '', FALSE, $2) RETURNING id`
+		if err = tx.QueryRowContext(ctx, query, name, userID).Scan(&id); err != nil {
+			return fmt.Errorf("ошибка создания упражнения '%s': %w", name, err)
+		}
+		created[name] = id
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("workout_sets", "user_id", "exercise_id", "weight", "reps", "created_at"))
+	if err != nil {
+		return err
+	}
+
+	for _, set := range sets {
+		exerciseID := set.ExerciseID
+		if exerciseID == 0 {
+			id, ok := created[set.ExerciseName]
+			if !ok {
+				stmt.Close()
+				return fmt.Errorf("упражнение '%s' не найдено", set.ExerciseName)
+			}
+			exerciseID = id
+		}
+		if _, err = stmt.ExecContext(ctx, userID, exerciseID, set.Weight, set.Reps, set.CreatedAt); err != nil {
+			stmt.Close()
+			return err
+		}
+	}
+
+	if _, err = stmt.ExecContext(ctx); err != nil {
+		stmt.Close()
+		return err
+	}
+	if err = stmt.Close(); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Личные упражнения пользователя
+func (p *Postgres) GetCustomExercises(ctx context.Context, userID int64) ([]model.Exercise, error) {
+	query := `
+		SELECT id, name, COALESCE(description, ''), created_at
+		FROM exercises
+		WHERE user_id = $1 AND NOT is_standard
+		ORDER BY name
+	`
+	rows, err := p.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var exercises []model.Exercise
+	for rows.Next() {
+		ex := model.Exercise{UserID: userID}
+		if err := rows.Scan(&ex.ID, &ex.Name, &ex.Description, &ex.CreatedAt); err != nil {
+			return nil, err
+		}
+		exercises = append(exercises, ex)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := p.attachMuscles(ctx, exercises); err != nil {
+		return nil, err
+	}
+	return exercises, nil
+}
+
+// Восстановление из резервной копии одной транзакцией. Упражнения сопоставляются по имени
+// (недостающие создаются личными), одинаковые подходы добавляются, только пока их в базе
+// меньше, чем в копии, — поэтому повторное восстановление ничего не дублирует.
+// Возвращает число добавленных подходов.
+func (p *Postgres) RestoreBackup(ctx context.Context, userID int64, exercises []model.Exercise, sets []model.WorkoutSet) (inserted int, err error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	ids := make(map[string]int)
+	resolve := func(name, description string) (int, error) {
+		key := strings.ToLower(name)
+		if id, ok := ids[key]; ok {
+			return id, nil
+		}
+
+		var id int
+		query := `
+			SELECT id FROM exercises
+			WHERE LOWER(name) = LOWER($2) AND (is_standard OR user_id = $1)
+			ORDER BY is_standard DESC
+			LIMIT 1
+		`
+		err := tx.QueryRowContext(ctx, query, userID, name).Scan(&id)
+		if err == sql.ErrNoRows {
+			query = `INSERT INTO exercises (name, description, is_standard, user_id) VALUES ($1, $2, FALSE, $3) RETURNING id`
+			err = tx.QueryRowContext(ctx, query, name, description, userID).Scan(&id)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("ошибка восстановления упражнения '%s': %w", name, err)
+		}
+
+		ids[key] = id
+		return id, nil
+	}
+
+	muscleQuery := `
+		INSERT INTO exercise_muscles (exercise_id, muscle, is_primary)
+		SELECT id, $2, $3 FROM exercises WHERE id = $1 AND NOT is_standard
+		ON CONFLICT (exercise_id, muscle) DO NOTHING
+	`
+	for _, ex := range exercises {
+		id, err := resolve(ex.Name, ex.Description)
+		if err != nil {
+			return 0, err
+		}
+		for _, muscle := range ex.PrimaryMuscles {
+			if _, err := tx.ExecContext(ctx, muscleQuery, id, string(muscle), true); err != nil {
+				return 0, err
+			}
+		}
+		for _, muscle := range ex.SecondaryMuscles {
+			if _, err := tx.ExecContext(ctx, muscleQuery, id, string(muscle), false); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO workout_sets (user_id, exercise_id, weight, reps, created_at)
+		SELECT $1, $2, $3, $4, $5
+		WHERE (
+			SELECT COUNT(*) FROM workout_sets
+			WHERE user_id = $1 AND exercise_id = $2 AND weight = $3 AND reps = $4 AND created_at = $5
+		) < $6
+	`)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	counter := make(restoreCounter)
+	for _, set := range sets {
+		exerciseID, err := resolve(set.ExerciseName, "")
+		if err != nil {
+			return 0, err
+		}
+		result, err := stmt.ExecContext(ctx, userID, exerciseID, set.Weight, set.Reps, set.CreatedAt, counter.next(exerciseID, set))
+		if err != nil {
+			return 0, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		inserted += int(affected)
+	}
+
+	return inserted, tx.Commit()
+}
+
 // Получаем историю подходов пользователя
-func (p *Postgres) GetUserWorkoutHistory(userID int64, limit int) ([]model.WorkoutSet, error) {
+func (p *Postgres) GetUserWorkoutHistory(ctx context.Context, userID int64, limit int) ([]model.WorkoutSet, error) {
 	query := `
 		SELECT ws.id, ws.exercise_id, e.name, ws.weight, ws.reps, ws.created_at 
 		FROM workout_sets ws
@@ -196,7 +431,7 @@ func (p *Postgres) GetUserWorkoutHistory(userID int64, limit int) ([]model.Worko
 		LIMIT $2
 	`
 	
-	rows, err := p.db.Query(query, userID, limit)
+	rows, err := p.db.QueryContext(ctx, query, userID, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -214,15 +449,217 @@ func (p *Postgres) GetUserWorkoutHistory(userID int64, limit int) ([]model.Worko
 	return sets, nil
 }
 
+// Проходим по всем подходам пользователя в хронологическом порядке,
+// не загружая историю в память целиком
+func (p *Postgres) StreamWorkoutSets(ctx context.Context, userID int64, fn func(model.WorkoutSet) error) error {
+	query := `
+		SELECT ws.id, ws.exercise_id, e.name, ws.weight, ws.reps, ws.created_at
+		FROM workout_sets ws
+		JOIN exercises e ON ws.exercise_id = e.id
+		WHERE ws.user_id = $1
+		ORDER BY ws.created_at ASC, ws.id ASC
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		set := model.WorkoutSet{UserID: userID}
+		if err := rows.Scan(&set.ID, &set.ExerciseID, &set.ExerciseName, &set.Weight, &set.Reps, &set.CreatedAt); err != nil {
+			return err
+		}
+		if err := fn(set); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// Подходы пользователя за [from, to) в хронологическом порядке
+func (p *Postgres) GetWorkoutSetsBetween(ctx context.Context, userID int64, from, to time.Time) ([]model.WorkoutSet, error) {
+	query := `
+		SELECT ws.id, ws.exercise_id, e.name, ws.weight, ws.reps, ws.created_at
+		FROM workout_sets ws
+		JOIN exercises e ON ws.exercise_id = e.id
+		WHERE ws.user_id = $1 AND ws.created_at >= $2 AND ws.created_at < $3
+		ORDER BY ws.created_at ASC, ws.id ASC
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sets []model.WorkoutSet
+	for rows.Next() {
+		set := model.WorkoutSet{UserID: userID}
+		if err := rows.Scan(&set.ID, &set.ExerciseID, &set.ExerciseName, &set.Weight, &set.Reps, &set.CreatedAt); err != nil {
+			return nil, err
+		}
+		sets = append(sets, set)
+	}
+
+	return sets, rows.Err()
+}
+
+// Получаем подход пользователя по ID
+func (p *Postgres) GetWorkoutSet(ctx context.Context, userID int64, setID int) (*model.WorkoutSet, error) {
+	query := `
+		SELECT ws.id, ws.exercise_id, e.name, ws.weight, ws.reps, ws.created_at
+		FROM workout_sets ws
+		JOIN exercises e ON ws.exercise_id = e.id
+		WHERE ws.user_id = $1 AND ws.id = $2
+	`
+	set := model.WorkoutSet{UserID: userID}
+	err := p.db.QueryRowContext(ctx, query, userID, setID).Scan(&set.ID, &set.ExerciseID, &set.ExerciseName, &set.Weight, &set.Reps, &set.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &set, nil
+}
+
+// Создаём подход; нулевое CreatedAt — текущее время
+func (p *Postgres) CreateWorkoutSet(ctx context.Context, set model.WorkoutSet) (*model.WorkoutSet, error) {
+	query := `
+		INSERT INTO workout_sets (user_id, exercise_id, weight, reps, created_at)
+		VALUES ($1, $2, $3, $4, COALESCE($5::timestamp, CURRENT_TIMESTAMP))
+		RETURNING id
+	`
+	var createdAt interface{}
+	if !set.CreatedAt.IsZero() {
+		createdAt = set.CreatedAt
+	}
+	var id int
+	if err := p.db.QueryRowContext(ctx, query, set.UserID, set.ExerciseID, set.Weight, set.Reps, createdAt).Scan(&id); err != nil {
+		return nil, err
+	}
+	return p.GetWorkoutSet(ctx, set.UserID, id)
+}
+
+// Обновляем упражнение, вес, повторения и время подхода
+func (p *Postgres) UpdateWorkoutSet(ctx context.Context, set model.WorkoutSet) error {
+	query := `
+		UPDATE workout_sets SET exercise_id = $3, weight = $4, reps = $5, created_at = $6
+		WHERE user_id = $1 AND id = $2
+	`
+	res, err := p.db.ExecContext(ctx, query, set.UserID, set.ID, set.ExerciseID, set.Weight, set.Reps, set.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res)
+}
+
+func (p *Postgres) DeleteWorkoutSet(ctx context.Context, userID int64, setID int) error {
+	res, err := p.db.ExecContext(ctx, `DELETE FROM workout_sets WHERE user_id = $1 AND id = $2`, userID, setID)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res)
+}
+
+// Заменяем токены API пользователя новым
+func (p *Postgres) ReplaceAPIToken(ctx context.Context, userID int64, tokenHash string) (err error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM api_tokens WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `INSERT INTO api_tokens (user_id, token_hash) VALUES ($1, $2)`, userID, tokenHash); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (p *Postgres) GetUserByAPIToken(ctx context.Context, tokenHash string) (*model.User, error) {
+	query := `
+		SELECT u.id, u.chat_id, COALESCE(u.username, ''), u.created_at, COALESCE(u.language, ''), u.banned, u.broadcast_opt_out
+		FROM api_tokens t
+		JOIN users u ON t.user_id = u.id
+		WHERE t.token_hash = $1
+	`
+	var user model.User
+	err := p.db.QueryRowContext(ctx, query, tokenHash).Scan(&user.ID, &user.ChatID, &user.Username, &user.CreatedAt, &user.Language, &user.Banned, &user.BroadcastOptOut)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка проверки токена: %w", err)
+	}
+	return &user, nil
+}
+
+func (p *Postgres) DeleteAPITokens(ctx context.Context, userID int64) error {
+	_, err := p.db.ExecContext(ctx, `DELETE FROM api_tokens WHERE user_id = $1`, userID)
+	return err
+}
+
+func (p *Postgres) SaveConversations(ctx context.Context, states map[int64][]byte) (err error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM conversation_states`); err != nil {
+		return err
+	}
+	for chatID, state := range states {
+		query := `INSERT INTO conversation_states (chat_id, state) VALUES ($1, $2)`
+		if _, err = tx.ExecContext(ctx, query, chatID, string(state)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Чтение и удаление одним запросом: диалог не восстановится дважды
+func (p *Postgres) TakeConversations(ctx context.Context) (map[int64][]byte, error) {
+	rows, err := p.db.QueryContext(ctx, `DELETE FROM conversation_states RETURNING chat_id, state`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	states := make(map[int64][]byte)
+	for rows.Next() {
+		var chatID int64
+		var state string
+		if err := rows.Scan(&chatID, &state); err != nil {
+			return nil, err
+		}
+		states[chatID] = []byte(state)
+	}
+	return states, rows.Err()
+}
+
 // В Postgres репозитории
-func (p *Postgres) GetProgressByExercise(userID int64, exerciseID int, days int) ([]model.ProgressPoint, error) {
+func (p *Postgres) GetProgressByExercise(ctx context.Context, userID int64, exerciseID int, days int) ([]model.ProgressPoint, error) {
     query := `
         SELECT 
             DATE_TRUNC('day', ws.created_at) AS day,
             SUM(ws.weight * ws.reps)          AS total_volume,
             AVG(ws.weight)                    AS avg_weight,
             AVG(ws.reps)                      AS avg_reps,
-            COUNT(*)                          AS sets_count
+            COUNT(*)                          AS sets_count,
+            MAX(ws.weight * (1 + ws.reps / 30.0)) AS best_e1rm,
+            MAX(ws.reps)                      AS max_reps
         FROM workout_sets ws
         WHERE ws.user_id = $1
           AND ws.exercise_id = $2
@@ -231,7 +668,7 @@ func (p *Postgres) GetProgressByExercise(userID int64, exerciseID int, days int)
         ORDER BY day ASC
     `
 
-    rows, err := p.db.Query(query, userID, exerciseID, days)
+    rows, err := p.db.QueryContext(ctx, query, userID, exerciseID, days)
     if err != nil {
         return nil, err
     }
@@ -241,10 +678,10 @@ func (p *Postgres) GetProgressByExercise(userID int64, exerciseID int, days int)
     for rows.Next() {
         var p model.ProgressPoint
         var day time.Time
-        var volume, avgWeight, avgReps sql.NullFloat64
-        var count int
+        var volume, avgWeight, avgReps, bestE1RM sql.NullFloat64
+        var count, maxReps int
 
-        err := rows.Scan(&day, &volume, &avgWeight, &avgReps, &count)
+        err := rows.Scan(&day, &volume, &avgWeight, &avgReps, &count, &bestE1RM, &maxReps)
         if err != nil {
             return nil, err
         }
@@ -254,6 +691,8 @@ func (p *Postgres) GetProgressByExercise(userID int64, exerciseID int, days int)
         p.AvgWeight = avgWeight.Float64
         p.AvgReps = avgReps.Float64
         p.SetsCount = count
+        p.BestE1RM = bestE1RM.Float64
+        p.MaxReps = maxReps
 
         points = append(points, p)
     }
@@ -262,27 +701,368 @@ func (p *Postgres) GetProgressByExercise(userID int64, exerciseID int, days int)
 }
 
 // Получаем упражнение по ID
-func (p *Postgres) GetExerciseByID(id int) (*model.Exercise, error) {
-	query := `SELECT id, name, description FROM exercises WHERE id = $1`
+func (p *Postgres) GetExerciseByID(ctx context.Context, id int) (*model.Exercise, error) {
+	query := `SELECT id, name, COALESCE(description, ''), is_standard, COALESCE(user_id, 0) FROM exercises WHERE id = $1`
 	var exercise model.Exercise
-	err := p.db.QueryRow(query, id).Scan(&exercise.ID, &exercise.Name, &exercise.Description)
+	err := p.db.QueryRowContext(ctx, query, id).Scan(&exercise.ID, &exercise.Name, &exercise.Description, &exercise.IsStandard, &exercise.UserID)
 	if err != nil {
 		return nil, err
 	}
-	return &exercise, nil
+
+	exercises := []model.Exercise{exercise}
+	if err := p.attachMuscles(ctx, exercises); err != nil {
+		return nil, err
+	}
+	return &exercises[0], nil
 }
 
-// Получаем упражнение по имени
-func (p *Postgres) GetExerciseByName(name string) (*model.Exercise, error) {
-	query := `SELECT id, name, description FROM exercises WHERE name ILIKE $1`
+// Получаем упражнение по имени среди стандартных и личных упражнений пользователя
+func (p *Postgres) GetExerciseByName(ctx context.Context, userID int64, name string) (*model.Exercise, error) {
+	query := `
+		SELECT id, name, COALESCE(description, '')
+		FROM exercises
+		WHERE LOWER(name) = LOWER($2) AND (is_standard OR user_id = $1)
+		ORDER BY is_standard DESC
+		LIMIT 1
+	`
 	var exercise model.Exercise
-	err := p.db.QueryRow(query, name).Scan(&exercise.ID, &exercise.Name, &exercise.Description)
+	err := p.db.QueryRowContext(ctx, query, userID, name).Scan(&exercise.ID, &exercise.Name, &exercise.Description)
+	if err != nil {
+		return nil, err
+	}
+	return &exercise, nil
+}
+
+// Язык пользователя из /settings; пустая строка — по языку Telegram
+func (p *Postgres) SetUserLanguage(ctx context.Context, userID int64, language string) error {
+	res, err := p.db.ExecContext(ctx, `UPDATE users SET language = NULLIF($2, '') WHERE id = $1`, userID, language)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res)
+}
+
+// Все пользователи бота (для рассылки отчётов)
+func (p *Postgres) GetAllUsers(ctx context.Context) ([]model.User, error) {
+	query := `SELECT id, chat_id, username, created_at, COALESCE(language, ''), banned, broadcast_opt_out FROM users ORDER BY id`
+	rows, err := p.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []model.User
+	for rows.Next() {
+		var user model.User
+		var username sql.NullString
+		if err := rows.Scan(&user.ID, &user.ChatID, &username, &user.CreatedAt, &user.Language, &user.Banned, &user.BroadcastOptOut); err != nil {
+			return nil, err
+		}
+		user.Username = username.String
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// Сводка подходов пользователя за период [from, to)
+func (p *Postgres) GetWorkoutSummary(ctx context.Context, userID int64, from, to time.Time) (*model.WorkoutSummary, error) {
+	query := `
+		SELECT
+			COUNT(DISTINCT DATE(created_at)),
+			COUNT(*),
+			COALESCE(SUM(reps), 0),
+			COALESCE(SUM(weight * reps), 0),
+			MIN(created_at),
+			MAX(created_at)
+		FROM workout_sets
+		WHERE user_id = $1
+		  AND created_at >= $2
+		  AND created_at < $3
+	`
+
+	summary := model.WorkoutSummary{From: from, To: to}
+	var firstAt, lastAt sql.NullTime
+	err := p.db.QueryRowContext(ctx, query, userID, from, to).Scan(
+		&summary.Sessions,
+		&summary.Sets,
+		&summary.Reps,
+		&summary.Tonnage,
+		&firstAt,
+		&lastAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подсчёта сводки: %w", err)
+	}
+
+	summary.FirstAt = firstAt.Time
+	summary.LastAt = lastAt.Time
+	return &summary, nil
+}
+
+// Лучшие результаты по каждому упражнению за период [from, to)
+func (p *Postgres) GetExerciseBests(ctx context.Context, userID int64, from, to time.Time) ([]model.ExerciseBest, error) {
+	query := `
+		SELECT
+			e.id,
+			e.name,
+			MAX(ws.weight),
+			MAX(ws.reps),
+			MAX(ws.weight * (1 + ws.reps / 30.0)),
+			COUNT(*),
+			SUM(ws.weight * ws.reps)
+		FROM workout_sets ws
+		JOIN exercises e ON ws.exercise_id = e.id
+		WHERE ws.user_id = $1
+		  AND ws.created_at >= $2
+		  AND ws.created_at < $3
+		GROUP BY e.id, e.name
+		ORDER BY e.name
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bests []model.ExerciseBest
+	for rows.Next() {
+		var best model.ExerciseBest
+		err := rows.Scan(
+			&best.ExerciseID,
+			&best.ExerciseName,
+			&best.MaxWeight,
+			&best.MaxReps,
+			&best.BestE1RM,
+			&best.Sets,
+			&best.Tonnage,
+		)
+		if err != nil {
+			return nil, err
+		}
+		bests = append(bests, best)
+	}
+
+	return bests, rows.Err()
+}
+
+// Агрегаты подходов по дням за период [from, to)
+func (p *Postgres) GetDailyActivity(ctx context.Context, userID int64, from, to time.Time) ([]model.DayActivity, error) {
+	query := `
+		SELECT
+			DATE(created_at)   AS day,
+			COUNT(*)           AS sets_count,
+			SUM(reps)          AS total_reps,
+			SUM(weight * reps) AS tonnage
+		FROM workout_sets
+		WHERE user_id = $1
+		  AND created_at >= $2
+		  AND created_at < $3
+		GROUP BY day
+		ORDER BY day ASC
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var days []model.DayActivity
+	for rows.Next() {
+		var day model.DayActivity
+		if err := rows.Scan(&day.Date, &day.Sets, &day.Reps, &day.Tonnage); err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+	}
+
+	return days, rows.Err()
+}
+
+// Недельный объём в тяжёлых подходах по группам мышц начиная с from.
+// Разминочные подходы (легче половины рабочего веса за день) не учитываются.
+func (p *Postgres) GetWeeklyMuscleVolume(ctx context.Context, userID int64, from time.Time) ([]model.MuscleVolume, error) {
+	query := `
+		WITH sets AS (
+			SELECT
+				exercise_id,
+				created_at,
+				weight,
+				MAX(weight) OVER (PARTITION BY exercise_id, DATE(created_at)) AS day_max
+			FROM workout_sets
+			WHERE user_id = $1
+			  AND created_at >= $2
+		)
+		SELECT
+			DATE_TRUNC('week', s.created_at) AS week,
+			em.muscle,
+			SUM(CASE WHEN em.is_primary THEN 1.0 ELSE 0.5 END) AS hard_sets
+		FROM sets s
+		JOIN exercise_muscles em ON em.exercise_id = s.exercise_id
+		WHERE s.weight >= 0.5 * s.day_max
+		GROUP BY week, em.muscle
+		ORDER BY week ASC, em.muscle ASC
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, userID, from)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var volumes []model.MuscleVolume
+	for rows.Next() {
+		var volume model.MuscleVolume
+		var muscle string
+		if err := rows.Scan(&volume.Week, &muscle, &volume.Sets); err != nil {
+			return nil, err
+		}
+		volume.Muscle = model.MuscleGroup(muscle)
+		volumes = append(volumes, volume)
+	}
+
+	return volumes, rows.Err()
+}
+
+// Блокировка пользователя администратором
+func (p *Postgres) SetUserBanned(ctx context.Context, userID int64, banned bool) error {
+	res, err := p.db.ExecContext(ctx, `UPDATE users SET banned = $2 WHERE id = $1`, userID, banned)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res)
+}
+
+// Отказ от рассылок администратора
+func (p *Postgres) SetBroadcastOptOut(ctx context.Context, userID int64, optOut bool) error {
+	res, err := p.db.ExecContext(ctx, `UPDATE users SET broadcast_opt_out = $2 WHERE id = $1`, userID, optOut)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res)
+}
+
+// Пользователи, записавшие хотя бы один подход начиная с since
+func (p *Postgres) CountActiveUsers(ctx context.Context, since time.Time) (int, error) {
+	var count int
+	query := `SELECT COUNT(DISTINCT user_id) FROM workout_sets WHERE created_at >= $1`
+	if err := p.db.QueryRowContext(ctx, query, since).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Агрегаты подходов всех пользователей по дням за период [from, to)
+func (p *Postgres) GetTotalDailyActivity(ctx context.Context, from, to time.Time) ([]model.DayActivity, error) {
+	query := `
+		SELECT
+			DATE(created_at)   AS day,
+			COUNT(*)           AS sets_count,
+			SUM(reps)          AS total_reps,
+			SUM(weight * reps) AS tonnage
+		FROM workout_sets
+		WHERE created_at >= $1
+		  AND created_at < $2
+		GROUP BY day
+		ORDER BY day ASC
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var days []model.DayActivity
+	for rows.Next() {
+		var day model.DayActivity
+		if err := rows.Scan(&day.Date, &day.Sets, &day.Reps, &day.Tonnage); err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+	}
+
+	return days, rows.Err()
+}
+
+// Новое стандартное упражнение с группами мышц
+func (p *Postgres) CreateStandardExercise(ctx context.Context, exercise model.Exercise) (created *model.Exercise, err error) {
+	tx, err := p.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	query := `INSERT INTO exercises (name, description, is_standard, user_id) VALUES ($1, $2, TRUE, 0) RETURNING id, created_at`
+	if err = tx.QueryRowContext(ctx, query, exercise.Name, exercise.Description).Scan(&exercise.ID, &exercise.CreatedAt); err != nil {
+		return nil, fmt.Errorf("ошибка создания упражнения: %w", err)
+	}
+	if err = replaceMuscles(ctx, tx, exercise); err != nil {
+		return nil, fmt.Errorf("ошибка сохранения групп мышц: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	exercise.IsStandard = true
+	exercise.UserID = 0
 	return &exercise, nil
 }
 
+// Название, описание и группы мышц стандартного упражнения
+func (p *Postgres) UpdateStandardExercise(ctx context.Context, exercise model.Exercise) (err error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	query := `UPDATE exercises SET name = $2, description = $3 WHERE id = $1 AND is_standard`
+	res, err := tx.ExecContext(ctx, query, exercise.ID, exercise.Name, exercise.Description)
+	if err != nil {
+		return err
+	}
+	if err = expectAffected(res); err != nil {
+		return err
+	}
+	if err = replaceMuscles(ctx, tx, exercise); err != nil {
+		return fmt.Errorf("ошибка сохранения групп мышц: %w", err)
+	}
+	return tx.Commit()
+}
+
+// Удаляем стандартное упражнение, по которому нет подходов
+func (p *Postgres) DeleteStandardExercise(ctx context.Context, id int) (err error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = deleteStandardExercise(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (p *Postgres) Ping(ctx context.Context) error {
+	return p.db.PingContext(ctx)
+}
+
 func (p *Postgres) Close() error {
 	return p.db.Close()
 }
\ No newline at end of file
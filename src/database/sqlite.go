@@ -0,0 +1,1150 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"gofitness/src/model"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLite — хранилище в одном файле для самостоятельного запуска без Postgres.
+// Схема та же, что и в Postgres; время хранится строкой в местном времени.
+type SQLite struct {
+	db *sql.DB
+}
+
+// Формат времени в колонках TIMESTAMP, совпадает с strftime('%Y-%m-%d %H:%M:%f')
+const sqliteTimeLayout = "2006-01-02 15:04:05.000"
+
+// NewSQLite открывает базу по адресу sqlite://путь/к/файлу.db
+func NewSQLite(url string) (*SQLite, error) {
+	path := strings.TrimPrefix(strings.TrimPrefix(url, "sqlite:"), "//")
+	if path == "" {
+		return nil, fmt.Errorf("не указан путь к файлу SQLite")
+	}
+
+	dsn := "file:" + path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	// SQLite допускает одного писателя: одно подключение избавляет от «database is locked»
+	db.SetMaxOpenConns(1)
+
+	return &SQLite{db: db}, nil
+}
+
+// Время для параметра запроса: местное, в формате колонок
+func sqliteTime(t time.Time) string {
+	return t.In(time.Local).Format(sqliteTimeLayout)
+}
+
+// sqliteTimeValue читает время, которое драйвер вернул строкой
+// (результаты агрегатов и выражений) или уже разобранным. В колонках хранится
+// местное время без смещения, поэтому оба варианта трактуются как time.Local
+type sqliteTimeValue struct {
+	Time  time.Time
+	Valid bool
+}
+
+func (v *sqliteTimeValue) Scan(src interface{}) error {
+	var text string
+	switch value := src.(type) {
+	case nil:
+		v.Time, v.Valid = time.Time{}, false
+		return nil
+	case time.Time:
+		// Драйвер разбирает строку без смещения как UTC — переносим показания часов в местное время
+		v.Time, v.Valid = time.Date(value.Year(), value.Month(), value.Day(),
+			value.Hour(), value.Minute(), value.Second(), value.Nanosecond(), time.Local), true
+		return nil
+	case string:
+		text = value
+	case []byte:
+		text = string(value)
+	default:
+		return fmt.Errorf("неожиданный тип времени %T", src)
+	}
+
+	for _, layout := range []string{"2006-01-02 15:04:05.999999999", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, text, time.Local); err == nil {
+			v.Time, v.Valid = t, true
+			return nil
+		}
+	}
+	return fmt.Errorf("некорректное время %q", text)
+}
+
+func (s *SQLite) migrator() *migrator {
+	return &migrator{db: s.db, dir: "migrations/sqlite"}
+}
+
+// Init приводит схему к актуальной версии
+func (s *SQLite) Init() error {
+	if _, err := s.MigrateUp(); err != nil {
+		return fmt.Errorf("ошибка применения миграций: %w", err)
+	}
+	return nil
+}
+
+// MigrateUp применяет все ещё не применённые миграции. Возвращает их количество.
+func (s *SQLite) MigrateUp() (int, error) {
+	return s.migrator().Up()
+}
+
+// MigrateDown откатывает последнюю применённую миграцию. Возвращает nil, если откатывать нечего.
+func (s *SQLite) MigrateDown() (*Migration, error) {
+	return s.migrator().Down()
+}
+
+// MigrationStatus — список всех миграций с отметкой, применены ли они
+func (s *SQLite) MigrationStatus() ([]MigrationStatus, error) {
+	return s.migrator().Status()
+}
+
+func (s *SQLite) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+func (s *SQLite) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLite) GetUserByChatID(ctx context.Context, chatID int64) (*model.User, error) {
+	query := `SELECT id, chat_id, COALESCE(username, ''), created_at, COALESCE(language, ''), banned, broadcast_opt_out FROM users WHERE chat_id = $1`
+
+	var user model.User
+	var createdAt sqliteTimeValue
+	err := s.db.QueryRowContext(ctx, query, chatID).Scan(&user.ID, &user.ChatID, &user.Username, &createdAt, &user.Language, &user.Banned, &user.BroadcastOptOut)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса пользователя: %w", err)
+	}
+
+	user.CreatedAt = createdAt.Time
+	return &user, nil
+}
+
+func (s *SQLite) GetOrCreateUser(ctx context.Context, chatID int64, username string) (*model.User, error) {
+	user, err := s.GetUserByChatID(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		return user, nil
+	}
+
+	return s.SaveUser(ctx, chatID, username)
+}
+
+// Сохраняем или получаем пользователя
+func (s *SQLite) SaveUser(ctx context.Context, chatID int64, username string) (*model.User, error) {
+	query := `
+		INSERT INTO users (chat_id, username)
+		VALUES ($1, $2)
+		ON CONFLICT (chat_id)
+		DO UPDATE SET username = excluded.username
+		RETURNING id, chat_id, username, created_at, COALESCE(language, ''), banned, broadcast_opt_out
+	`
+
+	var user model.User
+	var createdAt sqliteTimeValue
+	err := s.db.QueryRowContext(ctx, query, chatID, username).Scan(&user.ID, &user.ChatID, &user.Username, &createdAt, &user.Language, &user.Banned, &user.BroadcastOptOut)
+	if err != nil {
+		return nil, err
+	}
+
+	user.CreatedAt = createdAt.Time
+	return &user, nil
+}
+
+// Язык пользователя из /settings; пустая строка — по языку Telegram
+func (s *SQLite) SetUserLanguage(ctx context.Context, userID int64, language string) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE users SET language = NULLIF($2, '') WHERE id = $1`, userID, language)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res)
+}
+
+// Все пользователи бота (для рассылки отчётов)
+func (s *SQLite) GetAllUsers(ctx context.Context) ([]model.User, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, chat_id, COALESCE(username, ''), created_at, COALESCE(language, ''), banned, broadcast_opt_out FROM users ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []model.User
+	for rows.Next() {
+		var user model.User
+		var createdAt sqliteTimeValue
+		if err := rows.Scan(&user.ID, &user.ChatID, &user.Username, &createdAt, &user.Language, &user.Banned, &user.BroadcastOptOut); err != nil {
+			return nil, err
+		}
+		user.CreatedAt = createdAt.Time
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// Удаляем пользователя со всеми данными: подходами, личными упражнениями и их группами мышц
+func (s *SQLite) DeleteUser(ctx context.Context, userID int64) (err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	queries := []string{
+		`DELETE FROM workout_sets WHERE user_id = $1`,
+		`DELETE FROM workout_sets WHERE exercise_id IN (SELECT id FROM exercises WHERE user_id = $1 AND NOT is_standard)`,
+		`DELETE FROM exercise_muscles WHERE exercise_id IN (SELECT id FROM exercises WHERE user_id = $1 AND NOT is_standard)`,
+		`DELETE FROM exercises WHERE user_id = $1 AND NOT is_standard`,
+		`DELETE FROM api_tokens WHERE user_id = $1`,
+		`DELETE FROM conversation_states WHERE chat_id IN (SELECT chat_id FROM users WHERE id = $1)`,
+		`DELETE FROM users WHERE id = $1`,
+	}
+	for _, query := range queries {
+		if _, err = tx.ExecContext(ctx, query, userID); err != nil {
+			return fmt.Errorf("ошибка удаления пользователя: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Подгружаем группы мышц для списка упражнений
+func (s *SQLite) attachMuscles(ctx context.Context, exercises []model.Exercise) error {
+	if len(exercises) == 0 {
+		return nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT exercise_id, muscle, is_primary FROM exercise_muscles ORDER BY muscle`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	index := make(map[int]int, len(exercises))
+	for i, ex := range exercises {
+		index[ex.ID] = i
+	}
+
+	for rows.Next() {
+		var exerciseID int
+		var muscle string
+		var primary bool
+		if err := rows.Scan(&exerciseID, &muscle, &primary); err != nil {
+			return err
+		}
+		i, ok := index[exerciseID]
+		if !ok {
+			continue
+		}
+		if primary {
+			exercises[i].PrimaryMuscles = append(exercises[i].PrimaryMuscles, model.MuscleGroup(muscle))
+		} else {
+			exercises[i].SecondaryMuscles = append(exercises[i].SecondaryMuscles, model.MuscleGroup(muscle))
+		}
+	}
+
+	return rows.Err()
+}
+
+// Читаем упражнения целиком и закрываем курсор: подключение одно,
+// а группы мышц подгружаются следующим запросом
+func (s *SQLite) queryExercises(ctx context.Context, query string, args ...interface{}) ([]model.Exercise, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var exercises []model.Exercise
+	for rows.Next() {
+		var ex model.Exercise
+		var createdAt sqliteTimeValue
+		if err := rows.Scan(&ex.ID, &ex.Name, &ex.Description, &ex.IsStandard, &ex.UserID, &createdAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ex.CreatedAt = createdAt.Time
+		exercises = append(exercises, ex)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, err
+	}
+	rows.Close()
+
+	if err := s.attachMuscles(ctx, exercises); err != nil {
+		return nil, err
+	}
+	return exercises, nil
+}
+
+// Получаем список упражнений: стандартные и личные упражнения пользователя
+func (s *SQLite) GetExercises(ctx context.Context, userID int64) ([]model.Exercise, error) {
+	query := `
+		SELECT id, name, COALESCE(description, ''), is_standard, COALESCE(user_id, 0), created_at
+		FROM exercises
+		WHERE is_standard OR user_id = $1
+		ORDER BY name
+	`
+	return s.queryExercises(ctx, query, userID)
+}
+
+// Получаем упражнение по ID
+func (s *SQLite) GetExerciseByID(ctx context.Context, id int) (*model.Exercise, error) {
+	query := `
+		SELECT id, name, COALESCE(description, ''), is_standard, COALESCE(user_id, 0), created_at
+		FROM exercises
+		WHERE id = $1
+	`
+	exercises, err := s.queryExercises(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(exercises) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &exercises[0], nil
+}
+
+// sqliteQueryer — *sql.DB или *sql.Tx
+type sqliteQueryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Ищем упражнение по имени без учёта регистра, стандартное в приоритете.
+// LIKE в SQLite не сравнивает кириллицу без учёта регистра, поэтому сравниваем в Go.
+func findExerciseID(ctx context.Context, q sqliteQueryer, userID int64, name string) (int, error) {
+	query := `
+		SELECT id, name FROM exercises
+		WHERE is_standard OR user_id = $1
+		ORDER BY is_standard DESC, id
+	`
+	rows, err := q.QueryContext(ctx, query, userID)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var candidate string
+		if err := rows.Scan(&id, &candidate); err != nil {
+			return 0, err
+		}
+		if strings.EqualFold(candidate, name) {
+			return id, nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	return 0, sql.ErrNoRows
+}
+
+// Получаем упражнение по имени среди стандартных и личных упражнений пользователя
+func (s *SQLite) GetExerciseByName(ctx context.Context, userID int64, name string) (*model.Exercise, error) {
+	id, err := findExerciseID(ctx, s.db, userID, name)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetExerciseByID(ctx, id)
+}
+
+// Личные упражнения пользователя
+func (s *SQLite) GetCustomExercises(ctx context.Context, userID int64) ([]model.Exercise, error) {
+	query := `
+		SELECT id, name, COALESCE(description, ''), is_standard, COALESCE(user_id, 0), created_at
+		FROM exercises
+		WHERE user_id = $1 AND NOT is_standard
+		ORDER BY name
+	`
+	return s.queryExercises(ctx, query, userID)
+}
+
+// Количество личных упражнений пользователя
+func (s *SQLite) CountCustomExercises(ctx context.Context, userID int64) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM exercises WHERE user_id = $1 AND NOT is_standard`
+	if err := s.db.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Сохраняем подход (вес может быть 0)
+func (s *SQLite) SaveWorkoutSet(ctx context.Context, userID int64, exerciseID int, weight float64, reps int) error {
+	query := `INSERT INTO workout_sets (user_id, exercise_id, weight, reps, created_at) VALUES ($1, $2, $3, $4, $5)`
+	_, err := s.db.ExecContext(ctx, query, userID, exerciseID, weight, reps, sqliteTime(time.Now()))
+	return err
+}
+
+// Импорт истории одной транзакцией, как в Postgres, но обычными вставками вместо COPY
+func (s *SQLite) ImportWorkoutSets(ctx context.Context, userID int64, newExercises []string, sets []model.WorkoutSet) (err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	created := make(map[string]int, len(newExercises))
+	for _, name := range newExercises {
+		query := `INSERT INTO exercises (name, description, is_standard, user_id) VALUES ($1, '', FALSE, $2)`
+		result, err := tx.ExecContext(ctx, query, name, userID)
+		if err != nil {
+			return fmt.Errorf("ошибка создания упражнения '%s': %w", name, err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		created[name] = int(id)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO workout_sets (user_id, exercise_id, weight, reps, created_at) VALUES ($1, $2, $3, $4, $5)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, set := range sets {
+		exerciseID := set.ExerciseID
+		if exerciseID == 0 {
+			id, ok := created[set.ExerciseName]
+			if !ok {
+				return fmt.Errorf("упражнение '%s' не найдено", set.ExerciseName)
+			}
+			exerciseID = id
+		}
+		if _, err = stmt.ExecContext(ctx, userID, exerciseID, set.Weight, set.Reps, sqliteTime(set.CreatedAt)); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Восстановление из резервной копии одной транзакцией; повторное восстановление ничего не дублирует
+func (s *SQLite) RestoreBackup(ctx context.Context, userID int64, exercises []model.Exercise, sets []model.WorkoutSet) (inserted int, err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	ids := make(map[string]int)
+	resolve := func(name, description string) (int, error) {
+		key := strings.ToLower(name)
+		if id, ok := ids[key]; ok {
+			return id, nil
+		}
+
+		id, err := findExerciseID(ctx, tx, userID, name)
+		if err == sql.ErrNoRows {
+			var result sql.Result
+			query := `INSERT INTO exercises (name, description, is_standard, user_id) VALUES ($1, $2, FALSE, $3)`
+			result, err = tx.ExecContext(ctx, query, name, description, userID)
+			if err == nil {
+				var lastID int64
+				lastID, err = result.LastInsertId()
+				id = int(lastID)
+			}
+		}
+		if err != nil {
+			return 0, fmt.Errorf("ошибка восстановления упражнения '%s': %w", name, err)
+		}
+
+		ids[key] = id
+		return id, nil
+	}
+
+	muscleQuery := `
+		INSERT OR IGNORE INTO exercise_muscles (exercise_id, muscle, is_primary)
+		SELECT id, $2, $3 FROM exercises WHERE id = $1 AND NOT is_standard
+	`
+	for _, ex := range exercises {
+		id, err := resolve(ex.Name, ex.Description)
+		if err != nil {
+			return 0, err
+		}
+		for _, muscle := range ex.PrimaryMuscles {
+			if _, err := tx.ExecContext(ctx, muscleQuery, id, string(muscle), true); err != nil {
+				return 0, err
+			}
+		}
+		for _, muscle := range ex.SecondaryMuscles {
+			if _, err := tx.ExecContext(ctx, muscleQuery, id, string(muscle), false); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO workout_sets (user_id, exercise_id, weight, reps, created_at)
+		SELECT $1, $2, $3, $4, $5
+		WHERE (
+			SELECT COUNT(*) FROM workout_sets
+			WHERE user_id = $1 AND exercise_id = $2 AND weight = $3 AND reps = $4 AND created_at = $5
+		) < $6
+	`)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	counter := make(restoreCounter)
+	for _, set := range sets {
+		exerciseID, err := resolve(set.ExerciseName, "")
+		if err != nil {
+			return 0, err
+		}
+		result, err := stmt.ExecContext(ctx, userID, exerciseID, set.Weight, set.Reps, sqliteTime(set.CreatedAt), counter.next(exerciseID, set))
+		if err != nil {
+			return 0, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		inserted += int(affected)
+	}
+
+	return inserted, tx.Commit()
+}
+
+func scanSets(rows *sql.Rows, userID int64, fn func(model.WorkoutSet) error) error {
+	defer rows.Close()
+
+	for rows.Next() {
+		set := model.WorkoutSet{UserID: userID}
+		var createdAt sqliteTimeValue
+		if err := rows.Scan(&set.ID, &set.ExerciseID, &set.ExerciseName, &set.Weight, &set.Reps, &createdAt); err != nil {
+			return err
+		}
+		set.CreatedAt = createdAt.Time
+		if err := fn(set); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// Получаем историю подходов пользователя
+func (s *SQLite) GetUserWorkoutHistory(ctx context.Context, userID int64, limit int) ([]model.WorkoutSet, error) {
+	query := `
+		SELECT ws.id, ws.exercise_id, e.name, ws.weight, ws.reps, ws.created_at
+		FROM workout_sets ws
+		JOIN exercises e ON ws.exercise_id = e.id
+		WHERE ws.user_id = $1
+		ORDER BY ws.created_at DESC
+		LIMIT $2
+	`
+	rows, err := s.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var sets []model.WorkoutSet
+	err = scanSets(rows, userID, func(set model.WorkoutSet) error {
+		sets = append(sets, set)
+		return nil
+	})
+	return sets, err
+}
+
+// Сколько подходов StreamWorkoutSets читает за один запрос
+const streamBatchSize = 500
+
+// Проходим по всем подходам пользователя в хронологическом порядке.
+// Подключение у SQLite одно, поэтому читаем страницами по (created_at, id) и закрываем
+// курсор перед вызовом fn: иначе медленный fn (отправка файла, запросы к базе)
+// блокировал бы всех остальных, а в память попадает не больше одной страницы
+func (s *SQLite) StreamWorkoutSets(ctx context.Context, userID int64, fn func(model.WorkoutSet) error) error {
+	query := `
+		SELECT ws.id, ws.exercise_id, e.name, ws.weight, ws.reps, ws.created_at
+		FROM workout_sets ws
+		JOIN exercises e ON ws.exercise_id = e.id
+		WHERE ws.user_id = $1 AND (ws.created_at > $2 OR (ws.created_at = $2 AND ws.id > $3))
+		ORDER BY ws.created_at ASC, ws.id ASC
+		LIMIT $4
+	`
+
+	afterTime, afterID := "", 0
+	for {
+		rows, err := s.db.QueryContext(ctx, query, userID, afterTime, afterID, streamBatchSize)
+		if err != nil {
+			return err
+		}
+
+		batch := make([]model.WorkoutSet, 0, streamBatchSize)
+		err = scanSets(rows, userID, func(set model.WorkoutSet) error {
+			batch = append(batch, set)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, set := range batch {
+			if err := fn(set); err != nil {
+				return err
+			}
+		}
+		if len(batch) < streamBatchSize {
+			return nil
+		}
+
+		last := batch[len(batch)-1]
+		afterTime, afterID = sqliteTime(last.CreatedAt), last.ID
+	}
+}
+
+// Подходы пользователя за [from, to) в хронологическом порядке
+func (s *SQLite) GetWorkoutSetsBetween(ctx context.Context, userID int64, from, to time.Time) ([]model.WorkoutSet, error) {
+	query := `
+		SELECT ws.id, ws.exercise_id, e.name, ws.weight, ws.reps, ws.created_at
+		FROM workout_sets ws
+		JOIN exercises e ON ws.exercise_id = e.id
+		WHERE ws.user_id = $1 AND ws.created_at >= $2 AND ws.created_at < $3
+		ORDER BY ws.created_at ASC, ws.id ASC
+	`
+	rows, err := s.db.QueryContext(ctx, query, userID, sqliteTime(from), sqliteTime(to))
+	if err != nil {
+		return nil, err
+	}
+
+	var sets []model.WorkoutSet
+	err = scanSets(rows, userID, func(set model.WorkoutSet) error {
+		sets = append(sets, set)
+		return nil
+	})
+	return sets, err
+}
+
+// Получаем подход пользователя по ID
+func (s *SQLite) GetWorkoutSet(ctx context.Context, userID int64, setID int) (*model.WorkoutSet, error) {
+	query := `
+		SELECT ws.id, ws.exercise_id, e.name, ws.weight, ws.reps, ws.created_at
+		FROM workout_sets ws
+		JOIN exercises e ON ws.exercise_id = e.id
+		WHERE ws.user_id = $1 AND ws.id = $2
+	`
+	set := model.WorkoutSet{UserID: userID}
+	var createdAt sqliteTimeValue
+	err := s.db.QueryRowContext(ctx, query, userID, setID).Scan(&set.ID, &set.ExerciseID, &set.ExerciseName, &set.Weight, &set.Reps, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+	set.CreatedAt = createdAt.Time
+	return &set, nil
+}
+
+// Создаём подход; нулевое CreatedAt — текущее время
+func (s *SQLite) CreateWorkoutSet(ctx context.Context, set model.WorkoutSet) (*model.WorkoutSet, error) {
+	if set.CreatedAt.IsZero() {
+		set.CreatedAt = time.Now()
+	}
+	query := `
+		INSERT INTO workout_sets (user_id, exercise_id, weight, reps, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	var id int
+	if err := s.db.QueryRowContext(ctx, query, set.UserID, set.ExerciseID, set.Weight, set.Reps, sqliteTime(set.CreatedAt)).Scan(&id); err != nil {
+		return nil, err
+	}
+	return s.GetWorkoutSet(ctx, set.UserID, id)
+}
+
+// Обновляем упражнение, вес, повторения и время подхода
+func (s *SQLite) UpdateWorkoutSet(ctx context.Context, set model.WorkoutSet) error {
+	query := `
+		UPDATE workout_sets SET exercise_id = $3, weight = $4, reps = $5, created_at = $6
+		WHERE user_id = $1 AND id = $2
+	`
+	res, err := s.db.ExecContext(ctx, query, set.UserID, set.ID, set.ExerciseID, set.Weight, set.Reps, sqliteTime(set.CreatedAt))
+	if err != nil {
+		return err
+	}
+	return expectAffected(res)
+}
+
+func (s *SQLite) DeleteWorkoutSet(ctx context.Context, userID int64, setID int) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM workout_sets WHERE user_id = $1 AND id = $2`, userID, setID)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res)
+}
+
+// Заменяем токены API пользователя новым
+func (s *SQLite) ReplaceAPIToken(ctx context.Context, userID int64, tokenHash string) (err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM api_tokens WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	query := `INSERT INTO api_tokens (user_id, token_hash, created_at) VALUES ($1, $2, $3)`
+	if _, err = tx.ExecContext(ctx, query, userID, tokenHash, sqliteTime(time.Now())); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLite) GetUserByAPIToken(ctx context.Context, tokenHash string) (*model.User, error) {
+	query := `
+		SELECT u.id, u.chat_id, COALESCE(u.username, ''), u.created_at, COALESCE(u.language, ''), u.banned, u.broadcast_opt_out
+		FROM api_tokens t
+		JOIN users u ON t.user_id = u.id
+		WHERE t.token_hash = $1
+	`
+	var user model.User
+	var createdAt sqliteTimeValue
+	err := s.db.QueryRowContext(ctx, query, tokenHash).Scan(&user.ID, &user.ChatID, &user.Username, &createdAt, &user.Language, &user.Banned, &user.BroadcastOptOut)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка проверки токена: %w", err)
+	}
+	user.CreatedAt = createdAt.Time
+	return &user, nil
+}
+
+func (s *SQLite) DeleteAPITokens(ctx context.Context, userID int64) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM api_tokens WHERE user_id = $1`, userID)
+	return err
+}
+
+func (s *SQLite) SaveConversations(ctx context.Context, states map[int64][]byte) (err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM conversation_states`); err != nil {
+		return err
+	}
+	savedAt := sqliteTime(time.Now())
+	for chatID, state := range states {
+		query := `INSERT INTO conversation_states (chat_id, state, saved_at) VALUES ($1, $2, $3)`
+		if _, err = tx.ExecContext(ctx, query, chatID, string(state), savedAt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Чтение и удаление одним запросом: диалог не восстановится дважды
+func (s *SQLite) TakeConversations(ctx context.Context) (map[int64][]byte, error) {
+	rows, err := s.db.QueryContext(ctx, `DELETE FROM conversation_states RETURNING chat_id, state`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	states := make(map[int64][]byte)
+	for rows.Next() {
+		var chatID int64
+		var state string
+		if err := rows.Scan(&chatID, &state); err != nil {
+			return nil, err
+		}
+		states[chatID] = []byte(state)
+	}
+	return states, rows.Err()
+}
+
+// Прогресс по упражнению за days дней: вместо DATE_TRUNC и INTERVAL — DATE() и граница, посчитанная в Go
+func (s *SQLite) GetProgressByExercise(ctx context.Context, userID int64, exerciseID int, days int) ([]model.ProgressPoint, error) {
+	query := `
+		SELECT
+			DATE(ws.created_at)                   AS day,
+			SUM(ws.weight * ws.reps)              AS total_volume,
+			AVG(ws.weight)                        AS avg_weight,
+			AVG(ws.reps)                          AS avg_reps,
+			COUNT(*)                              AS sets_count,
+			MAX(ws.weight * (1 + ws.reps / 30.0)) AS best_e1rm,
+			MAX(ws.reps)                          AS max_reps
+		FROM workout_sets ws
+		WHERE ws.user_id = $1
+		  AND ws.exercise_id = $2
+		  AND ws.created_at >= $3
+		GROUP BY day
+		ORDER BY day ASC
+	`
+
+	from := time.Now().AddDate(0, 0, -days)
+	rows, err := s.db.QueryContext(ctx, query, userID, exerciseID, sqliteTime(from))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []model.ProgressPoint
+	for rows.Next() {
+		var p model.ProgressPoint
+		var day sqliteTimeValue
+		var volume, avgWeight, avgReps, bestE1RM sql.NullFloat64
+
+		err := rows.Scan(&day, &volume, &avgWeight, &avgReps, &p.SetsCount, &bestE1RM, &p.MaxReps)
+		if err != nil {
+			return nil, err
+		}
+
+		p.Date = day.Time
+		p.TotalVolume = volume.Float64
+		p.AvgWeight = avgWeight.Float64
+		p.AvgReps = avgReps.Float64
+		p.BestE1RM = bestE1RM.Float64
+
+		points = append(points, p)
+	}
+
+	return points, rows.Err()
+}
+
+// Сводка подходов пользователя за период [from, to)
+func (s *SQLite) GetWorkoutSummary(ctx context.Context, userID int64, from, to time.Time) (*model.WorkoutSummary, error) {
+	query := `
+		SELECT
+			COUNT(DISTINCT DATE(created_at)),
+			COUNT(*),
+			COALESCE(SUM(reps), 0),
+			COALESCE(SUM(weight * reps), 0),
+			MIN(created_at),
+			MAX(created_at)
+		FROM workout_sets
+		WHERE user_id = $1
+		  AND created_at >= $2
+		  AND created_at < $3
+	`
+
+	summary := model.WorkoutSummary{From: from, To: to}
+	var firstAt, lastAt sqliteTimeValue
+	err := s.db.QueryRowContext(ctx, query, userID, sqliteTime(from), sqliteTime(to)).Scan(
+		&summary.Sessions,
+		&summary.Sets,
+		&summary.Reps,
+		&summary.Tonnage,
+		&firstAt,
+		&lastAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подсчёта сводки: %w", err)
+	}
+
+	summary.FirstAt = firstAt.Time
+	summary.LastAt = lastAt.Time
+	return &summary, nil
+}
+
+// Лучшие результаты по каждому упражнению за период [from, to)
+func (s *SQLite) GetExerciseBests(ctx context.Context, userID int64, from, to time.Time) ([]model.ExerciseBest, error) {
+	query := `
+		SELECT
+			e.id,
+			e.name,
+			MAX(ws.weight),
+			MAX(ws.reps),
+			MAX(ws.weight * (1 + ws.reps / 30.0)),
+			COUNT(*),
+			SUM(ws.weight * ws.reps)
+		FROM workout_sets ws
+		JOIN exercises e ON ws.exercise_id = e.id
+		WHERE ws.user_id = $1
+		  AND ws.created_at >= $2
+		  AND ws.created_at < $3
+		GROUP BY e.id, e.name
+		ORDER BY e.name
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, userID, sqliteTime(from), sqliteTime(to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bests []model.ExerciseBest
+	for rows.Next() {
+		var best model.ExerciseBest
+		err := rows.Scan(
+			&best.ExerciseID,
+			&best.ExerciseName,
+			&best.MaxWeight,
+			&best.MaxReps,
+			&best.BestE1RM,
+			&best.Sets,
+			&best.Tonnage,
+		)
+		if err != nil {
+			return nil, err
+		}
+		bests = append(bests, best)
+	}
+
+	return bests, rows.Err()
+}
+
+// Агрегаты подходов по дням за период [from, to)
+func (s *SQLite) GetDailyActivity(ctx context.Context, userID int64, from, to time.Time) ([]model.DayActivity, error) {
+	query := `
+		SELECT
+			DATE(created_at)   AS day,
+			COUNT(*)           AS sets_count,
+			SUM(reps)          AS total_reps,
+			SUM(weight * reps) AS tonnage
+		FROM workout_sets
+		WHERE user_id = $1
+		  AND created_at >= $2
+		  AND created_at < $3
+		GROUP BY day
+		ORDER BY day ASC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, userID, sqliteTime(from), sqliteTime(to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var days []model.DayActivity
+	for rows.Next() {
+		var day model.DayActivity
+		var date sqliteTimeValue
+		if err := rows.Scan(&date, &day.Sets, &day.Reps, &day.Tonnage); err != nil {
+			return nil, err
+		}
+		day.Date = date.Time
+		days = append(days, day)
+	}
+
+	return days, rows.Err()
+}
+
+// Недельный объём в тяжёлых подходах по группам мышц начиная с from.
+// Неделя начинается с понедельника, как DATE_TRUNC('week', …) в Postgres.
+func (s *SQLite) GetWeeklyMuscleVolume(ctx context.Context, userID int64, from time.Time) ([]model.MuscleVolume, error) {
+	query := `
+		WITH sets AS (
+			SELECT
+				exercise_id,
+				created_at,
+				weight,
+				MAX(weight) OVER (PARTITION BY exercise_id, DATE(created_at)) AS day_max
+			FROM workout_sets
+			WHERE user_id = $1
+			  AND created_at >= $2
+		)
+		SELECT
+			DATE(s.created_at, 'weekday 0', '-6 days') AS week,
+			em.muscle,
+			SUM(CASE WHEN em.is_primary THEN 1.0 ELSE 0.5 END) AS hard_sets
+		FROM sets s
+		JOIN exercise_muscles em ON em.exercise_id = s.exercise_id
+		WHERE s.weight >= 0.5 * s.day_max
+		GROUP BY week, em.muscle
+		ORDER BY week ASC, em.muscle ASC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, userID, sqliteTime(from))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var volumes []model.MuscleVolume
+	for rows.Next() {
+		var volume model.MuscleVolume
+		var week sqliteTimeValue
+		var muscle string
+		if err := rows.Scan(&week, &muscle, &volume.Sets); err != nil {
+			return nil, err
+		}
+		volume.Week = week.Time
+		volume.Muscle = model.MuscleGroup(muscle)
+		volumes = append(volumes, volume)
+	}
+
+	return volumes, rows.Err()
+}
+
+// Блокировка пользователя администратором
+func (s *SQLite) SetUserBanned(ctx context.Context, userID int64, banned bool) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE users SET banned = $2 WHERE id = $1`, userID, banned)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res)
+}
+
+// Отказ от рассылок администратора
+func (s *SQLite) SetBroadcastOptOut(ctx context.Context, userID int64, optOut bool) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE users SET broadcast_opt_out = $2 WHERE id = $1`, userID, optOut)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res)
+}
+
+// Пользователи, записавшие хотя бы один подход начиная с since
+func (s *SQLite) CountActiveUsers(ctx context.Context, since time.Time) (int, error) {
+	var count int
+	query := `SELECT COUNT(DISTINCT user_id) FROM workout_sets WHERE created_at >= $1`
+	if err := s.db.QueryRowContext(ctx, query, sqliteTime(since)).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Агрегаты подходов всех пользователей по дням за период [from, to)
+func (s *SQLite) GetTotalDailyActivity(ctx context.Context, from, to time.Time) ([]model.DayActivity, error) {
+	query := `
+		SELECT
+			DATE(created_at)   AS day,
+			COUNT(*)           AS sets_count,
+			SUM(reps)          AS total_reps,
+			SUM(weight * reps) AS tonnage
+		FROM workout_sets
+		WHERE created_at >= $1
+		  AND created_at < $2
+		GROUP BY day
+		ORDER BY day ASC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, sqliteTime(from), sqliteTime(to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var days []model.DayActivity
+	for rows.Next() {
+		var day model.DayActivity
+		var date sqliteTimeValue
+		if err := rows.Scan(&date, &day.Sets, &day.Reps, &day.Tonnage); err != nil {
+			return nil, err
+		}
+		day.Date = date.Time
+		days = append(days, day)
+	}
+
+	return days, rows.Err()
+}
+
+// Новое стандартное упражнение с группами мышц
+func (s *SQLite) CreateStandardExercise(ctx context.Context, exercise model.Exercise) (created *model.Exercise, err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var createdAt sqliteTimeValue
+	query := `INSERT INTO exercises (name, description, is_standard, user_id) VALUES ($1, $2, TRUE, 0) RETURNING id, created_at`
+	if err = tx.QueryRowContext(ctx, query, exercise.Name, exercise.Description).Scan(&exercise.ID, &createdAt); err != nil {
+		return nil, fmt.Errorf("ошибка создания упражнения: %w", err)
+	}
+	if err = replaceMuscles(ctx, tx, exercise); err != nil {
+		return nil, fmt.Errorf("ошибка сохранения групп мышц: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	exercise.CreatedAt = createdAt.Time
+	exercise.IsStandard = true
+	exercise.UserID = 0
+	return &exercise, nil
+}
+
+// Название, описание и группы мышц стандартного упражнения
+func (s *SQLite) UpdateStandardExercise(ctx context.Context, exercise model.Exercise) (err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	query := `UPDATE exercises SET name = $2, description = $3 WHERE id = $1 AND is_standard`
+	res, err := tx.ExecContext(ctx, query, exercise.ID, exercise.Name, exercise.Description)
+	if err != nil {
+		return err
+	}
+	if err = expectAffected(res); err != nil {
+		return err
+	}
+	if err = replaceMuscles(ctx, tx, exercise); err != nil {
+		return fmt.Errorf("ошибка сохранения групп мышц: %w", err)
+	}
+	return tx.Commit()
+}
+
+// Удаляем стандартное упражнение, по которому нет подходов
+func (s *SQLite) DeleteStandardExercise(ctx context.Context, id int) (err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = deleteStandardExercise(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,227 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"gofitness/src/model"
+	"strings"
+	"time"
+)
+
+// UserRepository — пользователи Telegram
+type UserRepository interface {
+	// GetUserByChatID возвращает nil, nil, если пользователя нет
+	GetUserByChatID(ctx context.Context, chatID int64) (*model.User, error)
+	GetOrCreateUser(ctx context.Context, chatID int64, username string) (*model.User, error)
+	SaveUser(ctx context.Context, chatID int64, username string) (*model.User, error)
+	GetAllUsers(ctx context.Context) ([]model.User, error)
+	DeleteUser(ctx context.Context, userID int64) error
+	// SetUserLanguage сохраняет язык пользователя; пустая строка — по языку Telegram
+	SetUserLanguage(ctx context.Context, userID int64, language string) error
+	// SetUserBanned и SetBroadcastOptOut возвращают sql.ErrNoRows, если пользователя нет
+	SetUserBanned(ctx context.Context, userID int64, banned bool) error
+	SetBroadcastOptOut(ctx context.Context, userID int64, optOut bool) error
+}
+
+// ExerciseRepository — каталог упражнений: стандартные и личные упражнения пользователей
+type ExerciseRepository interface {
+	GetExercises(ctx context.Context, userID int64) ([]model.Exercise, error)
+	// GetExerciseByID и GetExerciseByName возвращают sql.ErrNoRows, если упражнения нет
+	GetExerciseByID(ctx context.Context, id int) (*model.Exercise, error)
+	GetExerciseByName(ctx context.Context, userID int64, name string) (*model.Exercise, error)
+	GetCustomExercises(ctx context.Context, userID int64) ([]model.Exercise, error)
+	CountCustomExercises(ctx context.Context, userID int64) (int, error)
+}
+
+// AdminRepository — статистика и каталог стандартных упражнений для администраторов
+type AdminRepository interface {
+	// CountActiveUsers — пользователи, записавшие хотя бы один подход начиная с since
+	CountActiveUsers(ctx context.Context, since time.Time) (int, error)
+	// GetTotalDailyActivity — агрегаты подходов всех пользователей по дням за [from, to)
+	GetTotalDailyActivity(ctx context.Context, from, to time.Time) ([]model.DayActivity, error)
+	CreateStandardExercise(ctx context.Context, exercise model.Exercise) (*model.Exercise, error)
+	// UpdateStandardExercise и DeleteStandardExercise возвращают sql.ErrNoRows,
+	// если стандартного упражнения нет; удалить упражнение с подходами
+	// нельзя — DeleteStandardExercise вернёт ErrExerciseInUse
+	UpdateStandardExercise(ctx context.Context, exercise model.Exercise) error
+	DeleteStandardExercise(ctx context.Context, id int) error
+}
+
+// ErrExerciseInUse — у упражнения есть подходы, удалять его нельзя
+var ErrExerciseInUse = errors.New("упражнение используется в подходах")
+
+// SetRepository — подходы и агрегаты по ним
+type SetRepository interface {
+	SaveWorkoutSet(ctx context.Context, userID int64, exerciseID int, weight float64, reps int) error
+	ImportWorkoutSets(ctx context.Context, userID int64, newExercises []string, sets []model.WorkoutSet) error
+	RestoreBackup(ctx context.Context, userID int64, exercises []model.Exercise, sets []model.WorkoutSet) (int, error)
+	GetUserWorkoutHistory(ctx context.Context, userID int64, limit int) ([]model.WorkoutSet, error)
+	// GetWorkoutSet, UpdateWorkoutSet и DeleteWorkoutSet работают только с подходами
+	// пользователя userID и возвращают sql.ErrNoRows, если подхода нет
+	GetWorkoutSet(ctx context.Context, userID int64, setID int) (*model.WorkoutSet, error)
+	CreateWorkoutSet(ctx context.Context, set model.WorkoutSet) (*model.WorkoutSet, error)
+	UpdateWorkoutSet(ctx context.Context, set model.WorkoutSet) error
+	DeleteWorkoutSet(ctx context.Context, userID int64, setID int) error
+	StreamWorkoutSets(ctx context.Context, userID int64, fn func(model.WorkoutSet) error) error
+	// GetWorkoutSetsBetween возвращает подходы за [from, to) в хронологическом порядке
+	GetWorkoutSetsBetween(ctx context.Context, userID int64, from, to time.Time) ([]model.WorkoutSet, error)
+	GetProgressByExercise(ctx context.Context, userID int64, exerciseID int, days int) ([]model.ProgressPoint, error)
+	GetWorkoutSummary(ctx context.Context, userID int64, from, to time.Time) (*model.WorkoutSummary, error)
+	GetExerciseBests(ctx context.Context, userID int64, from, to time.Time) ([]model.ExerciseBest, error)
+	GetDailyActivity(ctx context.Context, userID int64, from, to time.Time) ([]model.DayActivity, error)
+	GetWeeklyMuscleVolume(ctx context.Context, userID int64, from time.Time) ([]model.MuscleVolume, error)
+}
+
+// TokenRepository — персональные токены HTTP API (хранятся только хеши)
+type TokenRepository interface {
+	// ReplaceAPIToken отзывает прежние токены пользователя и сохраняет новый
+	ReplaceAPIToken(ctx context.Context, userID int64, tokenHash string) error
+	// GetUserByAPIToken возвращает nil, nil, если токен не найден
+	GetUserByAPIToken(ctx context.Context, tokenHash string) (*model.User, error)
+	DeleteAPITokens(ctx context.Context, userID int64) error
+}
+
+// ConversationRepository — незавершённые диалоги, сохранённые при остановке бота
+type ConversationRepository interface {
+	// SaveConversations заменяет сохранённые диалоги на states: chat_id → состояние в JSON
+	SaveConversations(ctx context.Context, states map[int64][]byte) error
+	// TakeConversations возвращает сохранённые диалоги и удаляет их
+	TakeConversations(ctx context.Context) (map[int64][]byte, error)
+}
+
+// Storage — хранилище, от которого зависят сервисы бота
+type Storage interface {
+	UserRepository
+	ExerciseRepository
+	SetRepository
+	TokenRepository
+	ConversationRepository
+	AdminRepository
+
+	// Init готовит хранилище к работе (схема, стандартные упражнения)
+	Init() error
+	// Ping проверяет, что хранилище доступно
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+var (
+	_ Storage  = (*Postgres)(nil)
+	_ Storage  = (*SQLite)(nil)
+	_ Storage  = (*Memory)(nil)
+	_ Migrator = (*Postgres)(nil)
+	_ Migrator = (*SQLite)(nil)
+)
+
+// Pool — настройки пула подключений к Postgres; SQLite всегда работает
+// через одно подключение
+type Pool struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// DefaultPool — пул по умолчанию
+var DefaultPool = Pool{MaxOpenConns: 25, MaxIdleConns: 25, ConnMaxLifetime: 5 * time.Minute}
+
+// Open выбирает хранилище по адресу: sqlite://файл.db — SQLite,
+// memory:// — данные в памяти процесса (для разработки и тестов),
+// любой другой адрес — строка подключения к Postgres с пулом pool
+func Open(url string, pool Pool) (Storage, error) {
+	switch {
+	case url == "":
+		return nil, fmt.Errorf("не задан DATABASE_URL")
+	case strings.HasPrefix(url, "memory:"):
+		return NewMemory(), nil
+	case strings.HasPrefix(url, "sqlite:"):
+		db, err := NewSQLite(url)
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+	default:
+		db, err := NewPostgres(url, pool)
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+	}
+}
+
+// Изменение одной записи: ни одной затронутой строки — sql.ErrNoRows
+func expectAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Заменяет группы мышц упражнения на те, что указаны в exercise
+func replaceMuscles(ctx context.Context, tx *sql.Tx, exercise model.Exercise) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM exercise_muscles WHERE exercise_id = $1`, exercise.ID); err != nil {
+		return err
+	}
+	query := `INSERT INTO exercise_muscles (exercise_id, muscle, is_primary) VALUES ($1, $2, $3)`
+	for _, muscle := range exercise.PrimaryMuscles {
+		if _, err := tx.ExecContext(ctx, query, exercise.ID, string(muscle), true); err != nil {
+			return err
+		}
+	}
+	for _, muscle := range exercise.SecondaryMuscles {
+		if _, err := tx.ExecContext(ctx, query, exercise.ID, string(muscle), false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Удаляет стандартное упражнение, если по нему нет подходов
+func deleteStandardExercise(ctx context.Context, tx *sql.Tx, id int) error {
+	var exists, used bool
+	err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM exercises WHERE id = $1 AND is_standard)`, id).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return sql.ErrNoRows
+	}
+
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM workout_sets WHERE exercise_id = $1)`, id).Scan(&used)
+	if err != nil {
+		return err
+	}
+	if used {
+		return ErrExerciseInUse
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM exercise_muscles WHERE exercise_id = $1`, id); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM exercises WHERE id = $1`, id)
+	return err
+}
+
+// Одинаковые подходы резервной копии: при восстановлении n-й повтор добавляется,
+// только если таких подходов в базе меньше n. Так повторное восстановление ничего
+// не дублирует, а несколько одинаковых подходов подряд не схлопываются в один.
+type restoreKey struct {
+	exerciseID int
+	weight     float64
+	reps       int
+	createdAt  int64
+}
+
+type restoreCounter map[restoreKey]int
+
+// next возвращает номер повтора подхода в копии, начиная с 1
+func (c restoreCounter) next(exerciseID int, set model.WorkoutSet) int {
+	key := restoreKey{exerciseID, set.Weight, set.Reps, set.CreatedAt.UnixNano()}
+	c[key]++
+	return c[key]
+}
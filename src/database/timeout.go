@@ -0,0 +1,325 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gofitness/src/model"
+	"time"
+)
+
+// ErrTimeout — хранилище не уложилось в отведённое на операцию время
+var ErrTimeout = errors.New("превышено время ожидания хранилища")
+
+// Ограничения времени на одну операцию с хранилищем
+const (
+	// Чтение и запись одной записи или агрегата
+	QueryTimeout = 5 * time.Second
+	// Импорт, восстановление, удаление аккаунта и выгрузка всей истории
+	BulkTimeout = 2 * time.Minute
+)
+
+// timeoutStorage ограничивает время каждой операции и превращает
+// истечение срока в ErrTimeout, чтобы обработчики могли попросить повторить
+type timeoutStorage struct {
+	Storage
+	query time.Duration
+	bulk  time.Duration
+}
+
+// WithTimeouts оборачивает хранилище дедлайнами на каждую операцию
+func WithTimeouts(storage Storage, query, bulk time.Duration) Storage {
+	return &timeoutStorage{Storage: storage, query: query, bulk: bulk}
+}
+
+// Выполняем op с дедлайном d; истечение именно этого дедлайна — ErrTimeout
+func withDeadline(ctx context.Context, d time.Duration, op func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+
+	err := op(ctx)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %v", ErrTimeout, err)
+	}
+	return err
+}
+
+func (s *timeoutStorage) Ping(ctx context.Context) error {
+	return withDeadline(ctx, s.query, s.Storage.Ping)
+}
+
+func (s *timeoutStorage) GetUserByChatID(ctx context.Context, chatID int64) (user *model.User, err error) {
+	err = withDeadline(ctx, s.query, func(ctx context.Context) error {
+		user, err = s.Storage.GetUserByChatID(ctx, chatID)
+		return err
+	})
+	return user, err
+}
+
+func (s *timeoutStorage) GetOrCreateUser(ctx context.Context, chatID int64, username string) (user *model.User, err error) {
+	err = withDeadline(ctx, s.query, func(ctx context.Context) error {
+		user, err = s.Storage.GetOrCreateUser(ctx, chatID, username)
+		return err
+	})
+	return user, err
+}
+
+func (s *timeoutStorage) SaveUser(ctx context.Context, chatID int64, username string) (user *model.User, err error) {
+	err = withDeadline(ctx, s.query, func(ctx context.Context) error {
+		user, err = s.Storage.SaveUser(ctx, chatID, username)
+		return err
+	})
+	return user, err
+}
+
+func (s *timeoutStorage) GetAllUsers(ctx context.Context) (users []model.User, err error) {
+	err = withDeadline(ctx, s.query, func(ctx context.Context) error {
+		users, err = s.Storage.GetAllUsers(ctx)
+		return err
+	})
+	return users, err
+}
+
+func (s *timeoutStorage) DeleteUser(ctx context.Context, userID int64) error {
+	return withDeadline(ctx, s.bulk, func(ctx context.Context) error {
+		return s.Storage.DeleteUser(ctx, userID)
+	})
+}
+
+func (s *timeoutStorage) SetUserLanguage(ctx context.Context, userID int64, language string) error {
+	return withDeadline(ctx, s.query, func(ctx context.Context) error {
+		return s.Storage.SetUserLanguage(ctx, userID, language)
+	})
+}
+
+func (s *timeoutStorage) GetExercises(ctx context.Context, userID int64) (exercises []model.Exercise, err error) {
+	err = withDeadline(ctx, s.query, func(ctx context.Context) error {
+		exercises, err = s.Storage.GetExercises(ctx, userID)
+		return err
+	})
+	return exercises, err
+}
+
+func (s *timeoutStorage) GetExerciseByID(ctx context.Context, id int) (exercise *model.Exercise, err error) {
+	err = withDeadline(ctx, s.query, func(ctx context.Context) error {
+		exercise, err = s.Storage.GetExerciseByID(ctx, id)
+		return err
+	})
+	return exercise, err
+}
+
+func (s *timeoutStorage) GetExerciseByName(ctx context.Context, userID int64, name string) (exercise *model.Exercise, err error) {
+	err = withDeadline(ctx, s.query, func(ctx context.Context) error {
+		exercise, err = s.Storage.GetExerciseByName(ctx, userID, name)
+		return err
+	})
+	return exercise, err
+}
+
+func (s *timeoutStorage) GetCustomExercises(ctx context.Context, userID int64) (exercises []model.Exercise, err error) {
+	err = withDeadline(ctx, s.query, func(ctx context.Context) error {
+		exercises, err = s.Storage.GetCustomExercises(ctx, userID)
+		return err
+	})
+	return exercises, err
+}
+
+func (s *timeoutStorage) CountCustomExercises(ctx context.Context, userID int64) (count int, err error) {
+	err = withDeadline(ctx, s.query, func(ctx context.Context) error {
+		count, err = s.Storage.CountCustomExercises(ctx, userID)
+		return err
+	})
+	return count, err
+}
+
+func (s *timeoutStorage) SaveWorkoutSet(ctx context.Context, userID int64, exerciseID int, weight float64, reps int) error {
+	return withDeadline(ctx, s.query, func(ctx context.Context) error {
+		return s.Storage.SaveWorkoutSet(ctx, userID, exerciseID, weight, reps)
+	})
+}
+
+func (s *timeoutStorage) ImportWorkoutSets(ctx context.Context, userID int64, newExercises []string, sets []model.WorkoutSet) error {
+	return withDeadline(ctx, s.bulk, func(ctx context.Context) error {
+		return s.Storage.ImportWorkoutSets(ctx, userID, newExercises, sets)
+	})
+}
+
+func (s *timeoutStorage) RestoreBackup(ctx context.Context, userID int64, exercises []model.Exercise, sets []model.WorkoutSet) (inserted int, err error) {
+	err = withDeadline(ctx, s.bulk, func(ctx context.Context) error {
+		inserted, err = s.Storage.RestoreBackup(ctx, userID, exercises, sets)
+		return err
+	})
+	return inserted, err
+}
+
+func (s *timeoutStorage) GetUserWorkoutHistory(ctx context.Context, userID int64, limit int) (sets []model.WorkoutSet, err error) {
+	err = withDeadline(ctx, s.query, func(ctx context.Context) error {
+		sets, err = s.Storage.GetUserWorkoutHistory(ctx, userID, limit)
+		return err
+	})
+	return sets, err
+}
+
+func (s *timeoutStorage) StreamWorkoutSets(ctx context.Context, userID int64, fn func(model.WorkoutSet) error) error {
+	return withDeadline(ctx, s.bulk, func(ctx context.Context) error {
+		return s.Storage.StreamWorkoutSets(ctx, userID, fn)
+	})
+}
+
+func (s *timeoutStorage) GetWorkoutSetsBetween(ctx context.Context, userID int64, from, to time.Time) (sets []model.WorkoutSet, err error) {
+	err = withDeadline(ctx, s.query, func(ctx context.Context) error {
+		sets, err = s.Storage.GetWorkoutSetsBetween(ctx, userID, from, to)
+		return err
+	})
+	return sets, err
+}
+
+func (s *timeoutStorage) GetProgressByExercise(ctx context.Context, userID int64, exerciseID int, days int) (points []model.ProgressPoint, err error) {
+	err = withDeadline(ctx, s.query, func(ctx context.Context) error {
+		points, err = s.Storage.GetProgressByExercise(ctx, userID, exerciseID, days)
+		return err
+	})
+	return points, err
+}
+
+func (s *timeoutStorage) GetWorkoutSummary(ctx context.Context, userID int64, from, to time.Time) (summary *model.WorkoutSummary, err error) {
+	err = withDeadline(ctx, s.query, func(ctx context.Context) error {
+		summary, err = s.Storage.GetWorkoutSummary(ctx, userID, from, to)
+		return err
+	})
+	return summary, err
+}
+
+func (s *timeoutStorage) GetExerciseBests(ctx context.Context, userID int64, from, to time.Time) (bests []model.ExerciseBest, err error) {
+	err = withDeadline(ctx, s.query, func(ctx context.Context) error {
+		bests, err = s.Storage.GetExerciseBests(ctx, userID, from, to)
+		return err
+	})
+	return bests, err
+}
+
+func (s *timeoutStorage) GetDailyActivity(ctx context.Context, userID int64, from, to time.Time) (days []model.DayActivity, err error) {
+	err = withDeadline(ctx, s.query, func(ctx context.Context) error {
+		days, err = s.Storage.GetDailyActivity(ctx, userID, from, to)
+		return err
+	})
+	return days, err
+}
+
+func (s *timeoutStorage) GetWeeklyMuscleVolume(ctx context.Context, userID int64, from time.Time) (volumes []model.MuscleVolume, err error) {
+	err = withDeadline(ctx, s.query, func(ctx context.Context) error {
+		volumes, err = s.Storage.GetWeeklyMuscleVolume(ctx, userID, from)
+		return err
+	})
+	return volumes, err
+}
+
+func (s *timeoutStorage) GetWorkoutSet(ctx context.Context, userID int64, setID int) (set *model.WorkoutSet, err error) {
+	err = withDeadline(ctx, s.query, func(ctx context.Context) error {
+		set, err = s.Storage.GetWorkoutSet(ctx, userID, setID)
+		return err
+	})
+	return set, err
+}
+
+func (s *timeoutStorage) CreateWorkoutSet(ctx context.Context, set model.WorkoutSet) (created *model.WorkoutSet, err error) {
+	err = withDeadline(ctx, s.query, func(ctx context.Context) error {
+		created, err = s.Storage.CreateWorkoutSet(ctx, set)
+		return err
+	})
+	return created, err
+}
+
+func (s *timeoutStorage) UpdateWorkoutSet(ctx context.Context, set model.WorkoutSet) error {
+	return withDeadline(ctx, s.query, func(ctx context.Context) error {
+		return s.Storage.UpdateWorkoutSet(ctx, set)
+	})
+}
+
+func (s *timeoutStorage) DeleteWorkoutSet(ctx context.Context, userID int64, setID int) error {
+	return withDeadline(ctx, s.query, func(ctx context.Context) error {
+		return s.Storage.DeleteWorkoutSet(ctx, userID, setID)
+	})
+}
+
+func (s *timeoutStorage) ReplaceAPIToken(ctx context.Context, userID int64, tokenHash string) error {
+	return withDeadline(ctx, s.query, func(ctx context.Context) error {
+		return s.Storage.ReplaceAPIToken(ctx, userID, tokenHash)
+	})
+}
+
+func (s *timeoutStorage) GetUserByAPIToken(ctx context.Context, tokenHash string) (user *model.User, err error) {
+	err = withDeadline(ctx, s.query, func(ctx context.Context) error {
+		user, err = s.Storage.GetUserByAPIToken(ctx, tokenHash)
+		return err
+	})
+	return user, err
+}
+
+func (s *timeoutStorage) DeleteAPITokens(ctx context.Context, userID int64) error {
+	return withDeadline(ctx, s.query, func(ctx context.Context) error {
+		return s.Storage.DeleteAPITokens(ctx, userID)
+	})
+}
+
+func (s *timeoutStorage) SaveConversations(ctx context.Context, states map[int64][]byte) error {
+	return withDeadline(ctx, s.bulk, func(ctx context.Context) error {
+		return s.Storage.SaveConversations(ctx, states)
+	})
+}
+
+func (s *timeoutStorage) TakeConversations(ctx context.Context) (states map[int64][]byte, err error) {
+	err = withDeadline(ctx, s.bulk, func(ctx context.Context) error {
+		states, err = s.Storage.TakeConversations(ctx)
+		return err
+	})
+	return states, err
+}
+
+func (s *timeoutStorage) SetUserBanned(ctx context.Context, userID int64, banned bool) error {
+	return withDeadline(ctx, s.query, func(ctx context.Context) error {
+		return s.Storage.SetUserBanned(ctx, userID, banned)
+	})
+}
+
+func (s *timeoutStorage) SetBroadcastOptOut(ctx context.Context, userID int64, optOut bool) error {
+	return withDeadline(ctx, s.query, func(ctx context.Context) error {
+		return s.Storage.SetBroadcastOptOut(ctx, userID, optOut)
+	})
+}
+
+func (s *timeoutStorage) CountActiveUsers(ctx context.Context, since time.Time) (count int, err error) {
+	err = withDeadline(ctx, s.query, func(ctx context.Context) error {
+		count, err = s.Storage.CountActiveUsers(ctx, since)
+		return err
+	})
+	return count, err
+}
+
+func (s *timeoutStorage) GetTotalDailyActivity(ctx context.Context, from, to time.Time) (days []model.DayActivity, err error) {
+	err = withDeadline(ctx, s.query, func(ctx context.Context) error {
+		days, err = s.Storage.GetTotalDailyActivity(ctx, from, to)
+		return err
+	})
+	return days, err
+}
+
+func (s *timeoutStorage) CreateStandardExercise(ctx context.Context, exercise model.Exercise) (created *model.Exercise, err error) {
+	err = withDeadline(ctx, s.query, func(ctx context.Context) error {
+		created, err = s.Storage.CreateStandardExercise(ctx, exercise)
+		return err
+	})
+	return created, err
+}
+
+func (s *timeoutStorage) UpdateStandardExercise(ctx context.Context, exercise model.Exercise) error {
+	return withDeadline(ctx, s.query, func(ctx context.Context) error {
+		return s.Storage.UpdateStandardExercise(ctx, exercise)
+	})
+}
+
+func (s *timeoutStorage) DeleteStandardExercise(ctx context.Context, id int) error {
+	return withDeadline(ctx, s.query, func(ctx context.Context) error {
+		return s.Storage.DeleteStandardExercise(ctx, id)
+	})
+}
@@ -0,0 +1,198 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"gofitness/src/config"
+	"gofitness/src/i18n"
+	"gofitness/src/service/admin"
+	"gofitness/src/state"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"gopkg.in/telebot.v3"
+)
+
+// Текст сообщения после первых n слов с сохранением переносов строк:
+// Payload в telebot обрывается на конце первой строки
+func textAfter(text string, n int) string {
+	for i := 0; i < n; i++ {
+		text = strings.TrimLeftFunc(text, unicode.IsSpace)
+		end := strings.IndexFunc(text, unicode.IsSpace)
+		if end < 0 {
+			return ""
+		}
+		text = text[end:]
+	}
+	return strings.TrimSpace(text)
+}
+
+// Команда /admin — консоль администратора: статистика, рассылка, пользователи
+// и каталог стандартных упражнений. Остальным бот не отвечает, будто команды нет
+func adminCommand(cfg *config.Config, adminService *admin.AdminService, broadcaster *admin.Broadcaster) telebot.HandlerFunc {
+	return func(c telebot.Context) error {
+		sender := c.Sender()
+		if !cfg.IsAdmin(sender.ID) {
+			slog.InfoContext(logContext(c), "Команда администратора от постороннего")
+			return nil
+		}
+		loc := locale(c)
+		if c.Chat().Type != telebot.ChatPrivate {
+			return c.Send(loc.T("admin.private"))
+		}
+
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		// Ошибки с текстом для администратора показываем, остальные — в лог
+		fail := func(err error, message string) error {
+			var localized *i18n.Error
+			if errors.As(err, &localized) {
+				return c.Send(loc.Error(err))
+			}
+			slog.ErrorContext(ctx, message, "err", err)
+			return sendError(c, err, "admin.error")
+		}
+
+		args := strings.Fields(c.Text())[1:]
+		if len(args) == 0 {
+			return c.Send(loc.T("admin.help"))
+		}
+
+		switch strings.ToLower(args[0]) {
+		case "stats":
+			text, err := adminService.Stats(ctx, loc, time.Now())
+			if err != nil {
+				return fail(err, "Ошибка статистики администратора")
+			}
+			return c.Send(text)
+
+		case "broadcast":
+			text := textAfter(c.Text(), 2)
+			if text == "" {
+				return c.Send(loc.T("admin.broadcast.usage"))
+			}
+			recipients, err := broadcaster.Recipients(ctx)
+			if err != nil {
+				return fail(err, "Ошибка подсчёта получателей рассылки")
+			}
+
+			getUserState(sender.ID).PendingBroadcast = text
+			menu := &telebot.ReplyMarkup{ResizeKeyboard: true, OneTimeKeyboard: true}
+			menu.Reply(menu.Row(menu.Text(loc.T("admin.broadcast.confirm")), menu.Text(loc.T("admin.broadcast.cancel"))))
+			return c.Send(loc.T("admin.broadcast.preview", recipients, text), menu)
+
+		case "user":
+			if len(args) < 2 || len(args) > 3 {
+				return c.Send(loc.T("admin.user.usage"))
+			}
+			chatID, err := strconv.ParseInt(args[1], 10, 64)
+			if err != nil {
+				return c.Send(loc.T("admin.user.usage"))
+			}
+			if len(args) == 2 {
+				text, err := adminService.InspectUser(ctx, loc, chatID)
+				if err != nil {
+					return fail(err, "Ошибка получения пользователя")
+				}
+				return c.Send(text)
+			}
+
+			switch strings.ToLower(args[2]) {
+			case "ban":
+				if cfg.IsAdmin(chatID) {
+					return c.Send(loc.T("admin.user.ban_admin"))
+				}
+				if err := adminService.SetBanned(ctx, chatID, true); err != nil {
+					return fail(err, "Ошибка блокировки пользователя")
+				}
+				slog.InfoContext(ctx, "Пользователь заблокирован", "target_id", chatID)
+				return c.Send(loc.T("admin.user.ban_done", chatID))
+			case "unban":
+				if err := adminService.SetBanned(ctx, chatID, false); err != nil {
+					return fail(err, "Ошибка разблокировки пользователя")
+				}
+				slog.InfoContext(ctx, "Пользователь разблокирован", "target_id", chatID)
+				return c.Send(loc.T("admin.user.unban_done", chatID))
+			default:
+				return c.Send(loc.T("admin.user.usage"))
+			}
+
+		case "exercises":
+			return adminExercises(ctx, c, loc, adminService, args[1:], fail)
+
+		default:
+			return c.Send(loc.T("admin.help"))
+		}
+	}
+}
+
+// /admin exercises [add …|edit <id> …|delete <id>]
+func adminExercises(ctx context.Context, c telebot.Context, loc i18n.Locale, adminService *admin.AdminService, args []string, fail func(error, string) error) error {
+	if len(args) == 0 {
+		text, err := adminService.StandardExercises(ctx, loc)
+		if err != nil {
+			return fail(err, "Ошибка получения каталога упражнений")
+		}
+		return c.Send(text)
+	}
+
+	action := strings.ToLower(args[0])
+	if action == "add" {
+		exercise, err := adminService.AddExercise(ctx, textAfter(c.Text(), 3))
+		if err != nil {
+			return fail(err, "Ошибка добавления упражнения")
+		}
+		slog.InfoContext(ctx, "Стандартное упражнение добавлено", "exercise_id", exercise.ID, "name", exercise.Name)
+		return c.Send(loc.T("admin.exercises.added", exercise.ID, exercise.Name))
+	}
+
+	if len(args) < 2 {
+		return c.Send(loc.Error(admin.ErrExerciseSpec))
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(args[1], "#"))
+	if err != nil {
+		return c.Send(loc.Error(admin.ErrExerciseSpec))
+	}
+
+	switch action {
+	case "edit":
+		exercise, err := adminService.EditExercise(ctx, id, textAfter(c.Text(), 4))
+		if err != nil {
+			return fail(err, "Ошибка изменения упражнения")
+		}
+		slog.InfoContext(ctx, "Стандартное упражнение изменено", "exercise_id", exercise.ID, "name", exercise.Name)
+		return c.Send(loc.T("admin.exercises.edited", exercise.ID, exercise.Name))
+	case "delete":
+		if err := adminService.DeleteExercise(ctx, id); err != nil {
+			return fail(err, "Ошибка удаления упражнения")
+		}
+		slog.InfoContext(ctx, "Стандартное упражнение удалено", "exercise_id", id)
+		return c.Send(loc.T("admin.exercises.deleted", id))
+	default:
+		return c.Send(loc.Error(admin.ErrExerciseSpec))
+	}
+}
+
+// Подтверждение рассылки: ставим её в очередь или отменяем
+func confirmBroadcast(c telebot.Context, loc i18n.Locale, states *state.UserState, broadcaster *admin.Broadcaster) error {
+	text := strings.TrimSpace(c.Text())
+	switch text {
+	case loc.T("admin.broadcast.confirm"):
+		broadcast := admin.Broadcast{Text: states.PendingBroadcast, AdminChatID: c.Sender().ID, AdminLocale: loc}
+		states.PendingBroadcast = ""
+		if err := broadcaster.Enqueue(broadcast); err != nil {
+			return c.Send(loc.Error(err), mainKeyboard(loc))
+		}
+		slog.InfoContext(logContext(c), "Рассылка поставлена в очередь")
+		return c.Send(loc.T("admin.broadcast.queued"), mainKeyboard(loc))
+	case loc.T("admin.broadcast.cancel"):
+		states.PendingBroadcast = ""
+		return c.Send(loc.T("admin.broadcast.cancelled"), mainKeyboard(loc))
+	default:
+		return c.Send(loc.T("admin.broadcast.pending", loc.T("admin.broadcast.confirm"), loc.T("admin.broadcast.cancel")))
+	}
+}
@@ -0,0 +1,144 @@
+package bot
+
+import (
+	"gofitness/src/config"
+	"gofitness/src/i18n"
+	"strings"
+	"testing"
+)
+
+const adminID = 1
+
+func newAdminHarness(t *testing.T) *harness {
+	return newHarness(t, func(cfg *config.Config) {
+		cfg.Admins = []int64{adminID}
+	})
+}
+
+func TestAdminIsInvisibleToOthers(t *testing.T) {
+	h := newAdminHarness(t)
+	u := h.user(100, "ru")
+
+	u.send("/admin").none()
+	u.send("/admin stats").none()
+	u.send("/admin user 100 ban").none()
+	if u.stored().Banned {
+		t.Fatal("не администратор заблокировал пользователя")
+	}
+
+	h.user(adminID, "ru").send("/start").has("/admin")
+}
+
+func TestAdminStatsAndUsers(t *testing.T) {
+	h := newAdminHarness(t)
+	admin := h.user(adminID, "ru")
+	u := h.user(100, "ru")
+	ru := i18n.RU
+
+	u.send("/add")
+	u.send("Жим лежа")
+	u.send("5")
+	u.send("100")
+
+	admin.send("/admin").is(ru, "admin.help")
+	admin.send("/admin stats").has("Пользователей: 2", "за сутки: 1")
+
+	admin.send("/admin user").is(ru, "admin.user.usage")
+	admin.send("/admin user 999").is(ru, "admin.user.not_found")
+	admin.send("/admin user 100").has("100", "Подходов: 1")
+	admin.send("/admin user 1 ban").is(ru, "admin.user.ban_admin")
+
+	// Заблокированному бот не отвечает и ничего не сохраняет
+	admin.send("/admin user 100 ban").is(ru, "admin.user.ban_done", 100)
+	u.send("/history").none()
+	u.send("/add").none()
+	admin.send("/admin stats").has("Заблокировано: 1")
+
+	admin.send("/admin user 100 unban").is(ru, "admin.user.unban_done", 100)
+	u.send("/history").has("Жим лежа")
+}
+
+func TestAdminExerciseCatalog(t *testing.T) {
+	h := newAdminHarness(t)
+	admin := h.user(adminID, "ru")
+	u := h.user(100, "ru")
+	ru := i18n.RU
+
+	admin.send("/admin exercises").has("#2 Жим лежа", "chest")
+	admin.send("/admin exercises add Выпады; С гантелями; квадрицепс, glutes; hamstrings").is(ru, "admin.exercises.added", 11, "Выпады")
+	admin.send("/admin exercises add Выпады").is(ru, "admin.exercises.exists")
+	admin.send("/admin exercises add Бёрпи; ; ноги").has("ноги")
+	admin.send("/admin exercises edit 11 Болгарские выпады; -").is(ru, "admin.exercises.edited", 11, "Болгарские выпады")
+	admin.send("/admin exercises edit 99 Что-то").is(ru, "admin.exercises.not_found")
+
+	// Новое упражнение сразу доступно пользователям
+	if !strings.Contains(u.send("/add").one().buttons(), "Болгарские выпады") {
+		t.Fatal("нового упражнения нет в клавиатуре")
+	}
+	u.send("Болгарские выпады")
+	u.send("12")
+	u.send("20")
+
+	admin.send("/admin exercises delete 11").is(ru, "admin.exercises.in_use")
+	admin.send("/admin exercises add Бёрпи").is(ru, "admin.exercises.added", 12, "Бёрпи")
+	admin.send("/admin exercises delete 12").is(ru, "admin.exercises.deleted", 12)
+	admin.send("/admin exercises delete 12").is(ru, "admin.exercises.not_found")
+}
+
+func TestAdminBroadcast(t *testing.T) {
+	h := newAdminHarness(t)
+	admin := h.user(adminID, "ru")
+	reader := h.user(100, "ru")
+	english := h.user(200, "ru")
+	optedOut := h.user(300, "ru")
+	ru := i18n.RU
+
+	reader.send("/start")
+	english.send("/settings language en")
+	optedOut.send("/settings broadcast off")
+
+	admin.send("/admin broadcast").is(ru, "admin.broadcast.usage")
+
+	text := "Привет!\nВышла новая версия бота"
+	preview := admin.send("/admin broadcast "+text).is(ru, "admin.broadcast.preview", 3, text)
+	expectButtons(t, preview, ru.T("admin.broadcast.confirm")+" | "+ru.T("admin.broadcast.cancel"))
+
+	admin.send("ок").is(ru, "admin.broadcast.pending", ru.T("admin.broadcast.confirm"), ru.T("admin.broadcast.cancel"))
+	admin.send(ru.T("admin.broadcast.cancel")).is(ru, "admin.broadcast.cancelled")
+
+	admin.send("/admin broadcast " + text)
+	admin.send(ru.T("admin.broadcast.confirm")).is(ru, "admin.broadcast.queued")
+
+	h.waitFor(adminID, ru.T("admin.broadcast.done", 3, 0, 0))
+	if got := h.waitFor(reader.id, text).Text; got != text+ru.T("broadcast.footer") {
+		t.Errorf("рассылка: %q", got)
+	}
+	if got := h.waitFor(english.id, text).Text; got != text+i18n.EN.T("broadcast.footer") {
+		t.Errorf("рассылка на английском: %q", got)
+	}
+
+	h.api.mu.Lock()
+	defer h.api.mu.Unlock()
+	for _, r := range h.api.replies {
+		if r.ChatID == optedOut.id && strings.Contains(r.Text, text) {
+			t.Fatal("рассылка пришла отказавшемуся от неё")
+		}
+	}
+}
+
+func TestPrivateCommandsInGroup(t *testing.T) {
+	h := newAdminHarness(t)
+	group := h.user(adminID, "ru").inGroup(-100)
+	ru := i18n.RU
+
+	group.send("/admin stats").is(ru, "admin.private")
+	group.send("/token").is(ru, "token.private")
+	group.send("/web").is(ru, "web.private")
+
+	// В группе в списке команд нет личных
+	start := group.send("/start").one()
+	if strings.Contains(start.Text, "/token") || strings.Contains(start.Text, "/admin") {
+		t.Errorf("в группе показаны личные команды:\n%s", start.Text)
+	}
+	group.send("/history").is(ru, "history.empty")
+}
@@ -0,0 +1,124 @@
+package bot
+
+import (
+	"fmt"
+	"gofitness/src/config"
+	"gofitness/src/i18n"
+	"log/slog"
+	"strings"
+
+	"gopkg.in/telebot.v3"
+)
+
+// Команда из меню бота; описание — сообщение «command.<Name>» каталога i18n
+type botCommand struct {
+	Name string
+	// Команда работает только в личном чате с ботом
+	PrivateOnly bool
+	// Команда видна только администраторам из настроек
+	AdminOnly bool
+	// Команда отключается настройками; nil — доступна всегда
+	Enabled func(cfg *config.Config) bool
+}
+
+// Команды в порядке меню. /start в меню не показываем: Telegram сам
+// предлагает его новым пользователям
+var botCommands = []botCommand{
+	{Name: "add"},
+	{Name: "finish"},
+	{Name: "history"},
+	{Name: "exercises"},
+	{Name: "stats"},
+	{Name: "volume"},
+	{Name: "compare"},
+	{Name: "calendar"},
+	{Name: "report"},
+	{Name: "export"},
+	{Name: "backup"},
+	{Name: "restore"},
+	{Name: "web", PrivateOnly: true, Enabled: func(cfg *config.Config) bool { return cfg.Features.Web }},
+	{Name: "token", PrivateOnly: true, Enabled: func(cfg *config.Config) bool { return cfg.Features.API }},
+	{Name: "settings"},
+	{Name: "mydata"},
+	{Name: "deleteme"},
+	{Name: "admin", PrivateOnly: true, AdminOnly: true},
+}
+
+// Команды бота; остальное попадает в метрики как «other», чтобы не плодить метки
+var knownCommands = func() map[string]bool {
+	known := map[string]bool{"/start": true}
+	for _, cmd := range botCommands {
+		known["/"+cmd.Name] = true
+	}
+	return known
+}()
+
+// Команды меню для языка loc; в группах — без команд личного чата,
+// команды администратора — только для admin
+func menuCommands(loc i18n.Locale, cfg *config.Config, private, admin bool) []telebot.Command {
+	var commands []telebot.Command
+	for _, cmd := range botCommands {
+		if cmd.PrivateOnly && !private || cmd.AdminOnly && !admin {
+			continue
+		}
+		if cmd.Enabled != nil && !cmd.Enabled(cfg) {
+			continue
+		}
+		commands = append(commands, telebot.Command{
+			Text:        cmd.Name,
+			Description: loc.T("command." + cmd.Name),
+		})
+	}
+	return commands
+}
+
+// Список команд для приветствия /start
+func commandList(loc i18n.Locale, cfg *config.Config, private, admin bool) string {
+	var list strings.Builder
+	for i, cmd := range menuCommands(loc, cfg, private, admin) {
+		if i > 0 {
+			list.WriteString("\n")
+		}
+		list.WriteString(fmt.Sprintf("/%s - %s", cmd.Text, cmd.Description))
+	}
+	return list.String()
+}
+
+// RegisterCommands публикует меню команд в Telegram: для личных чатов и групп
+// на каждом поддерживаемом языке, а для остальных языков — на языке,
+// который для них выбирает i18n.Detect. Администраторам меню дополняется
+// командой /admin в их личных чатах
+func RegisterCommands(b *telebot.Bot, cfg *config.Config) error {
+	scopes := []telebot.CommandScope{
+		{Type: telebot.CommandScopeAllPrivateChats},
+		{Type: telebot.CommandScopeAllGroupChats},
+	}
+	for _, scope := range scopes {
+		private := scope.Type == telebot.CommandScopeAllPrivateChats
+		if err := setCommands(b, cfg, scope, private, false); err != nil {
+			return err
+		}
+	}
+
+	for _, chatID := range cfg.Admins {
+		scope := telebot.CommandScope{Type: telebot.CommandScopeChat, ChatID: chatID}
+		if err := setCommands(b, cfg, scope, true, true); err != nil {
+			// Администратор мог ещё не писать боту: Telegram не знает такого чата
+			slog.Warn("Не удалось зарегистрировать меню администратора", "admin_id", chatID, "err", err)
+		}
+	}
+	return nil
+}
+
+// Меню команд области scope на всех языках и запасное для остальных
+func setCommands(b *telebot.Bot, cfg *config.Config, scope telebot.CommandScope, private, admin bool) error {
+	for _, loc := range i18n.Locales {
+		if err := b.SetCommands(menuCommands(loc, cfg, private, admin), string(loc), scope); err != nil {
+			return fmt.Errorf("меню команд %s (%s): %w", scope.Type, loc, err)
+		}
+	}
+	if err := b.SetCommands(menuCommands(i18n.Foreign, cfg, private, admin), scope); err != nil {
+		return fmt.Errorf("меню команд %s: %w", scope.Type, err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"gofitness/src/database"
+	"gofitness/src/state"
+	"log/slog"
+)
+
+// SaveConversations сохраняет незавершённые диалоги (ввод подхода, подтверждения,
+// ожидание файла), чтобы после перезапуска пользователь продолжил с того же места.
+// Вызывается при остановке, когда обработчики уже завершились
+func SaveConversations(ctx context.Context, db database.ConversationRepository) (int, error) {
+	userStatesMu.Lock()
+	states := make(map[int64][]byte)
+	for userID, s := range userStates {
+		if !s.Active() {
+			continue
+		}
+		data, err := json.Marshal(s)
+		if err != nil {
+			userStatesMu.Unlock()
+			return 0, fmt.Errorf("ошибка сохранения диалога: %w", err)
+		}
+		states[userID] = data
+	}
+	userStatesMu.Unlock()
+
+	if err := db.SaveConversations(ctx, states); err != nil {
+		return 0, fmt.Errorf("ошибка сохранения диалогов: %w", err)
+	}
+	return len(states), nil
+}
+
+// RestoreConversations загружает диалоги, сохранённые при прошлой остановке.
+// Сохранённые диалоги удаляются из хранилища сразу после чтения
+func RestoreConversations(ctx context.Context, db database.ConversationRepository) (int, error) {
+	saved, err := db.TakeConversations(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка загрузки диалогов: %w", err)
+	}
+
+	userStatesMu.Lock()
+	defer userStatesMu.Unlock()
+
+	restored := 0
+	for userID, data := range saved {
+		s := &state.UserState{}
+		if err := json.Unmarshal(data, s); err != nil {
+			slog.WarnContext(ctx, "Пропущен повреждённый диалог", "user_id", userID, "err", err)
+			continue
+		}
+		userStates[userID] = s
+		restored++
+	}
+	return restored, nil
+}
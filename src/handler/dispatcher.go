@@ -0,0 +1,90 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"gopkg.in/telebot.v3"
+)
+
+// Dispatcher получает обновления от poller и обрабатывает каждое в своей
+// горутине, как это делает telebot, но считает начатые обработки, чтобы
+// при остановке дождаться их. Боту нужен Synchronous: true, иначе telebot
+// запустит обработчики в собственных горутинах мимо счётчика
+type Dispatcher struct {
+	poller telebot.Poller
+
+	mu     sync.Mutex
+	active int
+	idle   chan struct{}
+}
+
+func NewDispatcher(poller telebot.Poller) *Dispatcher {
+	return &Dispatcher{poller: poller}
+}
+
+// Poll реализует telebot.Poller. Возвращается, когда остановлен исходный poller:
+// после этого новые обработки не начинаются
+func (d *Dispatcher) Poll(b *telebot.Bot, _ chan telebot.Update, stop chan struct{}) {
+	updates := make(chan telebot.Update)
+	done := make(chan struct{})
+	go func() {
+		d.poller.Poll(b, updates, stop)
+		close(done)
+	}()
+
+	for {
+		select {
+		case update := <-updates:
+			d.begin()
+			go func() {
+				defer d.end()
+				b.ProcessUpdate(update)
+			}()
+		case <-done:
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) begin() {
+	d.mu.Lock()
+	d.active++
+	d.mu.Unlock()
+}
+
+func (d *Dispatcher) end() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.active--
+	if d.active == 0 && d.idle != nil {
+		close(d.idle)
+		d.idle = nil
+	}
+}
+
+// Wait ждёт завершения начатых обработок, но не дольше, чем живёт ctx
+func (d *Dispatcher) Wait(ctx context.Context) error {
+	d.mu.Lock()
+	if d.active == 0 {
+		d.mu.Unlock()
+		return nil
+	}
+	if d.idle == nil {
+		d.idle = make(chan struct{})
+	}
+	idle := d.idle
+	d.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		d.mu.Lock()
+		active := d.active
+		d.mu.Unlock()
+		return fmt.Errorf("не завершено обработок: %d: %w", active, ctx.Err())
+	}
+}
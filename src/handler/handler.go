@@ -1,112 +1,661 @@
 package bot
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"gofitness/src/config"
 	"gofitness/src/database"
-	"gofitness/src/helper"
+	"gofitness/src/i18n"
+	"gofitness/src/service/admin"
+	"gofitness/src/service/backup"
 	"gofitness/src/service/exercise"
+	"gofitness/src/service/export"
 	"gofitness/src/service/history"
+	"gofitness/src/service/importer"
+	"gofitness/src/service/report"
+	"gofitness/src/service/session"
+	"gofitness/src/service/token"
+	userservice "gofitness/src/service/user"
 	"gofitness/src/state"
-	"log"
-	"os"
+	"io"
+	"log/slog"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"gopkg.in/telebot.v3"
 )
 
 // Состояние пользователя для ввода подхода
-var userStates = make(map[int64]*state.UserState)
+var (
+	userStatesMu sync.Mutex
+	userStates   = make(map[int64]*state.UserState)
+)
+
+// Состояние пользователя, создаётся при первом обращении
+func getUserState(userID int64) *state.UserState {
+	userStatesMu.Lock()
+	defer userStatesMu.Unlock()
+
+	states, exists := userStates[userID]
+	if !exists {
+		states = &state.UserState{}
+		userStates[userID] = states
+	}
+	return states
+}
+
+func deleteUserState(userID int64) {
+	userStatesMu.Lock()
+	delete(userStates, userID)
+	userStatesMu.Unlock()
+}
+
+// Количество пользователей посреди диалога
+func activeUserStates() int {
+	userStatesMu.Lock()
+	defer userStatesMu.Unlock()
+
+	count := 0
+	for _, s := range userStates {
+		if s.Active() {
+			count++
+		}
+	}
+	return count
+}
+
+// Убирает клавиатуру после завершения диалога
+var removeKeyboard = &telebot.ReplyMarkup{RemoveKeyboard: true}
+
+// Максимальное время обработки одного сообщения; отдельные запросы
+// к хранилищу ограничены своими, более короткими дедлайнами
+const requestTimeout = 3 * time.Minute
 
-func SetupHandlers(b *telebot.Bot, db *database.Postgres) {
+// Контекст обработки одного сообщения, несёт атрибуты логирования обновления
+func requestContext(c telebot.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(logContext(c), requestTimeout)
+}
+
+// Ответ при ошибке: если хранилище не ответило вовремя, просим повторить,
+// иначе отправляем сообщение key на языке пользователя
+func sendError(c telebot.Context, err error, key string, opts ...interface{}) error {
+	if errors.Is(err, database.ErrTimeout) || errors.Is(err, context.DeadlineExceeded) {
+		key = "error.timeout"
+	}
+	return c.Send(locale(c).T(key), opts...)
+}
+
+func SetupHandlers(b *telebot.Bot, db database.Storage, sessions *session.SessionService, broadcaster *admin.Broadcaster, cfg *config.Config) {
 	// Команда /start
 	// Инициализируем сервисы
 	exerciseService := exercise.NewExerciseService(db)
 	historyService := history.NewHistoryService(db)
-	log.Printf("Start handler")
-	// сохраняем пользователя и отдаем команды
+	reportService := report.NewReportService(db)
+	exportService := export.NewExportService(db)
+	importerService := importer.NewImporterService(db)
+	backupService := backup.NewBackupService(db)
+	userService := userservice.NewUserService(db)
+	tokenService := token.NewTokenService(db)
+	adminService := admin.NewAdminService(db)
+	b.Use(loggingMiddleware, metricsMiddleware, rateLimitMiddleware(cfg.Limits), userMiddleware(userService))
+	// пользователь уже сохранён userMiddleware, отдаем команды
 	b.Handle("/start", func(c telebot.Context) error {
-		user := c.Sender()
-		return c.Send(historyService.HandlerStart(user.ID, helper.GetUserName(user)))
+		loc := locale(c)
+		commands := commandList(loc, cfg, c.Chat().Type == telebot.ChatPrivate, cfg.IsAdmin(c.Sender().ID))
+		return c.Send(historyService.HandlerStart(loc, commands), mainKeyboard(loc))
 	})
 
 	// Команда /add - начать добавление подхода
-	b.Handle("/add", func(c telebot.Context) error {
-		var menu, err = exerciseService.ShowExerciseSelection(c)
+	addSet := func(c telebot.Context) error {
+		ctx, cancel := requestContext(c)
+		defer cancel()
 
-		if err == nil {
-			c.Send(err)
+		loc := locale(c)
+		var menu, err = exerciseService.ShowExerciseSelection(ctx, loc, currentUser(c))
+		if err != nil {
+			slog.ErrorContext(ctx, "Ошибка получения списка упражнений", "err", err)
+			return sendError(c, err, "add.error")
 		}
 
-		return c.Send("Выбери упражнение:", menu)
-	})
+		return c.Send(loc.T("add.choose"), menu)
+	}
+	b.Handle("/add", addSet)
+
+	// Команда /finish - закончить тренировку: сбросить незаконченный ввод и подвести итог дня
+	finishWorkout := func(c telebot.Context) error {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		loc := locale(c)
+		deleteUserState(c.Sender().ID)
+
+		message, err := historyService.FinishWorkout(ctx, loc, currentUser(c))
+		if err != nil {
+			slog.ErrorContext(ctx, "Ошибка подведения итогов тренировки", "err", err)
+			return sendError(c, err, "finish.error", mainKeyboard(loc))
+		}
+		return c.Send(message, mainKeyboard(loc))
+	}
+	b.Handle("/finish", finishWorkout)
 
 	// Команда /exercises - список упражнений
 	b.Handle("/exercises", func(c telebot.Context) error {
-		var message, err = exerciseService.GetExercises()
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		var message, err = exerciseService.GetExercises(ctx, locale(c), currentUser(c))
 		if err != nil {
-			return c.Send(err)
+			slog.ErrorContext(ctx, "Ошибка получения упражнений", "err", err)
+			return sendError(c, err, "exercises.error")
 		}
 		return c.Send(message)
 	})
 
 	// Команда /history - история тренировок
-	b.Handle("/history", func(c telebot.Context) error {
-		user := c.Sender()
-		username := helper.GetUserName(user)
-		var message, _ = historyService.GetHistory(user.ID, username, 10)
+	showHistory := func(c telebot.Context) error {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		var message, err = historyService.GetHistory(ctx, locale(c), currentUser(c), 10)
+		if err != nil {
+			slog.ErrorContext(ctx, "Ошибка получения истории", "err", err)
+			return sendError(c, err, "history.error")
+		}
 		return c.Send(message)
-	})
+	}
+	b.Handle("/history", showHistory)
 
 	// Команда /stats - статистика
-	b.Handle("/stats", func(c telebot.Context) error {
-		user := c.Sender()
-		username := helper.GetUserName(user)
-
+	showStats := func(c telebot.Context) error {
+		ctx, cancel := requestContext(c)
+		defer cancel()
 
-		var buf, err = historyService.GetUserWorkoutHistory(user.ID, username, 100)
+		loc := locale(c)
+		var buf, err = historyService.GetUserWorkoutHistory(ctx, loc, currentUser(c), 100)
 
-		if err != nil { 
-			c.Send(err)
+		if errors.Is(err, history.ErrNoStats) {
+			return c.Send(loc.Error(err))
 		}
-
-		if buf != nil && buf.Len() > 0 {
-			err := os.WriteFile("debug_chart.png", buf.Bytes(), 0644)
-			if err != nil {
-				log.Printf("Ошибка сохранения debug_chart.png: %v", err)
-			} else {
-				log.Println("График сохранён в debug_chart.png")
-			}
+		if err != nil {
+			slog.ErrorContext(ctx, "Ошибка построения статистики", "err", err)
+			return sendError(c, err, "stats.error")
 		}
 
 		photo := &telebot.Photo{
 			File:    telebot.FromReader(buf),
-			Caption: "Прогресс по жиму лёжа за 90 дней\nСиняя — вес, оранжевая — повторения",
+			Caption: loc.T("stats.caption"),
 		}
 		return c.Send(photo)
 		
 		// return c.Send(message)
+	}
+	b.Handle("/stats", showStats)
+
+	// Кнопки главной клавиатуры работают так же, как их команды
+	buttonHandlers := map[string]telebot.HandlerFunc{
+		"/add":     addSet,
+		"/history": showHistory,
+		"/stats":   showStats,
+		"/finish":  finishWorkout,
+	}
+	for text, command := range buttonCommands {
+		b.Handle(text, buttonHandlers[command])
+	}
+
+	// Команда /volume [недель] - тяжёлые подходы по группам мышц
+	b.Handle("/volume", func(c telebot.Context) error {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		loc := locale(c)
+		weeks := 4
+		if args := c.Args(); len(args) > 0 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 || n > 26 {
+				return c.Send(loc.T("volume.usage"))
+			}
+			weeks = n
+		}
+
+		message, buf, err := historyService.GetMuscleVolume(ctx, loc, currentUser(c), weeks)
+		if err != nil {
+			slog.ErrorContext(ctx, "Ошибка подсчёта объёма", "err", err)
+			return sendError(c, err, "volume.error")
+		}
+
+		if buf == nil {
+			return c.Send(message)
+		}
+		return c.Send(&telebot.Photo{File: telebot.FromReader(buf), Caption: message})
+	})
+
+	// Команда /compare Жим лежа, Приседания 180d [pct|e1rm] - сравнение упражнений
+	b.Handle("/compare", func(c telebot.Context) error {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		loc := locale(c)
+		names, days, mode, err := history.ParseCompareArgs(c.Message().Payload)
+		if err != nil {
+			return c.Send(loc.T("compare.usage", loc.Error(err)))
+		}
+
+		buf, caption, err := historyService.CompareExercises(ctx, loc, currentUser(c), names, days, mode)
+		if err != nil {
+			slog.ErrorContext(ctx, "Ошибка сравнения упражнений", "err", err)
+			return sendError(c, err, "compare.error")
+		}
+
+		if buf == nil {
+			return c.Send(caption)
+		}
+		return c.Send(&telebot.Photo{File: telebot.FromReader(buf), Caption: caption})
+	})
+
+	// Команда /calendar [год] [sets] - тепловая карта тренировок
+	b.Handle("/calendar", func(c telebot.Context) error {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		loc := locale(c)
+		year := time.Now().Year()
+		bySets := false
+		for _, arg := range c.Args() {
+			if y, err := strconv.Atoi(arg); err == nil && y > 1900 && y <= year {
+				year = y
+				continue
+			}
+			switch strings.ToLower(arg) {
+			case "sets", "подходы":
+				bySets = true
+			default:
+				return c.Send(loc.T("calendar.usage"))
+			}
+		}
+
+		buf, caption, err := historyService.GetCalendar(ctx, loc, currentUser(c), year, bySets)
+		if err != nil {
+			slog.ErrorContext(ctx, "Ошибка построения календаря", "err", err)
+			return sendError(c, err, "calendar.error")
+		}
+
+		return c.Send(&telebot.Photo{File: telebot.FromReader(buf), Caption: caption})
+	})
+
+	// Команда /report week|month|year - сводный отчёт за период
+	b.Handle("/report", func(c telebot.Context) error {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		loc := locale(c)
+		period, ok := report.ParsePeriod(c.Message().Payload)
+		if !ok {
+			return c.Send(loc.T("report.usage"))
+		}
+
+		message, buf, err := reportService.GetReport(ctx, loc, currentUser(c), period)
+		if err != nil {
+			slog.ErrorContext(ctx, "Ошибка построения отчёта", "err", err)
+			return sendError(c, err, "report.error")
+		}
+
+		if err := c.Send(message); err != nil {
+			return err
+		}
+		if buf != nil {
+			return c.Send(&telebot.Photo{File: telebot.FromReader(buf)})
+		}
+		return nil
+	})
+
+	// Команда /export csv - выгрузка всех подходов в CSV
+	b.Handle("/export", func(c telebot.Context) error {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		loc := locale(c)
+		if format := strings.ToLower(strings.TrimSpace(c.Message().Payload)); format != "csv" && format != "" {
+			return c.Send(loc.T("export.only_csv"))
+		}
+
+		user := currentUser(c)
+
+		// Подходы пишутся в файл по мере чтения из базы
+		reader, writer := io.Pipe()
+		defer reader.Close()
+		go func() {
+			_, err := exportService.WriteCSV(ctx, user, writer)
+			if err != nil {
+				slog.ErrorContext(ctx, "Ошибка экспорта CSV", "err", err)
+			}
+			writer.CloseWithError(err)
+		}()
+
+		doc := &telebot.Document{
+			File:     telebot.FromReader(reader),
+			FileName: fmt.Sprintf("gofitness-%s.csv", time.Now().Format("2006-01-02")),
+			MIME:     "text/csv",
+			Caption:  loc.T("export.caption", strings.Join(export.CSVHeader, ", ")),
+		}
+		if err := c.Send(doc); err != nil {
+			slog.ErrorContext(ctx, "Ошибка отправки экспорта", "err", err)
+			return sendError(c, err, "export.error")
+		}
+		return nil
+	})
+
+	// Команда /mydata - какие данные о пользователе хранятся
+	b.Handle("/mydata", func(c telebot.Context) error {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		message, err := userService.GetMyData(ctx, locale(c), currentUser(c))
+		if err != nil {
+			slog.ErrorContext(ctx, "Ошибка получения данных пользователя", "err", err)
+			return sendError(c, err, "mydata.error")
+		}
+		return c.Send(message)
+	})
+
+	// Команда /settings - настройки, /settings language ru|en|auto - язык бота,
+	// /settings broadcast on|off - рассылки администратора
+	b.Handle("/settings", func(c telebot.Context) error {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		loc := locale(c)
+		user := currentUser(c)
+		args := c.Args()
+		if len(args) == 0 {
+			settings := userService.GetSettings(user)
+			auto := ""
+			if settings.Language == "" {
+				auto = loc.T("settings.auto")
+			}
+			broadcasts := loc.T("settings.broadcast.on")
+			if settings.BroadcastOptOut {
+				broadcasts = loc.T("settings.broadcast.off")
+			}
+			return c.Send(loc.T("settings.current", loc.Name(), auto, broadcasts))
+		}
+		if len(args) != 2 {
+			return c.Send(loc.T("settings.usage"))
+		}
+
+		switch strings.ToLower(args[0]) {
+		case "language", "lang", "язык":
+		case "broadcast", "news", "рассылка", "рассылки":
+			var optOut bool
+			switch strings.ToLower(args[1]) {
+			case "on", "вкл":
+			case "off", "выкл":
+				optOut = true
+			default:
+				return c.Send(loc.T("settings.usage"))
+			}
+			if err := userService.SetBroadcastOptOut(ctx, user, optOut); err != nil {
+				slog.ErrorContext(ctx, "Ошибка сохранения настройки рассылок", "err", err)
+				return sendError(c, err, "settings.error")
+			}
+			if optOut {
+				return c.Send(loc.T("settings.broadcast.saved_off"))
+			}
+			return c.Send(loc.T("settings.broadcast.saved_on"))
+		default:
+			return c.Send(loc.T("settings.usage"))
+		}
+
+		var language string
+		if code := strings.ToLower(args[1]); code != "auto" && code != "авто" {
+			selected, ok := i18n.Parse(code)
+			if !ok {
+				return c.Send(loc.T("settings.usage"))
+			}
+			language = string(selected)
+		}
+
+		if err := userService.SetLanguage(ctx, user, language); err != nil {
+			slog.ErrorContext(ctx, "Ошибка сохранения языка", "err", err)
+			return sendError(c, err, "settings.error")
+		}
+
+		// Отвечаем уже на выбранном языке
+		if language == "" {
+			loc = i18n.Detect(c.Sender().LanguageCode)
+			return c.Send(loc.T("settings.auto_saved", loc.Name()))
+		}
+		loc = i18n.Locale(language)
+		return c.Send(loc.T("settings.saved", loc.Name()))
+	})
+
+	// Команда /admin - консоль администратора
+	b.Handle("/admin", adminCommand(cfg, adminService, broadcaster))
+
+	// Команда /deleteme - удаление аккаунта и всех данных после подтверждения
+	b.Handle("/deleteme", func(c telebot.Context) error {
+		getUserState(c.Sender().ID).WaitingForDeleteConfirm = true
+
+		loc := locale(c)
+		menu := &telebot.ReplyMarkup{ResizeKeyboard: true, OneTimeKeyboard: true}
+		menu.Reply(menu.Row(menu.Text(loc.T("delete.confirm")), menu.Text(loc.T("delete.cancel"))))
+		return c.Send(loc.T("delete.prompt"), menu)
+	})
+
+	// Команда /token - персональный токен HTTP API, /token revoke - отозвать
+	b.Handle("/token", func(c telebot.Context) error {
+		loc := locale(c)
+		if c.Chat().Type != telebot.ChatPrivate {
+			return c.Send(loc.T("token.private"))
+		}
+		if !cfg.Features.API {
+			return c.Send(loc.T("token.disabled"))
+		}
+
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		user := currentUser(c)
+		if strings.EqualFold(strings.TrimSpace(c.Message().Payload), "revoke") {
+			if err := tokenService.Revoke(ctx, user); err != nil {
+				slog.ErrorContext(ctx, "Ошибка отзыва токена", "err", err)
+				return sendError(c, err, "token.revoke_error")
+			}
+			return c.Send(loc.T("token.revoked"))
+		}
+
+		apiToken, err := tokenService.Issue(ctx, user)
+		if err != nil {
+			slog.ErrorContext(ctx, "Ошибка выпуска токена", "err", err)
+			return sendError(c, err, "token.issue_error")
+		}
+		return c.Send(loc.T("token.issued", apiToken), telebot.ModeHTML)
+	})
+
+	// Команда /web - одноразовая ссылка входа в веб-кабинет
+	b.Handle("/web", func(c telebot.Context) error {
+		loc := locale(c)
+		if c.Chat().Type != telebot.ChatPrivate {
+			return c.Send(loc.T("web.private"))
+		}
+		if !cfg.Features.Web {
+			return c.Send(loc.T("web.disabled"))
+		}
+
+		link, err := sessions.LoginURL(currentUser(c))
+		if err != nil {
+			slog.ErrorContext(logContext(c), "Ошибка создания ссылки входа", "err", err)
+			return sendError(c, err, "web.error")
+		}
+		if link == "" {
+			return c.Send(loc.T("web.not_configured"))
+		}
+		return c.Send(loc.T("web.link", link), telebot.NoPreview)
+	})
+
+	// Команда /backup - резервная копия всех данных в JSON
+	b.Handle("/backup", func(c telebot.Context) error {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		buf, err := backupService.Create(ctx, currentUser(c))
+		if err != nil {
+			slog.ErrorContext(ctx, "Ошибка создания резервной копии", "err", err)
+			return sendError(c, err, "backup.error")
+		}
+
+		return c.Send(&telebot.Document{
+			File:     telebot.FromReader(buf),
+			FileName: fmt.Sprintf("gofitness-backup-%s.json", time.Now().Format("2006-01-02")),
+			MIME:     "application/json",
+			Caption:  locale(c).T("backup.caption"),
+		})
+	})
+
+	// Команда /restore - следующий JSON-файл будет восстановлен из резервной копии
+	b.Handle("/restore", func(c telebot.Context) error {
+		getUserState(c.Sender().ID).WaitingForRestore = true
+		return c.Send(locale(c).T("restore.prompt"))
+	})
+
+	// Документ - восстановление резервной копии или импорт CSV
+	b.Handle(telebot.OnDocument, func(c telebot.Context) error {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		loc := locale(c)
+		doc := c.Message().Document
+		user := currentUser(c)
+		states := getUserState(user.ChatID)
+
+		if states.WaitingForRestore || strings.HasPrefix(c.Message().Caption, "/restore") {
+			states.WaitingForRestore = false
+			if doc.FileSize > backup.MaxFileSize {
+				return c.Send(loc.T("restore.too_large"))
+			}
+
+			file, err := b.File(&doc.File)
+			if err != nil {
+				slog.ErrorContext(ctx, "Ошибка загрузки файла", "err", err)
+				return c.Send(loc.T("error.file_download"))
+			}
+			defer file.Close()
+
+			replyText, err := backupService.Restore(ctx, loc, user, file)
+			if err != nil {
+				slog.ErrorContext(ctx, "Ошибка восстановления", "err", err)
+				return sendError(c, err, "restore.error")
+			}
+			return c.Send(replyText)
+		}
+
+		if !strings.HasSuffix(strings.ToLower(doc.FileName), ".csv") {
+			return c.Send(loc.T("import.not_csv"))
+		}
+		if doc.FileSize > importer.MaxFileSize {
+			return c.Send(loc.T("import.too_large"))
+		}
+
+		file, err := b.File(&doc.File)
+		if err != nil {
+			slog.ErrorContext(ctx, "Ошибка загрузки файла", "err", err)
+			return c.Send(loc.T("error.file_download"))
+		}
+		defer file.Close()
+
+		// Для Strong без колонки единиц вес берётся в единицах по умолчанию,
+		// подпись «lbs» или «kg» их переопределяет
+		caption := strings.ToLower(c.Message().Caption)
+		lbs := cfg.Defaults.Units == config.UnitsLbs
+		switch {
+		case strings.Contains(caption, "lb"):
+			lbs = true
+		case strings.Contains(caption, "kg"), strings.Contains(caption, "кг"):
+			lbs = false
+		}
+
+		plan, err := importerService.Prepare(ctx, user, file, lbs)
+		if err != nil {
+			var parseErr *i18n.Error
+			if !errors.As(err, &parseErr) {
+				slog.ErrorContext(ctx, "Ошибка подготовки импорта", "err", err)
+				return sendError(c, err, "import.error")
+			}
+			return c.Send(loc.T("import.parse_error", loc.Error(err)))
+		}
+
+		states.PendingImport = plan
+
+		menu := &telebot.ReplyMarkup{ResizeKeyboard: true, OneTimeKeyboard: true}
+		menu.Reply(menu.Row(menu.Text(loc.T("import.confirm")), menu.Text(loc.T("import.cancel"))))
+		return c.Send(importer.Summary(loc, plan), menu)
 	})
 
 	b.Handle(telebot.OnText, func(c telebot.Context) error {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		loc := locale(c)
+		user := currentUser(c)
 		userID := c.Sender().ID
-		username := helper.GetUserName(c.Sender())
 		text := strings.TrimSpace(c.Text())
 
-		states, exists := userStates[userID]
-		if !exists {
-			states = &state.UserState{}
-			userStates[userID] = states
+		states := getUserState(userID)
+
+		// Ожидаем подтверждения удаления аккаунта
+		if states.WaitingForDeleteConfirm {
+			states.WaitingForDeleteConfirm = false
+			if text != loc.T("delete.confirm") {
+				return c.Send(loc.T("delete.cancelled"), mainKeyboard(loc))
+			}
+
+			deleted, err := userService.DeleteAccount(ctx, user)
+			if err != nil {
+				slog.ErrorContext(ctx, "Ошибка удаления аккаунта", "err", err)
+				return sendError(c, err, "delete.error", mainKeyboard(loc))
+			}
+			deleteUserState(userID)
+			if !deleted {
+				return c.Send(loc.T("mydata.nothing"), removeKeyboard)
+			}
+			return c.Send(loc.T("delete.done"), removeKeyboard)
+		}
+
+		// Ожидаем подтверждения рассылки администратора
+		if states.PendingBroadcast != "" {
+			return confirmBroadcast(c, loc, states, broadcaster)
+		}
+
+		// Ожидаем подтверждения импорта CSV
+		if states.PendingImport != nil {
+			plan := states.PendingImport
+			switch text {
+			case loc.T("import.confirm"):
+				states.PendingImport = nil
+				replyText, err := importerService.Commit(ctx, loc, user, plan)
+				if err != nil {
+					slog.ErrorContext(ctx, "Ошибка импорта", "err", err)
+					return sendError(c, err, "import.error", mainKeyboard(loc))
+				}
+				return c.Send(replyText, mainKeyboard(loc))
+			case loc.T("import.cancel"):
+				states.PendingImport = nil
+				return c.Send(loc.T("import.cancelled"), mainKeyboard(loc))
+			default:
+				return c.Send(loc.T("import.pending", loc.T("import.confirm"), loc.T("import.cancel")))
+			}
 		}
 
 		// Передаём управление сервису
-		replyText, err := historyService.SaveHistory(userID, text, username, states)
+		replyText, err := historyService.SaveHistory(ctx, loc, user, text, states)
 		if err != nil {
-			log.Printf("Ошибка сохранения истории: %v", err)
-			return c.Send("Произошла ошибка. Попробуй позже.")
+			slog.ErrorContext(ctx, "Ошибка сохранения истории", "err", err)
+			return sendError(c, err, "error.generic")
 		}
 
-		// Отправляем ответ пользователю
-		return c.Send(replyText)
+		// Отправляем ответ пользователю; после сохранения подхода возвращаем главную клавиатуру
+		return c.Send(replyText, flowKeyboard(loc, states))
 	})
-
-	log.Printf("End handler")
 }
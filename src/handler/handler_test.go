@@ -0,0 +1,447 @@
+package bot
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"gofitness/src/config"
+	"gofitness/src/i18n"
+	"gofitness/src/service/backup"
+	"gofitness/src/service/token"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// CSV в формате /export csv: жим и присед через день за последние две недели
+func historyCSV() []byte {
+	var b strings.Builder
+	b.WriteString("date,time,exercise,weight_kg,reps\n")
+	now := time.Now()
+	for day := 13; day >= 1; day -= 2 {
+		date := now.AddDate(0, 0, -day).Format("2006-01-02")
+		for set := 0; set < 3; set++ {
+			fmt.Fprintf(&b, "%s,18:0%d:00,Жим лежа,%d,8\n", date, set, 60+13-day)
+			fmt.Fprintf(&b, "%s,18:3%d:00,Приседания,%d,5\n", date, set, 90+13-day)
+		}
+	}
+	return []byte(b.String())
+}
+
+// Загружаем историю через импорт CSV и проверяем, что всё сохранилось
+func importHistory(t *testing.T, u *chat) {
+	t.Helper()
+
+	summary := u.sendDocument("history.csv", historyCSV(), "").has(i18n.RU.T("import.sets", 42))
+	expectButtons(t, summary, "✅ Импортировать | ❌ Отмена")
+	u.send("✅ Импортировать").has("Импортировано подходов: 42")
+	if sets := u.sets(); len(sets) != 42 {
+		t.Fatalf("сохранено %d подходов, ожидалось 42", len(sets))
+	}
+}
+
+func TestStartSavesUserAndShowsMainKeyboard(t *testing.T) {
+	h := newHarness(t, nil)
+	u := h.user(100, "ru")
+
+	r := u.send("/start").has("Привет!", "/add", "/settings")
+	expectButtons(t, r, mainButtonsText(i18n.RU))
+	if strings.Contains(r.Text, "/admin") {
+		t.Error("обычному пользователю показана /admin")
+	}
+
+	user := u.stored()
+	if user == nil {
+		t.Fatal("пользователь не сохранён")
+	}
+	if user.Username != "user100 Test " {
+		t.Errorf("имя %q", user.Username)
+	}
+}
+
+func TestAddSetDialogue(t *testing.T) {
+	h := newHarness(t, nil)
+	u := h.user(100, "ru")
+	ru := i18n.RU
+
+	choose := u.send("/add").is(ru, "add.choose")
+	if buttons := choose.buttons(); !strings.Contains(buttons, "Жим лежа") || !strings.Contains(buttons, "Приседания") {
+		t.Fatalf("в клавиатуре нет упражнений: %q", buttons)
+	}
+
+	selected := u.send("Жим лежа").is(ru, "add.selected", "Жим лежа")
+	if !selected.RemoveKeyboard {
+		t.Error("пока ждём повторения, клавиатура упражнений должна исчезнуть")
+	}
+	u.send("восемь").is(ru, "add.bad_reps")
+	u.send("8").is(ru, "add.enter_weight", 8)
+	u.send("-5").is(ru, "add.bad_weight")
+	saved := u.send("80").is(ru, "add.saved", "Жим лежа", ru.N("reps", 8), 80.0)
+	expectButtons(t, saved, mainButtonsText(ru))
+
+	sets := u.sets()
+	if len(sets) != 1 || sets[0].ExerciseName != "Жим лежа" || sets[0].Weight != 80 || sets[0].Reps != 8 {
+		t.Fatalf("сохранены подходы %+v", sets)
+	}
+
+	// Кнопка главной клавиатуры работает как команда
+	u.send(ru.T("button.history")).has("Последние подходы", "Жим лежа: 80.0 кг × 8")
+	u.send(ru.T("button.finish")).has("Тренировка завершена", "1 подход", "8 повторений", "Тоннаж: 640 кг")
+}
+
+func TestAddSetInEnglish(t *testing.T) {
+	h := newHarness(t, nil)
+	u := h.user(100, "en")
+	en := i18n.EN
+
+	choose := u.send(en.T("button.add")).is(en, "add.choose")
+	if !strings.Contains(choose.buttons(), "Bench press") {
+		t.Fatalf("в клавиатуре нет переведённых упражнений: %q", choose.buttons())
+	}
+	u.send("Bench press").is(en, "add.selected", "Bench press")
+	u.send("10").is(en, "add.enter_weight", 10)
+	u.send("0").is(en, "add.saved", "Bench press", en.N("reps", 10), 0.0)
+
+	// Упражнение хранится под исходным названием
+	if sets := u.sets(); len(sets) != 1 || sets[0].ExerciseName != "Жим лежа" {
+		t.Fatalf("сохранены подходы %+v", sets)
+	}
+	u.send("/history").has("Bench press: 10 reps")
+}
+
+func TestTextOutsideDialogue(t *testing.T) {
+	h := newHarness(t, nil)
+	u := h.user(100, "ru")
+
+	u.send("привет").is(i18n.RU, "add.unknown_exercise")
+	u.send("/finish").is(i18n.RU, "finish.empty")
+	if sets := u.sets(); len(sets) != 0 {
+		t.Fatalf("сохранены подходы %+v", sets)
+	}
+}
+
+func TestFinishResetsDialogue(t *testing.T) {
+	h := newHarness(t, nil)
+	u := h.user(100, "ru")
+
+	u.send("/add")
+	u.send("Приседания")
+	u.send("/finish").is(i18n.RU, "finish.empty")
+	// Ввод подхода сброшен: число теперь не повторения
+	u.send("5").is(i18n.RU, "add.unknown_exercise")
+}
+
+func TestExercises(t *testing.T) {
+	h := newHarness(t, nil)
+
+	h.user(100, "ru").send("/exercises").has("Жим лежа", "Становая тяга", "грудь")
+	h.user(200, "en").send("/exercises").has("Bench press", "Deadlift", "chest")
+}
+
+func TestChartsWithoutData(t *testing.T) {
+	h := newHarness(t, nil)
+	u := h.user(100, "ru")
+	ru := i18n.RU
+
+	u.send("/history").is(ru, "history.empty")
+	u.send("/stats").is(ru, "stats.no_data")
+	u.send("/volume").is(ru, "volume.empty")
+	u.send("/volume 99").is(ru, "volume.usage")
+	u.send("/compare Жим лежа").has("минимум два упражнения")
+	u.send("/calendar 1800").is(ru, "calendar.usage")
+	u.send("/report decade").is(ru, "report.usage")
+	u.send("/report week").has("Отчёт за неделю")
+}
+
+func TestChartsAfterImport(t *testing.T) {
+	h := newHarness(t, nil)
+	u := h.user(100, "ru")
+	importHistory(t, u)
+
+	u.send("/history").has("Последние подходы", "Приседания: 102.0 кг × 5")
+
+	stats := u.send("/stats").is(i18n.RU, "stats.caption")
+	if stats.Method != "sendPhoto" || len(stats.File) == 0 {
+		t.Fatalf("/stats: %s без графика", stats.Method)
+	}
+
+	volume := u.send("/volume 2").has("Грудь", "Квадрицепс")
+	if volume.Method != "sendPhoto" {
+		t.Errorf("/volume: %s без графика", volume.Method)
+	}
+
+	compare := u.send("/compare Жим лежа, Squat, Становая тяга 30d e1rm").has("Становая тяга")
+	if compare.Method != "sendPhoto" {
+		t.Errorf("/compare: %s без графика", compare.Method)
+	}
+
+	calendar := u.send("/calendar sets").has(fmt.Sprint(time.Now().Year()))
+	if calendar.Method != "sendPhoto" {
+		t.Errorf("/calendar: %s без графика", calendar.Method)
+	}
+
+	report := u.send("/report month")
+	if len(report.list) != 2 || report.list[1].Method != "sendPhoto" {
+		t.Fatalf("/report month: %s", report)
+	}
+	if !strings.Contains(report.list[0].Text, "Отчёт за месяц") {
+		t.Errorf("отчёт: %q", report.list[0].Text)
+	}
+}
+
+func TestImportConfirmation(t *testing.T) {
+	h := newHarness(t, nil)
+	u := h.user(100, "ru")
+	ru := i18n.RU
+
+	u.sendDocument("notes.txt", []byte("hello"), "").is(ru, "import.not_csv")
+	u.sendDocument("bad.csv", []byte("a,b\n1,2\n"), "").has(ru.T("import.unknown_format"))
+
+	u.sendDocument("history.csv", historyCSV(), "")
+	u.send("что?").is(ru, "import.pending", ru.T("import.confirm"), ru.T("import.cancel"))
+	cancelled := u.send(ru.T("import.cancel")).is(ru, "import.cancelled")
+	expectButtons(t, cancelled, mainButtonsText(ru))
+	if sets := u.sets(); len(sets) != 0 {
+		t.Fatalf("после отмены сохранено %d подходов", len(sets))
+	}
+
+	importHistory(t, u)
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	h := newHarness(t, nil)
+	alice := h.user(100, "ru")
+	bob := h.user(200, "ru")
+
+	alice.send("/export pdf").is(i18n.RU, "export.only_csv")
+
+	importHistory(t, alice)
+	export := alice.send("/export csv").one()
+	if export.Method != "sendDocument" || !strings.HasSuffix(export.FileName, ".csv") {
+		t.Fatalf("/export: %s %q", export.Method, export.FileName)
+	}
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(string(export.File), "\uFEFF"))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 43 || strings.Join(records[0], ",") != "date,time,exercise,weight_kg,reps,e1rm_kg,set_id" {
+		t.Fatalf("выгружено %d строк, заголовок %v", len(records), records[0])
+	}
+
+	bob.sendDocument(export.FileName, export.File, "")
+	bob.send("✅ Импортировать").has("Импортировано подходов: 42, создано упражнений: 0")
+	if len(bob.sets()) != 42 {
+		t.Fatalf("у второго пользователя %d подходов", len(bob.sets()))
+	}
+}
+
+func TestBackupRestore(t *testing.T) {
+	h := newHarness(t, nil)
+	alice := h.user(100, "ru")
+	bob := h.user(200, "ru")
+
+	alice.send("/add")
+	alice.send("Подтягивания")
+	alice.send("10")
+	alice.send("0")
+	alice.send("/settings broadcast off")
+
+	backup := alice.send("/backup").is(i18n.RU, "backup.caption")
+	if backup.Method != "sendDocument" || !strings.HasSuffix(backup.FileName, ".json") {
+		t.Fatalf("/backup: %s %q", backup.Method, backup.FileName)
+	}
+
+	bob.send("/restore").is(i18n.RU, "restore.prompt")
+	bob.sendDocument(backup.FileName, []byte("{"), "").is(i18n.RU, "restore.bad_json")
+
+	// Подпись /restore работает без отдельной команды; повтор не создаёт дубликатов
+	bob.sendDocument(backup.FileName, backup.File, "/restore").has("Добавлено подходов: 1, уже были: 0")
+	bob.sendDocument(backup.FileName, backup.File, "/restore").has("Добавлено подходов: 0, уже были: 1")
+	if sets := bob.sets(); len(sets) != 1 || sets[0].ExerciseName != "Подтягивания" || sets[0].Reps != 10 {
+		t.Fatalf("восстановлены подходы %+v", sets)
+	}
+	if !bob.stored().BroadcastOptOut {
+		t.Fatal("отказ от рассылок не восстановлен")
+	}
+}
+
+// Одинаковые подходы копии восстанавливаются все, но только один раз
+func TestRestoreRepeatedSets(t *testing.T) {
+	h := newHarness(t, nil)
+	alice := h.user(100, "ru")
+	bob := h.user(200, "ru")
+
+	alice.send("/add")
+	alice.send("Подтягивания")
+	alice.send("10")
+	alice.send("0")
+	file := alice.send("/backup").one()
+
+	var doubled backup.Backup
+	if err := json.Unmarshal(file.File, &doubled); err != nil {
+		t.Fatal(err)
+	}
+	doubled.Sets = append(doubled.Sets, doubled.Sets[0], doubled.Sets[0])
+	data, err := json.Marshal(doubled)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bob.sendDocument(file.FileName, data, "/restore").has("Добавлено подходов: 3, уже были: 0")
+	bob.sendDocument(file.FileName, data, "/restore").has("Добавлено подходов: 0, уже были: 3")
+	if sets := bob.sets(); len(sets) != 3 {
+		t.Fatalf("восстановлено подходов: %d", len(sets))
+	}
+
+	// Alice уже хранит один такой подход — добавляются только два недостающих
+	alice.sendDocument(file.FileName, data, "/restore").has("Добавлено подходов: 2, уже были: 1")
+}
+
+func TestSettings(t *testing.T) {
+	h := newHarness(t, nil)
+	u := h.user(100, "en")
+	ru, en := i18n.RU, i18n.EN
+
+	u.send("/settings").is(en, "settings.current", en.Name(), en.T("settings.auto"), en.T("settings.broadcast.on"))
+	u.send("/settings language de").is(en, "settings.usage")
+	u.send("/settings theme dark").is(en, "settings.usage")
+
+	// Ответ уже на выбранном языке, и дальше бот говорит на нём
+	u.send("/settings language ru").is(ru, "settings.saved", ru.Name())
+	u.send("/history").is(ru, "history.empty")
+	if user := u.stored(); user.Language != "ru" {
+		t.Fatalf("сохранён язык %q", user.Language)
+	}
+
+	u.send("/settings рассылки выкл").is(ru, "settings.broadcast.saved_off")
+	u.send("/settings").is(ru, "settings.current", ru.Name(), "", ru.T("settings.broadcast.off"))
+	if user := u.stored(); !user.BroadcastOptOut {
+		t.Fatal("отказ от рассылок не сохранён")
+	}
+
+	u.send("/settings language auto").is(en, "settings.auto_saved", en.Name())
+	u.send("/history").is(en, "history.empty")
+}
+
+func TestMyDataAndDeleteAccount(t *testing.T) {
+	h := newHarness(t, nil)
+	u := h.user(100, "ru")
+	ru := i18n.RU
+
+	// Просмотр данных не заводит аккаунт
+	u.send("/mydata").is(ru, "mydata.nothing")
+	if u.stored() != nil {
+		t.Fatal("/mydata создал пользователя")
+	}
+
+	u.send("/start")
+	u.send("/add")
+	u.send("Планка")
+	u.send("1")
+	u.send("0")
+	u.send("/mydata").has("Telegram ID: 100", "Подходов: 1")
+
+	prompt := u.send("/deleteme").is(ru, "delete.prompt")
+	expectButtons(t, prompt, ru.T("delete.confirm")+" | "+ru.T("delete.cancel"))
+	u.send(ru.T("delete.cancel")).is(ru, "delete.cancelled")
+	if u.stored() == nil {
+		t.Fatal("аккаунт удалён без подтверждения")
+	}
+
+	u.send("/deleteme")
+	done := u.send(ru.T("delete.confirm")).is(ru, "delete.done")
+	if !done.RemoveKeyboard {
+		t.Error("после удаления клавиатура должна исчезнуть")
+	}
+	if u.stored() != nil {
+		t.Fatal("аккаунт не удалён")
+	}
+	u.send("/mydata").is(ru, "mydata.nothing")
+}
+
+func TestDeleteAccountThatDoesNotExist(t *testing.T) {
+	h := newHarness(t, nil)
+	u := h.user(100, "ru")
+
+	u.send("/deleteme")
+	u.send(i18n.RU.T("delete.confirm")).is(i18n.RU, "mydata.nothing")
+	if u.stored() != nil {
+		t.Fatal("подтверждение удаления создало пользователя")
+	}
+}
+
+func TestUsernameRefresh(t *testing.T) {
+	h := newHarness(t, nil)
+	u := h.user(100, "ru")
+
+	u.send("/start")
+	u.name = "renamed"
+	u.send("/history")
+	if user := u.stored(); user.Username != "renamed Test " {
+		t.Fatalf("имя не обновилось: %q", user.Username)
+	}
+}
+
+var tokenRx = regexp.MustCompile(token.Prefix + `[0-9a-f]+`)
+
+func TestToken(t *testing.T) {
+	h := newHarness(t, nil)
+	u := h.user(100, "ru")
+
+	issued := u.send("/token").has("HTTP API")
+	raw := tokenRx.FindString(issued.Text)
+	if raw == "" {
+		t.Fatalf("в ответе нет токена: %q", issued.Text)
+	}
+
+	tokens := token.NewTokenService(h.db)
+	user, err := tokens.Authenticate(context.Background(), raw)
+	if err != nil || user == nil || user.ChatID != 100 {
+		t.Fatalf("токен не принят: %v, %+v", err, user)
+	}
+
+	u.send("/token revoke").is(i18n.RU, "token.revoked")
+	if user, _ := tokens.Authenticate(context.Background(), raw); user != nil {
+		t.Fatal("отозванный токен принят")
+	}
+}
+
+func TestTokenAndWebDisabled(t *testing.T) {
+	h := newHarness(t, func(cfg *config.Config) {
+		cfg.Features.API = false
+		cfg.Features.Web = false
+	})
+	u := h.user(100, "ru")
+
+	u.send("/token").is(i18n.RU, "token.disabled")
+	u.send("/web").is(i18n.RU, "web.disabled")
+}
+
+func TestWeb(t *testing.T) {
+	h := newHarness(t, nil)
+	h.user(100, "ru").send("/web").is(i18n.RU, "web.not_configured")
+
+	h = newHarness(t, func(cfg *config.Config) {
+		cfg.PublicURL = "https://fit.example.com/"
+	})
+	h.user(100, "ru").send("/web").has("https://fit.example.com/login?code=")
+}
+
+func TestRateLimit(t *testing.T) {
+	h := newHarness(t, func(cfg *config.Config) {
+		cfg.Limits.TextPerMinute = 1
+		cfg.Limits.TextBurst = 2
+	})
+	u := h.user(100, "ru")
+
+	u.send("/history").is(i18n.RU, "history.empty")
+	u.send("/history").is(i18n.RU, "history.empty")
+	u.send("/history").has("Слишком много сообщений")
+	// О задержке сообщаем один раз
+	u.send("/history").none()
+
+	// У других пользователей свой лимит
+	h.user(200, "ru").send("/history").is(i18n.RU, "history.empty")
+}
@@ -0,0 +1,443 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"gofitness/src/config"
+	"gofitness/src/database"
+	"gofitness/src/i18n"
+	"gofitness/src/model"
+	"gofitness/src/service/admin"
+	"gofitness/src/service/session"
+	"gofitness/src/state"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gopkg.in/telebot.v3"
+)
+
+// Сообщение, которое бот отправил через Bot API
+type reply struct {
+	Method string
+	ChatID int64
+	// Текст сообщения или подпись к файлу
+	Text string
+	// Кнопки обычной клавиатуры по строкам; nil — клавиатуру не меняли
+	Keyboard       [][]string
+	RemoveKeyboard bool
+	// Отправленный файл (фото, документ)
+	FileName string
+	File     []byte
+}
+
+// Кнопки клавиатуры одной строкой через « | »
+func (r reply) buttons() string {
+	var all []string
+	for _, row := range r.Keyboard {
+		all = append(all, row...)
+	}
+	return strings.Join(all, " | ")
+}
+
+// Поддельный Bot API: запоминает исходящие сообщения, отвечает успехом
+// и отдаёт файлы, которые «прислали» пользователи
+type fakeAPI struct {
+	mu        sync.Mutex
+	replies   []reply
+	files     map[string][]byte
+	messageID int
+}
+
+func (api *fakeAPI) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Скачивание файла: /file/bot<token>/<file_path>
+	if strings.Contains(req.URL.Path, "/file/bot") {
+		api.mu.Lock()
+		data, ok := api.files[path.Base(req.URL.Path)]
+		api.mu.Unlock()
+		if !ok {
+			return respond(http.StatusNotFound, `{"ok":false}`), nil
+		}
+		return respond(http.StatusOK, string(data)), nil
+	}
+
+	params, file, err := readParams(req)
+	if err != nil {
+		return nil, err
+	}
+
+	method := path.Base(req.URL.Path)
+	switch method {
+	case "getFile":
+		return respond(http.StatusOK, fmt.Sprintf(`{"ok":true,"result":{"file_id":%q,"file_path":"documents/%s"}}`,
+			params["file_id"], params["file_id"])), nil
+	case "answerCallbackQuery", "sendChatAction", "setMyCommands", "deleteMessage":
+		return respond(http.StatusOK, `{"ok":true,"result":true}`), nil
+	}
+
+	chatID, _ := strconv.ParseInt(params["chat_id"], 10, 64)
+	r := reply{Method: method, ChatID: chatID, Text: params["text"], FileName: file.name, File: file.data}
+	if caption, ok := params["caption"]; ok {
+		r.Text = caption
+	}
+	if markup := params["reply_markup"]; markup != "" {
+		var keyboard struct {
+			Keyboard [][]struct {
+				Text string `json:"text"`
+			} `json:"keyboard"`
+			RemoveKeyboard bool `json:"remove_keyboard"`
+		}
+		if err := json.Unmarshal([]byte(markup), &keyboard); err != nil {
+			return nil, fmt.Errorf("reply_markup %q: %w", markup, err)
+		}
+		r.RemoveKeyboard = keyboard.RemoveKeyboard
+		for _, row := range keyboard.Keyboard {
+			var texts []string
+			for _, btn := range row {
+				texts = append(texts, btn.Text)
+			}
+			r.Keyboard = append(r.Keyboard, texts)
+		}
+	}
+
+	api.mu.Lock()
+	api.replies = append(api.replies, r)
+	api.messageID++
+	id := api.messageID
+	api.mu.Unlock()
+
+	// telebot копирует отправленный файл из ответа, так что он должен там быть
+	media := ""
+	switch method {
+	case "sendPhoto":
+		media = fmt.Sprintf(`,"photo":[{"file_id":"photo%d","width":800,"height":600}]`, id)
+	case "sendDocument":
+		media = fmt.Sprintf(`,"document":{"file_id":"document%d","file_name":%q}`, id, file.name)
+	}
+	return respond(http.StatusOK, fmt.Sprintf(`{"ok":true,"result":{"message_id":%d,"date":%d,"chat":{"id":%d,"type":"private"}%s}}`,
+		id, time.Now().Unix(), chatID, media)), nil
+}
+
+func respond(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+// Файл из multipart-запроса
+type uploaded struct {
+	name string
+	data []byte
+}
+
+// Параметры запроса: telebot шлёт JSON со строковыми значениями,
+// а файлы — в multipart/form-data
+func readParams(req *http.Request) (map[string]string, uploaded, error) {
+	params := make(map[string]string)
+	var file uploaded
+	if req.Body == nil {
+		return params, file, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, file, err
+	}
+
+	mediaType, mediaParams, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		if len(body) > 0 {
+			if err := json.Unmarshal(body, &params); err != nil {
+				return nil, file, fmt.Errorf("тело %s: %w", req.URL.Path, err)
+			}
+		}
+		return params, file, nil
+	}
+
+	form := multipart.NewReader(bytes.NewReader(body), mediaParams["boundary"])
+	for {
+		part, err := form.NextPart()
+		if err == io.EOF {
+			return params, file, nil
+		}
+		if err != nil {
+			return nil, file, err
+		}
+		data, err := io.ReadAll(part)
+		if err != nil {
+			return nil, file, err
+		}
+		// Файлы telebot пишет через CreateFormFile, у полей типа нет
+		if part.Header.Get("Content-Type") != "" {
+			file = uploaded{name: part.FileName(), data: data}
+		} else {
+			params[part.FormName()] = string(data)
+		}
+	}
+}
+
+// harness — бот со всеми обработчиками SetupHandlers поверх поддельного
+// Bot API и хранилища в памяти. Обновления обрабатываются синхронно
+type harness struct {
+	t           *testing.T
+	bot         *telebot.Bot
+	db          *database.Memory
+	cfg         *config.Config
+	api         *fakeAPI
+	broadcaster *admin.Broadcaster
+
+	mu       sync.Mutex
+	updateID int
+}
+
+// newHarness запускает бота; configure меняет конфигурацию по умолчанию.
+// Ограничения частоты выключены, чтобы длинные диалоги не упирались в них
+func newHarness(t *testing.T, configure func(cfg *config.Config)) *harness {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.Limits.TextPerMinute = 0
+	cfg.Limits.HeavyPerMinute = 0
+	if configure != nil {
+		configure(cfg)
+	}
+
+	api := &fakeAPI{files: make(map[string][]byte)}
+	b, err := telebot.NewBot(telebot.Settings{
+		Token:       "123456:TEST",
+		Offline:     true,
+		Synchronous: true,
+		Client:      &http.Client{Transport: api},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := database.NewMemory()
+	if err := db.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Состояния диалогов общие для пакета: каждый тест начинает с чистых
+	userStatesMu.Lock()
+	userStates = make(map[int64]*state.UserState)
+	userStatesMu.Unlock()
+
+	broadcaster := admin.NewBroadcaster(b, db, cfg.Limits.BroadcastPerSecond)
+	broadcaster.Start()
+	t.Cleanup(broadcaster.Stop)
+
+	SetupHandlers(b, db, session.NewSessionService(db, cfg.PublicURL), broadcaster, cfg)
+
+	return &harness{t: t, bot: b, db: db, cfg: cfg, api: api, broadcaster: broadcaster}
+}
+
+// chat — собеседник бота в личном чате или, если задан group, в группе
+type chat struct {
+	h     *harness
+	id    int64
+	lang  string
+	name  string
+	group int64
+}
+
+// user — пользователь с языком Telegram lang
+func (h *harness) user(id int64, lang string) *chat {
+	return &chat{h: h, id: id, lang: lang, name: fmt.Sprintf("user%d", id)}
+}
+
+// inGroup — тот же пользователь, но пишет в группу groupID (отрицательный)
+func (c *chat) inGroup(groupID int64) *chat {
+	g := *c
+	g.group = groupID
+	return &g
+}
+
+// Обрабатываем обновление и возвращаем всё, что бот отправил за это время
+func (c *chat) process(msg *telebot.Message) replies {
+	c.h.t.Helper()
+
+	c.h.mu.Lock()
+	c.h.updateID++
+	id := c.h.updateID
+	c.h.mu.Unlock()
+
+	msg.ID = id
+	msg.Unixtime = time.Now().Unix()
+	msg.Sender = &telebot.User{ID: c.id, Username: c.name, FirstName: "Test", LanguageCode: c.lang}
+	msg.Chat = &telebot.Chat{ID: c.id, Type: telebot.ChatPrivate}
+	if c.group != 0 {
+		msg.Chat = &telebot.Chat{ID: c.group, Type: telebot.ChatGroup}
+	}
+
+	c.h.api.mu.Lock()
+	start := len(c.h.api.replies)
+	c.h.api.mu.Unlock()
+
+	c.h.bot.ProcessUpdate(telebot.Update{ID: id, Message: msg})
+
+	c.h.api.mu.Lock()
+	defer c.h.api.mu.Unlock()
+	return replies{t: c.h.t, list: append([]reply(nil), c.h.api.replies[start:]...)}
+}
+
+// send — сообщение или команда от пользователя
+func (c *chat) send(text string) replies {
+	c.h.t.Helper()
+
+	msg := &telebot.Message{Text: text}
+	if strings.HasPrefix(text, "/") {
+		command := strings.Fields(text)[0]
+		msg.Entities = telebot.Entities{{Type: telebot.EntityCommand, Length: len(command)}}
+	}
+	return c.process(msg)
+}
+
+// sendDocument — файл от пользователя; бот скачает его через поддельный API
+func (c *chat) sendDocument(name string, data []byte, caption string) replies {
+	c.h.t.Helper()
+
+	c.h.api.mu.Lock()
+	fileID := fmt.Sprintf("file%d", len(c.h.api.files)+1)
+	c.h.api.files[fileID] = data
+	c.h.api.mu.Unlock()
+
+	return c.process(&telebot.Message{
+		Caption: caption,
+		Document: &telebot.Document{
+			File:     telebot.File{FileID: fileID, UniqueID: fileID, FileSize: int64(len(data))},
+			FileName: name,
+		},
+	})
+}
+
+// Пользователь в хранилище; nil — бот его не сохранял
+func (c *chat) stored() *model.User {
+	c.h.t.Helper()
+
+	user, err := c.h.db.GetUserByChatID(context.Background(), c.id)
+	if err != nil {
+		c.h.t.Fatal(err)
+	}
+	return user
+}
+
+// Сохранённые подходы пользователя, новые первыми
+func (c *chat) sets() []model.WorkoutSet {
+	c.h.t.Helper()
+
+	user := c.stored()
+	if user == nil {
+		return nil
+	}
+	sets, err := c.h.db.GetUserWorkoutHistory(context.Background(), user.ID, 1000)
+	if err != nil {
+		c.h.t.Fatal(err)
+	}
+	return sets
+}
+
+// waitFor ждёт сообщения в чат chatID с текстом want от фоновых задач
+func (h *harness) waitFor(chatID int64, want string) reply {
+	h.t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		h.api.mu.Lock()
+		for _, r := range h.api.replies {
+			if r.ChatID == chatID && strings.Contains(r.Text, want) {
+				h.api.mu.Unlock()
+				return r
+			}
+		}
+		h.api.mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+	}
+	h.t.Fatalf("в чат %d не пришло сообщение с %q", chatID, want)
+	return reply{}
+}
+
+// replies — ответы бота на одно обновление
+type replies struct {
+	t    *testing.T
+	list []reply
+}
+
+// one проверяет, что ответ ровно один, и возвращает его
+func (r replies) one() reply {
+	r.t.Helper()
+
+	if len(r.list) != 1 {
+		r.t.Fatalf("ожидался один ответ, получено %d: %s", len(r.list), r)
+	}
+	return r.list[0]
+}
+
+// none проверяет, что бот промолчал
+func (r replies) none() {
+	r.t.Helper()
+
+	if len(r.list) != 0 {
+		r.t.Fatalf("ожидалось молчание, получено: %s", r)
+	}
+}
+
+// has проверяет, что единственный ответ содержит все подстроки want
+func (r replies) has(want ...string) reply {
+	r.t.Helper()
+
+	got := r.one()
+	for _, w := range want {
+		if !strings.Contains(got.Text, w) {
+			r.t.Fatalf("в ответе нет %q:\n%s", w, got.Text)
+		}
+	}
+	return got
+}
+
+// is проверяет, что единственный ответ — сообщение key каталога loc
+func (r replies) is(loc i18n.Locale, key string, args ...interface{}) reply {
+	r.t.Helper()
+
+	got := r.one()
+	if want := loc.T(key, args...); got.Text != want {
+		r.t.Fatalf("ответ %q, ожидался %s: %q", got.Text, key, want)
+	}
+	return got
+}
+
+func (r replies) String() string {
+	var parts []string
+	for _, reply := range r.list {
+		parts = append(parts, fmt.Sprintf("%s → %d: %q", reply.Method, reply.ChatID, reply.Text))
+	}
+	return "[" + strings.Join(parts, "; ") + "]"
+}
+
+// Проверяем клавиатуру ответа: кнопки через « | »
+func expectButtons(t *testing.T, r reply, want string) {
+	t.Helper()
+
+	if got := r.buttons(); got != want {
+		t.Fatalf("клавиатура %q, ожидалась %q", got, want)
+	}
+}
+
+// Главная клавиатура на языке loc
+func mainButtonsText(loc i18n.Locale) string {
+	var texts []string
+	for _, btn := range mainButtons {
+		texts = append(texts, loc.T(btn.Key))
+	}
+	return strings.Join(texts, " | ")
+}
@@ -0,0 +1,50 @@
+package bot
+
+import (
+	"gofitness/src/i18n"
+	"gofitness/src/state"
+
+	"gopkg.in/telebot.v3"
+)
+
+// Кнопки главной клавиатуры и команды, которые они повторяют
+var mainButtons = []struct {
+	Key     string
+	Command string
+}{
+	{Key: "button.add", Command: "/add"},
+	{Key: "button.history", Command: "/history"},
+	{Key: "button.stats", Command: "/stats"},
+	{Key: "button.finish", Command: "/finish"},
+}
+
+// Текст кнопки на любом языке → команда
+var buttonCommands = func() map[string]string {
+	commands := make(map[string]string)
+	for _, loc := range i18n.Locales {
+		for _, btn := range mainButtons {
+			commands[loc.T(btn.Key)] = btn.Command
+		}
+	}
+	return commands
+}()
+
+// Главная клавиатура: остаётся на экране, пока её не сменит клавиатура диалога
+func mainKeyboard(loc i18n.Locale) *telebot.ReplyMarkup {
+	menu := &telebot.ReplyMarkup{ResizeKeyboard: true}
+	var buttons []telebot.Btn
+	for _, btn := range mainButtons {
+		buttons = append(buttons, menu.Text(loc.T(btn.Key)))
+	}
+	menu.Reply(menu.Split(2, buttons)...)
+	return menu
+}
+
+// Клавиатура после шага ввода подхода: пока ждём числа, списка упражнений
+// на экране быть не должно, а после сохранения возвращается главная
+func flowKeyboard(loc i18n.Locale, s *state.UserState) *telebot.ReplyMarkup {
+	if s.WaitingForReps || s.WaitingForWeight {
+		return removeKeyboard
+	}
+	return mainKeyboard(loc)
+}
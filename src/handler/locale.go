@@ -0,0 +1,21 @@
+package bot
+
+import (
+	"gofitness/src/i18n"
+
+	"gopkg.in/telebot.v3"
+)
+
+// Ключ языка обновления в telebot.Context
+const localeKey = "locale"
+
+// Язык ответов на текущее обновление
+func locale(c telebot.Context) i18n.Locale {
+	if loc, ok := c.Get(localeKey).(i18n.Locale); ok {
+		return loc
+	}
+	if sender := c.Sender(); sender != nil {
+		return i18n.Detect(sender.LanguageCode)
+	}
+	return i18n.Default
+}
@@ -0,0 +1,54 @@
+package bot
+
+import (
+	"context"
+	"gofitness/src/logging"
+	"log/slog"
+	"time"
+
+	"gopkg.in/telebot.v3"
+)
+
+// Ключ контекста обработки в telebot.Context
+const logContextKey = "log_ctx"
+
+// Помечаем все записи обработки обновления его номером, пользователем и командой.
+// Имя и username не пишем: по числовому ID этого достаточно для разбора
+func loggingMiddleware(next telebot.HandlerFunc) telebot.HandlerFunc {
+	return func(c telebot.Context) error {
+		command := commandLabel(c)
+		args := []any{"update_id", c.Update().ID, "command", command}
+		if sender := c.Sender(); sender != nil {
+			args = append(args, "user_id", sender.ID)
+		}
+		ctx := logging.With(context.Background(), args...)
+		c.Set(logContextKey, ctx)
+
+		start := time.Now()
+		slog.DebugContext(ctx, "Обновление получено")
+
+		err := next(c)
+
+		// Саму ошибку пишет OnError, здесь только итог обработки
+		slog.InfoContext(ctx, "Обновление обработано",
+			slog.Duration("duration", time.Since(start)), "failed", err != nil)
+		return err
+	}
+}
+
+// Контекст с атрибутами логирования текущего обновления
+func logContext(c telebot.Context) context.Context {
+	if ctx, ok := c.Get(logContextKey).(context.Context); ok {
+		return ctx
+	}
+	return context.Background()
+}
+
+// Ошибки, возвращённые обработчиками, и сбои самого бота пишем через slog
+func OnError(err error, c telebot.Context) {
+	ctx := context.Background()
+	if c != nil {
+		ctx = logContext(c)
+	}
+	slog.ErrorContext(ctx, "Ошибка бота", "err", err)
+}
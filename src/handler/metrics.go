@@ -0,0 +1,60 @@
+package bot
+
+import (
+	"gofitness/src/metrics"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gopkg.in/telebot.v3"
+)
+
+var conversationStates = promauto.NewGaugeFunc(prometheus.GaugeOpts{
+	Name: "gofitness_conversation_states_active",
+	Help: "Пользователи, от которых бот ждёт ответа (ввод подхода, подтверждения, файл).",
+}, func() float64 {
+	return float64(activeUserStates())
+})
+
+// Метка обновления для метрик: команда или вид сообщения
+func commandLabel(c telebot.Context) string {
+	switch {
+	case c.Callback() != nil:
+		return "callback"
+	case c.Message() == nil:
+		return "other"
+	case c.Message().Document != nil:
+		return "document"
+	}
+
+	text := c.Message().Text
+	if command, ok := buttonCommands[text]; ok {
+		return command
+	}
+	if !strings.HasPrefix(text, "/") {
+		return "text"
+	}
+	command, _, _ := strings.Cut(strings.Fields(text)[0], "@")
+	if knownCommands[command] {
+		return command
+	}
+	return "other"
+}
+
+// Считаем обновления, время и ошибки обработчиков по командам
+func metricsMiddleware(next telebot.HandlerFunc) telebot.HandlerFunc {
+	return func(c telebot.Context) error {
+		command := commandLabel(c)
+		start := time.Now()
+
+		err := next(c)
+
+		metrics.Updates.WithLabelValues(command).Inc()
+		metrics.HandlerDuration.WithLabelValues(command).Observe(time.Since(start).Seconds())
+		if err != nil {
+			metrics.HandlerErrors.WithLabelValues(command).Inc()
+		}
+		return err
+	}
+}
@@ -0,0 +1,84 @@
+package bot
+
+import (
+	"gofitness/src/config"
+	"gofitness/src/i18n"
+	"gofitness/src/metrics"
+	"gofitness/src/ratelimit"
+	"log/slog"
+	"math"
+
+	"gopkg.in/telebot.v3"
+)
+
+// Классы обновлений с отдельными ограничениями частоты
+const (
+	classText  = "text"
+	classHeavy = "heavy"
+)
+
+// Команды, которые строят графики, читают всю историю или принимают файлы
+var heavyCommands = map[string]bool{
+	"/stats":    true,
+	"/volume":   true,
+	"/compare":  true,
+	"/calendar": true,
+	"/report":   true,
+	"/export":   true,
+	"/backup":   true,
+	"document":  true,
+}
+
+// Класс обновления по его метке в метриках
+func commandClass(c telebot.Context) string {
+	if heavyCommands[commandLabel(c)] {
+		return classHeavy
+	}
+	return classText
+}
+
+// Ограничиваем частоту обновлений от каждого пользователя. Стоит до
+// userMiddleware, чтобы флуд не доходил до хранилища, поэтому отвечаем
+// на языке Telegram. О задержке сообщаем один раз, остальное молча отбрасываем
+func rateLimitMiddleware(limits config.Limits) telebot.MiddlewareFunc {
+	limiters := make(map[string]*ratelimit.Limiter)
+	if limits.TextPerMinute > 0 {
+		limiters[classText] = ratelimit.New(limits.TextPerMinute, limits.TextBurst)
+	}
+	if limits.HeavyPerMinute > 0 {
+		limiters[classHeavy] = ratelimit.New(limits.HeavyPerMinute, limits.HeavyBurst)
+	}
+
+	return func(next telebot.HandlerFunc) telebot.HandlerFunc {
+		return func(c telebot.Context) error {
+			sender := c.Sender()
+			if sender == nil {
+				return next(c)
+			}
+			class := commandClass(c)
+			limiter, ok := limiters[class]
+			if !ok {
+				return next(c)
+			}
+
+			res := limiter.Allow(sender.ID)
+			if res.Allowed {
+				return next(c)
+			}
+
+			metrics.Throttled.WithLabelValues(class).Inc()
+			slog.DebugContext(logContext(c), "Обновление отброшено ограничением частоты", "class", class, "retry_after", res.RetryAfter)
+			if !res.First {
+				return nil
+			}
+
+			loc := i18n.Detect(sender.LanguageCode)
+			wait := loc.N("seconds", int(math.Ceil(res.RetryAfter.Seconds())))
+			text := loc.T("throttle."+class, wait)
+			if c.Callback() != nil {
+				return c.Respond(&telebot.CallbackResponse{Text: text})
+			}
+			return c.Send(text)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package bot
+
+import (
+	"gofitness/src/helper"
+	"gofitness/src/i18n"
+	"gofitness/src/model"
+	userservice "gofitness/src/service/user"
+	"log/slog"
+
+	"gopkg.in/telebot.v3"
+)
+
+// Ключ пользователя обновления в telebot.Context
+const userKey = "user"
+
+// Команды, которые только читают или удаляют данные: для них пользователя
+// не создаём, иначе /mydata после /deleteme снова заведёт аккаунт
+var lookupOnlyCommands = map[string]bool{
+	"/mydata":   true,
+	"/deleteme": true,
+}
+
+// Обновление не должно создавать пользователя: команда из lookupOnlyCommands
+// или ответ на подтверждение удаления аккаунта
+func lookupOnly(c telebot.Context) bool {
+	return lookupOnlyCommands[commandLabel(c)] || getUserState(c.Sender().ID).WaitingForDeleteConfirm
+}
+
+// Загружаем пользователя один раз на обновление, а при первом обращении
+// создаём его. Здесь же выбираем язык ответов — из /settings, а если он
+// не задан, по языку Telegram — и не отвечаем заблокированным
+func userMiddleware(users *userservice.UserService) telebot.MiddlewareFunc {
+	return func(next telebot.HandlerFunc) telebot.HandlerFunc {
+		return func(c telebot.Context) error {
+			sender := c.Sender()
+			if sender == nil {
+				return next(c)
+			}
+
+			ctx, cancel := requestContext(c)
+			username := helper.GetUserName(sender)
+			var user *model.User
+			var err error
+			if lookupOnly(c) {
+				user, err = users.GetUser(ctx, sender.ID, username)
+			} else {
+				user, err = users.GetUserOrCreate(ctx, sender.ID, username)
+			}
+			cancel()
+			if err != nil {
+				slog.ErrorContext(ctx, "Ошибка получения пользователя", "err", err)
+				c.Set(localeKey, i18n.Detect(sender.LanguageCode))
+				return sendError(c, err, "error.generic")
+			}
+
+			loc := i18n.Detect(sender.LanguageCode)
+			if user != nil {
+				if user.Banned {
+					slog.DebugContext(ctx, "Обновление от заблокированного пользователя")
+					return nil
+				}
+				if saved, ok := i18n.Parse(user.Language); ok {
+					loc = saved
+				}
+				c.Set(userKey, user)
+			}
+
+			c.Set(localeKey, loc)
+			return next(c)
+		}
+	}
+}
+
+// Пользователь текущего обновления. nil — только для команд, которые его
+// не создают, если пользователя ещё нет
+func currentUser(c telebot.Context) *model.User {
+	user, _ := c.Get(userKey).(*model.User)
+	return user
+}
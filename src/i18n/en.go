@@ -0,0 +1,389 @@
+package i18n
+
+import "gofitness/src/model"
+
+// Английские сообщения
+var en = map[string]string{
+	"language.name": "English",
+
+	"layout.date":     "Jan 2, 2006",
+	"layout.datetime": "Jan 2, 2006 15:04",
+	"layout.short":    "Jan 2 15:04",
+	"layout.day":      "Jan 2",
+	"layout.chart":    "Jan 2 '06",
+
+	"weekdays.short":  "Mon,Tue,Wed,Thu,Fri,Sat,Sun",
+	"months.short":    "Jan,Feb,Mar,Apr,May,Jun,Jul,Aug,Sep,Oct,Nov,Dec",
+	"months.calendar": "Jan,Feb,Mar,Apr,May,Jun,Jul,Aug,Sep,Oct,Nov,Dec",
+	"months.full":     "January,February,March,April,May,June,July,August,September,October,November,December",
+
+	"error.timeout":       "⏳ The server is responding too slowly, nothing was changed. Please try again in a minute.",
+	"error.generic":       "Something went wrong. Please try again later.",
+	"error.file_download": "Couldn't download the file. Please try again.",
+
+	"throttle.text":  "🐢 Too many messages in a row. Wait %s and carry on.",
+	"throttle.heavy": "🐢 Charts and exports can't be requested that often. Try again in %s.",
+
+	"start.text": `🏋️‍♂️ Hi! I'm your fitness assistant!
+
+Commands:
+%s
+Send a CSV from Strong, Hevy or FitNotes to import your history
+
+Tap /add to start your workout!`,
+
+	"command.add":       "Log a set",
+	"command.finish":    "Finish workout",
+	"command.history":   "Workout history",
+	"command.exercises": "Exercise list",
+	"command.stats":     "Workout stats",
+	"command.volume":    "Weekly volume per muscle group",
+	"command.compare":   "Compare exercises: /compare Bench press, Squat 180d",
+	"command.calendar":  "Yearly workout calendar",
+	"command.report":    "Report for a week (week), month (month) or year (year)",
+	"command.export":    "Export all sets to CSV",
+	"command.backup":    "Back up all your data",
+	"command.restore":   "Restore from a backup",
+	"command.web":       "Web dashboard with charts and history",
+	"command.token":     "Personal HTTP API token",
+	"command.settings":  "Settings: language and announcements",
+	"command.mydata":    "What data is stored about you",
+	"command.deleteme":  "Delete your account and all data",
+	"command.admin":     "Admin console",
+
+	"button.add":     "➕ Add set",
+	"button.history": "📊 History",
+	"button.stats":   "📈 Stats",
+	"button.finish":  "🏁 Finish workout",
+
+	"add.error":            "Couldn't load the exercise list. Please try again later.",
+	"add.choose":           "Choose an exercise:",
+	"add.no_state":         "No active entry. Tap /add to start.",
+	"add.selected":         "Selected: %s. Now enter the number of reps (for example, 10).",
+	"add.unknown_exercise": "Unknown exercise. Pick one from the list with /add.",
+	"add.bad_reps":         "Please enter a positive number of reps.",
+	"add.enter_weight":     "Great! Now enter the weight (kg, 0 for bodyweight). Reps: %d",
+	"add.bad_weight":       "Enter a valid weight (>= 0).",
+	"add.saved":            "Set saved: %s — %s, %.1f kg.\n\nWhat's next?",
+
+	"finish.empty":   "No sets today. Tap «➕ Add set» to start.",
+	"finish.done":    "🏁 Workout finished!\nToday: %s, %s.",
+	"finish.tonnage": "\nTonnage: %.0f kg.",
+	"finish.error":   "Couldn't sum up the workout. Please try again later.",
+
+	"exercises.error":  "Couldn't load exercises. Please try again later.",
+	"exercises.title":  "🏋️ All exercises:\n\n",
+	"exercises.custom": " (custom)",
+
+	"history.error":      "Couldn't load your workout history. Please try again later.",
+	"history.empty":      "You haven't logged any sets yet. Use /add to log your first set!",
+	"history.title":      "📊 Recent sets:\n\n",
+	"history.set_weight": "• %s: %.1f kg × %d\n  %s\n",
+	"history.set_reps":   "• %s: %s\n  %s\n",
+
+	"stats.no_data": "No data for stats yet",
+	"stats.error":   "Couldn't load your stats. Please try again later.",
+	"stats.caption": "Progress of your latest exercise over 90 days: average weight and reps per day",
+
+	"volume.usage": "Usage: /volume [number of weeks from 1 to 26]",
+	"volume.error": "Couldn't calculate volume. Please try again later.",
+	"volume.empty": "No sets in this period. Use /add to log a set!",
+	"volume.title": "💪 Volume this week (target %d–%d hard sets):\n\n",
+	"volume.note":  "\nSecondary muscle groups count as half a set, warm-up sets are not counted.",
+
+	"compare.usage":          "%s.\nExample: /compare Bench press, Squat, Deadlift 180d\nAdd e1rm to compare as %% of the best result.",
+	"compare.period_range":   "the period must be from 7 to 3650 days",
+	"compare.min_two":        "list at least two exercises separated by commas",
+	"compare.max_six":        "you can compare at most six exercises",
+	"compare.error":          "Couldn't build the chart. Please try again later.",
+	"compare.not_found":      "%s — not found",
+	"compare.too_few":        "%s — fewer than two workouts",
+	"compare.caption_e1rm":   "📈 e1RM as %% of the best result over %s",
+	"compare.caption_change": "📈 Strength change since the first workout over %s, %%",
+	"compare.missing":        "\n\nNot shown:\n• ",
+
+	"calendar.usage":   "Usage: /calendar [year] [sets], for example /calendar 2025",
+	"calendar.error":   "Couldn't build the calendar. Please try again later.",
+	"calendar.caption": "📅 Training days in %d: %d\n🔥 Current streak: %s\n🏆 Longest streak: %s",
+
+	"chart.weight_kg":      "Weight, kg",
+	"chart.reps":           "Reps",
+	"chart.sets":           "Sets",
+	"chart.tonnage_kg":     "Tonnage, kg",
+	"chart.tonnage":        "Tonnage",
+	"chart.e1rm_kg":        "e1RM, kg",
+	"chart.max_reps":       "Max reps",
+	"chart.volume_title":   "Hard sets per week by muscle group",
+	"chart.compare_change": "Change since start, %",
+	"chart.compare_e1rm":   "e1RM of best, %",
+
+	"report.usage":             "Usage: /report week, /report month or /report year",
+	"report.error":             "Couldn't build the report. Please try again later.",
+	"report.header":            "📋 %s: %s — %s\n\n",
+	"report.title.week":        "Weekly report",
+	"report.title.month":       "Monthly report",
+	"report.title.year":        "Yearly report",
+	"report.previous.week":     "with last week",
+	"report.previous.month":    "with last month",
+	"report.previous.year":     "with last year",
+	"report.previous_in.week":  "Last week",
+	"report.previous_in.month": "Last month",
+	"report.previous_in.year":  "Last year",
+	"report.empty":             "No sets in this period.",
+	"report.empty_previous":    " %s you had %d workouts — keep it up!",
+	"report.sessions":          "Workouts: %d%s\n",
+	"report.sets":              "Sets: %d%s\n",
+	"report.reps":              "Reps: %d%s\n",
+	"report.tonnage":           "Tonnage: %.0f kg%s\n",
+	"report.compare":           "\nCompared %s.\n",
+	"report.bests":             "\n🏆 Best results:\n",
+	"report.best_weight":       "• %s: %.1f kg, e1RM %.1f kg, sets %d\n",
+	"report.best_reps":         "• %s: up to %d reps, sets %d\n",
+	"report.new":               " (new)",
+
+	"export.only_csv": "Only CSV is supported for now: /export csv",
+	"export.caption":  "📤 All your sets.\nColumns: %s",
+	"export.error":    "Couldn't export your data. Please try again later.",
+
+	"mydata.error":      "Couldn't load your data. Please try again later.",
+	"mydata.nothing":    "The bot stores nothing about you.",
+	"mydata.title":      "🔐 What the bot stores about you:\n\n",
+	"mydata.id":         "Telegram ID: %d\n",
+	"mydata.name":       "Name (username, first and last name from Telegram): %q\n",
+	"mydata.registered": "Registered: %s\n",
+	"mydata.language":   "Language: %s\n",
+	"mydata.sets":       "Sets: %d\n",
+	"mydata.custom":     "Custom exercises: %d\n",
+	"mydata.first":      "First activity: %s\n",
+	"mydata.last":       "Last activity: %s\n",
+	"mydata.footer":     "\nDownload your data: /export csv or /backup\nDelete your account and all data: /deleteme",
+
+	"delete.confirm":   "🗑 Yes, delete everything",
+	"delete.cancel":    "Cancel",
+	"delete.prompt":    "⚠️ Your profile, all sets and custom exercises will be permanently deleted.\nYou can download your data first: /backup\n\nDelete everything?",
+	"delete.cancelled": "Deletion cancelled.",
+	"delete.error":     "Couldn't delete your data, nothing was changed. Please try again later.",
+	"delete.done":      "Your account and all data have been deleted. Tap /start to begin again.",
+
+	"token.private":      "Tokens are only issued in a private chat with the bot.",
+	"token.disabled":     "The HTTP API has been disabled by the administrator.",
+	"token.revoke_error": "Couldn't revoke the token. Please try again later.",
+	"token.revoked":      "Token revoked, requests with it no longer work.",
+	"token.issue_error":  "Couldn't issue a token. Please try again later.",
+	"token.issued": "🔑 Your HTTP API token:\n\n<code>%s</code>\n\n" +
+		"Pass it in the header Authorization: Bearer &lt;token&gt;. " +
+		"The token is shown only once, the previous token no longer works. " +
+		"API description: /api/v1/openapi.yaml\n\nRevoke: /token revoke",
+
+	"web.private":        "Dashboard links are only issued in a private chat with the bot.",
+	"web.disabled":       "The web dashboard has been disabled by the administrator.",
+	"web.error":          "Couldn't create a link. Please try again later.",
+	"web.not_configured": "The web dashboard is not configured: the administrator needs to set PUBLIC_URL.",
+	"web.link":           "🌐 Web dashboard login link (single use, valid for 15 minutes):\n%s",
+
+	"web.kg":                  "%s kg",
+	"web.method_not_allowed":  "Method not allowed",
+	"web.internal_error":      "Internal error",
+	"web.not_found.title":     "Not found",
+	"web.not_found":           "This page doesn't exist.",
+	"web.busy.title":          "Server busy",
+	"web.busy":                "The server is taking too long to respond. Try reloading the page in a minute.",
+	"web.failed.title":        "Error",
+	"web.failed":              "Something went wrong. Please try again later.",
+	"web.bad_month":           "The month must be in the format 2006-01.",
+	"web.bad_year":            "The year must be a number from 2000 to %d.",
+	"web.login.title":         "Sign in to the dashboard",
+	"web.login.button":        "Sign in",
+	"web.login.hint":          "The link is single use and valid for 15 minutes.",
+	"web.login.help":          "Send /web to the bot and follow the link from the reply.",
+	"web.login.nav":           "Sign in",
+	"web.login.required":      "To open the dashboard, send /web to the bot and follow the link from the reply.",
+	"web.login.expired":       "This login link has already been used or has expired. Request a new one with /web.",
+	"web.login.expired.title": "Link expired",
+	"web.nav.overview":        "Overview",
+	"web.nav.history":         "History",
+	"web.nav.calendar":        "Calendar",
+	"web.nav.logout":          "Log out",
+
+	"web.col.exercise":   "Exercise",
+	"web.col.max_weight": "Max weight",
+	"web.col.max_reps":   "Max reps",
+	"web.col.sets":       "Sets",
+	"web.col.when":       "When",
+	"web.col.time":       "Time",
+	"web.col.day":        "Day",
+	"web.col.weight":     "Weight",
+	"web.col.reps":       "Reps",
+	"web.col.avg_weight": "Avg weight",
+	"web.col.avg_reps":   "Avg reps",
+	"web.col.tonnage":    "Tonnage",
+
+	"web.dashboard.sessions":    "workouts",
+	"web.dashboard.sets":        "sets",
+	"web.dashboard.reps":        "reps",
+	"web.dashboard.tonnage":     "kg tonnage",
+	"web.dashboard.period":      "From %s to %s",
+	"web.dashboard.records":     "Personal records",
+	"web.dashboard.empty":       "No sets yet. Log your first one in the bot with /add.",
+	"web.dashboard.recent":      "Recent sets",
+	"web.dashboard.all_history": "Full history →",
+
+	"web.history.earlier": "← Earlier",
+	"web.history.later":   "Later →",
+	"web.history.summary": "Workouts: %d, sets: %d",
+	"web.history.day":     "%d reps · %s kg",
+	"web.history.empty":   "No workouts this month.",
+
+	"web.exercise.primary":   "Primary muscles: %s",
+	"web.exercise.secondary": "Secondary: %s",
+	"web.exercise.no_chart":  "The chart needs at least two training days in the period.",
+	"web.period.month":       "Month",
+	"web.period.quarter":     "3 months",
+	"web.period.half_year":   "6 months",
+	"web.period.year":        "Year",
+	"web.period.all":         "All time",
+
+	"web.calendar.by_tonnage":    "by tonnage",
+	"web.calendar.by_sets":       "by sets",
+	"web.calendar.training_days": "training days in %d",
+	"web.calendar.current":       "current streak, days",
+	"web.calendar.longest":       "longest streak, days",
+
+	"backup.error":   "Couldn't create a backup. Please try again later.",
+	"backup.caption": "💾 Backup of all your data. Restore: /restore",
+
+	"restore.prompt":      "Send a backup file created with /backup. The data will be merged with your current data without duplicates.",
+	"restore.too_large":   "The file is too large for a backup.",
+	"restore.error":       "Couldn't restore the backup, nothing was changed. Please try again later.",
+	"restore.bad_json":    "This doesn't look like a backup: couldn't read JSON.",
+	"restore.not_backup":  "This doesn't look like a backup created with /backup.",
+	"restore.bad_version": "Backup version %d is not supported by this version of the bot.",
+	"restore.no_name":     "The backup contains an exercise without a name.",
+	"restore.bad_set":     "The backup contains an invalid set.",
+	"restore.done":        "✅ Backup from %s restored.\nSets added: %d, already present: %d.",
+
+	"import.not_csv":        "Send a CSV export from Strong, Hevy, FitNotes or /export csv.\nTo restore a backup, send /restore first.",
+	"import.too_large":      "The file is too large (10 MB max).",
+	"import.parse_error":    "Couldn't parse the file: %s",
+	"import.confirm":        "✅ Import",
+	"import.cancel":         "❌ Cancel",
+	"import.error":          "Couldn't import the file, nothing was saved. Please try again later.",
+	"import.cancelled":      "Import cancelled.",
+	"import.pending":        "Please confirm the import first: «%s» or «%s».",
+	"import.done":           "✅ Sets imported: %d, exercises created: %d.\nSee: /history, /report year, /calendar",
+	"import.file":           "📥 %s file\n\n",
+	"import.sets":           "Sets: %d\n",
+	"import.period":         "Period: %s — %s\n",
+	"import.matched":        "Catalog exercises: %d\n",
+	"import.skipped":        "Skipped rows without reps: %d\n",
+	"import.new_exercises":  "\nCustom exercises to be created (%d):\n",
+	"import.more":           "… and %d more\n",
+	"import.prompt":         "\nTap «%s» to save or «%s».",
+	"import.no_sets":        "the file has no sets with reps",
+	"import.bad_header":     "couldn't read the CSV header: %s",
+	"import.unknown_format": "unknown file format: exports from Strong, Hevy, FitNotes and /export csv are supported",
+	"import.line":           "line %d: %s",
+	"import.bad_reps":       "invalid number of reps %q",
+	"import.bad_weight":     "invalid weight %q",
+	"import.bad_date":       "invalid date %q",
+
+	"settings.current":             "⚙️ Settings\n\nLanguage: %s%s\nAnnouncements: %s\n\nChange the language: /settings language ru, /settings language en or /settings language auto to follow Telegram.\nAnnouncements: /settings broadcast off to turn them off, /settings broadcast on to turn them back on.",
+	"settings.auto":                " (from Telegram)",
+	"settings.usage":               "Usage: /settings language ru|en|auto or /settings broadcast on|off",
+	"settings.saved":               "Language set: %s.",
+	"settings.auto_saved":          "The language will follow your Telegram settings, currently: %s.",
+	"settings.error":               "Couldn't save settings. Please try again later.",
+	"settings.broadcast.on":        "on",
+	"settings.broadcast.off":       "off",
+	"settings.broadcast.saved_on":  "📣 Announcements are on.",
+	"settings.broadcast.saved_off": "🔕 Announcements are off. Turn them back on: /settings broadcast on",
+
+	"broadcast.footer": "\n\n—\nTurn off announcements: /settings broadcast off",
+
+	"admin.private": "The admin console only works in a private chat with the bot.",
+	"admin.error":   "The command failed. See the logs for details.",
+	"admin.help": `🛠 Admin console
+
+/admin stats — users, activity and sets per day
+/admin broadcast <text> — announcement to everyone who hasn't opted out
+/admin user <chat_id> — user details
+/admin user <chat_id> ban|unban — ban or unban a user
+/admin exercises — standard exercise catalog`,
+
+	"admin.stats.title":  "📊 Bot stats\n\n",
+	"admin.stats.users":  "Users: %d, new this week: %d\nBanned: %d, opted out of announcements: %d\n",
+	"admin.stats.active": "Active (logged a set) in the last day: %d, week: %d\n",
+	"admin.stats.sets":   "\nSets per day over %d days:\n",
+
+	"admin.user.usage":      "Usage: /admin user <chat_id> or /admin user <chat_id> ban|unban",
+	"admin.user.not_found":  "No user with this chat ID has messaged the bot.",
+	"admin.user.title":      "👤 User %d\n\n",
+	"admin.user.opted_out":  "Opted out of announcements\n",
+	"admin.user.active":     "\nBan: /admin user %d ban",
+	"admin.user.banned":     "\n⛔ Banned. Unban: /admin user %d unban",
+	"admin.user.ban_admin":  "Admins can't be banned.",
+	"admin.user.ban_done":   "⛔ User %d is banned: the bot ignores them, the API and the dashboard are closed to them.",
+	"admin.user.unban_done": "✅ User %d is unbanned.",
+
+	"admin.exercises.title": "📚 Standard exercises:\n\n",
+	"admin.exercises.help": `
+Add: /admin exercises add Name; description; primary muscles; secondary muscles
+Edit: /admin exercises edit <id> Name; description; primary; secondary
+An empty field keeps the current value, "-" clears it
+Delete: /admin exercises delete <id>
+Muscles are comma-separated codes (chest, triceps) or their names in any supported language.
+Only the original exercises have translations: renamed and new ones are shown to everyone as is`,
+	"admin.exercises.usage":          "Usage: /admin exercises add Name; description; primary; secondary, /admin exercises edit <id> … or /admin exercises delete <id>",
+	"admin.exercises.not_found":      "There is no standard exercise with this ID.",
+	"admin.exercises.in_use":         "Sets have already been logged for this exercise, so it can't be deleted. You can rename it.",
+	"admin.exercises.exists":         "A standard exercise with this name already exists.",
+	"admin.exercises.unknown_muscle": "Unknown muscle group %q. Available: %s",
+	"admin.exercises.added":          "✅ Added exercise #%d %s",
+	"admin.exercises.edited":         "✅ Exercise #%d updated: %s",
+	"admin.exercises.deleted":        "🗑 Exercise #%d deleted",
+
+	"admin.broadcast.usage":      "Usage: /admin broadcast <announcement text>",
+	"admin.broadcast.preview":    "📣 Announcement for %d users:\n\n%s\n\nSend it?",
+	"admin.broadcast.confirm":    "📣 Send",
+	"admin.broadcast.cancel":     "Cancel",
+	"admin.broadcast.pending":    "The announcement is waiting for confirmation: tap \"%s\" or \"%s\".",
+	"admin.broadcast.queued":     "The announcement is queued. I'll report back when it's done.",
+	"admin.broadcast.cancelled":  "Announcement cancelled.",
+	"admin.broadcast.queue_full": "Too many announcements are queued, please try again later.",
+	"admin.broadcast.error":      "The announcement wasn't sent: couldn't load the user list.",
+	"admin.broadcast.done":       "📣 Announcement finished: delivered %d, unreachable %d, failed %d.",
+}
+
+var enPlurals = map[string][]string{
+	"reps":    {"%d rep", "%d reps"},
+	"sets":    {"%d set", "%d sets"},
+	"times":   {"%d rep", "%d reps"},
+	"days":    {"%d day", "%d days"},
+	"seconds": {"%d second", "%d seconds"},
+}
+
+// Названия и описания стандартных упражнений по-английски
+var enExercises = map[string]exerciseName{
+	"Приседания":    {Name: "Squat", Description: "Barbell back squat"},
+	"Жим лежа":      {Name: "Bench press", Description: "Barbell bench press"},
+	"Становая тяга": {Name: "Deadlift", Description: "Conventional deadlift"},
+	"Подтягивания":  {Name: "Pull-ups", Description: "Wide-grip pull-ups"},
+	"Отжимания":     {Name: "Push-ups", Description: "Floor push-ups"},
+	"Жим стоя":      {Name: "Overhead press", Description: "Standing barbell press"},
+	"Тяга штанги":   {Name: "Barbell row", Description: "Bent-over barbell row"},
+	"Бицепс":        {Name: "Biceps curl", Description: "Barbell curl"},
+	"Трицепс":       {Name: "Triceps", Description: "Close-grip bench press"},
+	"Планка":        {Name: "Plank", Description: "Core exercise"},
+}
+
+var enMuscles = map[model.MuscleGroup]string{
+	model.MuscleChest:      "Chest",
+	model.MuscleBack:       "Back",
+	model.MuscleShoulders:  "Shoulders",
+	model.MuscleBiceps:     "Biceps",
+	model.MuscleTriceps:    "Triceps",
+	model.MuscleQuads:      "Quads",
+	model.MuscleHamstrings: "Hamstrings",
+	model.MuscleGlutes:     "Glutes",
+	model.MuscleCore:       "Core",
+}
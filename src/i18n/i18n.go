@@ -0,0 +1,210 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"gofitness/src/model"
+	"strings"
+)
+
+// Locale — язык сообщений бота
+type Locale string
+
+const (
+	RU Locale = "ru"
+	EN Locale = "en"
+)
+
+// Default — язык для пользователей, чей язык неизвестен
+const Default = RU
+
+// Foreign — язык для пользователей с неподдерживаемым языком Telegram
+const Foreign = EN
+
+// Locales — поддерживаемые языки в порядке отображения
+var Locales = []Locale{RU, EN}
+
+var messages = map[Locale]map[string]string{
+	RU: ru,
+	EN: en,
+}
+
+var plurals = map[Locale]map[string][]string{
+	RU: ruPlurals,
+	EN: enPlurals,
+}
+
+// Parse разбирает код языка из настроек: ru или en
+func Parse(code string) (Locale, bool) {
+	loc := Locale(strings.ToLower(strings.TrimSpace(code)))
+	if _, ok := messages[loc]; ok {
+		return loc, true
+	}
+	return "", false
+}
+
+// Detect выбирает язык по language_code из Telegram (ru, en-US, …).
+// Пустой код — язык по умолчанию, любой другой неизвестный — Foreign
+func Detect(languageCode string) Locale {
+	if languageCode == "" {
+		return Default
+	}
+	code, _, _ := strings.Cut(strings.ToLower(languageCode), "-")
+	if loc, ok := Parse(code); ok {
+		return loc
+	}
+	return Foreign
+}
+
+// Name — название языка на нём самом
+func (l Locale) Name() string {
+	return l.T("language.name")
+}
+
+// T возвращает сообщение key; с аргументами оно форматируется как fmt.Sprintf.
+// Если перевода нет, берётся русский текст, а если нет и его — сам ключ
+func (l Locale) T(key string, args ...interface{}) string {
+	msg, ok := messages[l][key]
+	if !ok {
+		if msg, ok = ru[key]; !ok {
+			msg = key
+		}
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// N — число с согласованным словом: «1 повторение», «2 повторения», «5 повторений»
+func (l Locale) N(key string, n int) string {
+	forms, ok := plurals[l][key]
+	if !ok {
+		l, forms = RU, ruPlurals[key]
+	}
+	if len(forms) == 0 {
+		return fmt.Sprintf("%d %s", n, key)
+	}
+
+	index := l.pluralIndex(n)
+	if index >= len(forms) {
+		index = len(forms) - 1
+	}
+	return fmt.Sprintf(forms[index], n)
+}
+
+// Номер формы множественного числа: для русского — одна, несколько, много;
+// для английского — одна и остальные
+func (l Locale) pluralIndex(n int) int {
+	if n < 0 {
+		n = -n
+	}
+	if l != RU {
+		if n == 1 {
+			return 0
+		}
+		return 1
+	}
+
+	switch mod10, mod100 := n%10, n%100; {
+	case mod10 == 1 && mod100 != 11:
+		return 0
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// List — сообщение key, разбитое по запятым: названия дней недели, месяцев
+func (l Locale) List(key string) []string {
+	return strings.Split(l.T(key), ",")
+}
+
+// Error — ошибка, текст которой показывается пользователю на его языке.
+// Error() возвращает русский текст, для логов и обычных обёрток
+type Error struct {
+	Key  string
+	Args []interface{}
+}
+
+// NewError создаёт ошибку с сообщением key; аргументы-ошибки тоже переводятся
+func NewError(key string, args ...interface{}) *Error {
+	return &Error{Key: key, Args: args}
+}
+
+func (e *Error) Error() string {
+	return RU.Error(e)
+}
+
+// Error — текст ошибки для пользователя: ошибки i18n переводятся,
+// остальные возвращаются как есть
+func (l Locale) Error(err error) string {
+	var e *Error
+	if !errors.As(err, &e) {
+		return err.Error()
+	}
+
+	args := make([]interface{}, len(e.Args))
+	for i, arg := range e.Args {
+		if argErr, ok := arg.(error); ok {
+			arg = l.Error(argErr)
+		}
+		args[i] = arg
+	}
+	return l.T(e.Key, args...)
+}
+
+// Exercise — название стандартного упражнения на языке пользователя;
+// личные упражнения показываются как есть
+func (l Locale) Exercise(name string) string {
+	if tr, ok := exerciseNames[l][name]; ok {
+		return tr.Name
+	}
+	return name
+}
+
+// ExerciseDescription — описание стандартного упражнения name на языке пользователя
+func (l Locale) ExerciseDescription(name, description string) string {
+	if tr, ok := exerciseNames[l][name]; ok && tr.Description != "" {
+		return tr.Description
+	}
+	return description
+}
+
+// ExerciseName — название упражнения в каталоге по названию на любом языке:
+// «Bench press» → «Жим лежа». Неизвестные названия возвращаются без изменений
+func ExerciseName(name string) string {
+	for _, names := range exerciseNames {
+		for source, tr := range names {
+			if strings.EqualFold(tr.Name, name) {
+				return source
+			}
+		}
+	}
+	return name
+}
+
+// Muscle — название группы мышц на языке пользователя
+func (l Locale) Muscle(m model.MuscleGroup) string {
+	if title, ok := muscleTitles[l][m]; ok {
+		return title
+	}
+	return m.Title()
+}
+
+// Перевод стандартного упражнения
+type exerciseName struct {
+	Name        string
+	Description string
+}
+
+// Переводы стандартных упражнений по их названию в каталоге;
+// для русского каталог уже на нужном языке
+var exerciseNames = map[Locale]map[string]exerciseName{
+	EN: enExercises,
+}
+
+var muscleTitles = map[Locale]map[model.MuscleGroup]string{
+	EN: enMuscles,
+}
@@ -0,0 +1,363 @@
+package i18n
+
+// Русские сообщения; для отсутствующих в других языках ключей используются они же
+var ru = map[string]string{
+	"language.name": "Русский",
+
+	"layout.date":     "02.01.2006",
+	"layout.datetime": "02.01.2006 15:04",
+	"layout.short":    "02.01 15:04",
+	"layout.day":      "02.01",
+	"layout.chart":    "02.01.06",
+
+	"weekdays.short":  "Пн,Вт,Ср,Чт,Пт,Сб,Вс",
+	"months.short":    "Янв,Фев,Мар,Апр,Май,Июн,Июл,Авг,Сен,Окт,Ноя,Дек",
+	"months.calendar": "янв,фев,мар,апр,май,июн,июл,авг,сен,окт,ноя,дек",
+	"months.full":     "Январь,Февраль,Март,Апрель,Май,Июнь,Июль,Август,Сентябрь,Октябрь,Ноябрь,Декабрь",
+
+	"error.timeout":       "⏳ Сервер сейчас отвечает слишком долго, ничего не изменено. Попробуй ещё раз через минуту.",
+	"error.generic":       "Произошла ошибка. Попробуй позже.",
+	"error.file_download": "Не удалось скачать файл. Попробуй ещё раз.",
+
+	"throttle.text":  "🐢 Слишком много сообщений подряд. Подожди %s и продолжай.",
+	"throttle.heavy": "🐢 Графики и выгрузки можно запрашивать не так часто. Попробуй через %s.",
+
+	"start.text": `🏋️‍♂️ Привет! Я твой фитнес-помощник!
+
+Доступные команды:
+%s
+Пришли CSV из Strong, Hevy или FitNotes, чтобы импортировать историю
+
+Нажми /add чтобы начать тренировку!
+Набираем по всякому! ходж твинс!`,
+
+	"command.add":       "Добавить подход",
+	"command.finish":    "Закончить тренировку",
+	"command.history":   "История тренировок",
+	"command.exercises": "Список упражнений",
+	"command.stats":     "Статистика тренировок",
+	"command.volume":    "Недельный объём по группам мышц",
+	"command.compare":   "Сравнить упражнения: /compare Жим лежа, Приседания 180d",
+	"command.calendar":  "Календарь тренировок за год",
+	"command.report":    "Отчёт за неделю (week), месяц (month) или год (year)",
+	"command.export":    "Выгрузить все подходы в CSV",
+	"command.backup":    "Резервная копия всех данных",
+	"command.restore":   "Восстановить из резервной копии",
+	"command.web":       "Веб-кабинет с графиками и историей",
+	"command.token":     "Личный токен для HTTP API",
+	"command.settings":  "Настройки: язык и рассылки",
+	"command.mydata":    "Какие данные о тебе хранятся",
+	"command.deleteme":  "Удалить аккаунт и все данные",
+	"command.admin":     "Консоль администратора",
+
+	"button.add":     "➕ Подход",
+	"button.history": "📊 История",
+	"button.stats":   "📈 Статистика",
+	"button.finish":  "🏁 Закончить тренировку",
+
+	"add.error":            "Ошибка при получении списка упражнений. Попробуй позже.",
+	"add.choose":           "Выбери упражнение:",
+	"add.no_state":         "Состояние не найдено. Нажми /add чтобы начать.",
+	"add.selected":         "Выбрано: %s. Теперь введи количество повторений (например, 10).",
+	"add.unknown_exercise": "Неизвестное упражнение. Выбери из списка с помощью /add.",
+	"add.bad_reps":         "Пожалуйста, введи положительное число повторений.",
+	"add.enter_weight":     "Отлично! Теперь введи вес (кг, 0 — без веса). Повторений: %d",
+	"add.bad_weight":       "Введи корректный вес (>= 0).",
+	"add.saved":            "Подход сохранён: %s — %s, %.1f кг.\n\nЧто дальше?",
+
+	"finish.empty":   "Сегодня подходов не было. Нажми «➕ Подход», чтобы начать.",
+	"finish.done":    "🏁 Тренировка завершена!\nСегодня: %s, %s.",
+	"finish.tonnage": "\nТоннаж: %.0f кг.",
+	"finish.error":   "Ошибка при подведении итогов тренировки. Попробуй позже.",
+
+	"exercises.error":  "Ошибка при получении упражнений. Попробуй позже.",
+	"exercises.title":  "🏋️ Все упражнения:\n\n",
+	"exercises.custom": " (своё)",
+
+	"history.error":      "Ошибка при получении истории тренировок. Попробуй позже.",
+	"history.empty":      "У тебя пока нет записанных подходов. Используй /add чтобы добавить первый подход!",
+	"history.title":      "📊 Последние подходы:\n\n",
+	"history.set_weight": "• %s: %.1f кг × %d\n  %s\n",
+	"history.set_reps":   "• %s: %s\n  %s\n",
+
+	"stats.no_data": "Пока нет данных для статистики",
+	"stats.error":   "Ошибка при получении статистики. Попробуй позже.",
+	"stats.caption": "Прогресс последнего упражнения за 90 дней: средний вес и повторения по дням",
+
+	"volume.usage": "Используй: /volume [число недель от 1 до 26]",
+	"volume.error": "Ошибка при подсчёте объёма. Попробуй позже.",
+	"volume.empty": "Нет подходов за выбранный период. Используй /add чтобы добавить подход!",
+	"volume.title": "💪 Объём на этой неделе (цель %d–%d тяжёлых подходов):\n\n",
+	"volume.note":  "\nВспомогательная группа мышц считается как полподхода, разминочные подходы не учитываются.",
+
+	"compare.usage":          "%s.\nПример: /compare Жим лежа, Приседания, Становая тяга 180d\nДобавь e1rm, чтобы сравнить в %% от лучшего результата.",
+	"compare.period_range":   "период должен быть от 7 до 3650 дней",
+	"compare.min_two":        "укажи минимум два упражнения через запятую",
+	"compare.max_six":        "можно сравнить не больше шести упражнений",
+	"compare.error":          "Ошибка при построении графика. Попробуй позже.",
+	"compare.not_found":      "%s — не найдено",
+	"compare.too_few":        "%s — меньше двух тренировок",
+	"compare.caption_e1rm":   "📈 e1RM в %% от лучшего результата за %s",
+	"compare.caption_change": "📈 Изменение силы от первой тренировки за %s, %%",
+	"compare.missing":        "\n\nНе показаны:\n• ",
+
+	"calendar.usage":   "Используй: /calendar [год] [sets], например /calendar 2025",
+	"calendar.error":   "Ошибка при построении календаря. Попробуй позже.",
+	"calendar.caption": "📅 Тренировочных дней в %d: %d\n🔥 Текущая серия: %s\n🏆 Самая длинная серия: %s",
+
+	"chart.weight_kg":      "Вес, кг",
+	"chart.reps":           "Повторения",
+	"chart.sets":           "Подходы",
+	"chart.tonnage_kg":     "Тоннаж, кг",
+	"chart.tonnage":        "Тоннаж",
+	"chart.e1rm_kg":        "e1RM, кг",
+	"chart.max_reps":       "Максимум повторений",
+	"chart.volume_title":   "Тяжёлые подходы в неделю по группам мышц",
+	"chart.compare_change": "Изменение от начала, %",
+	"chart.compare_e1rm":   "e1RM от лучшего, %",
+
+	"report.usage":             "Используй: /report week, /report month или /report year",
+	"report.error":             "Ошибка при построении отчёта. Попробуй позже.",
+	"report.header":            "📋 %s: %s — %s\n\n",
+	"report.title.week":        "Отчёт за неделю",
+	"report.title.month":       "Отчёт за месяц",
+	"report.title.year":        "Отчёт за год",
+	"report.previous.week":     "с прошлой неделей",
+	"report.previous.month":    "с прошлым месяцем",
+	"report.previous.year":     "с прошлым годом",
+	"report.previous_in.week":  "На прошлой неделе",
+	"report.previous_in.month": "В прошлом месяце",
+	"report.previous_in.year":  "В прошлом году",
+	"report.empty":             "За этот период подходов нет.",
+	"report.empty_previous":    " %s тренировок было: %d — не сбавляй темп!",
+	"report.sessions":          "Тренировок: %d%s\n",
+	"report.sets":              "Подходов: %d%s\n",
+	"report.reps":              "Повторений: %d%s\n",
+	"report.tonnage":           "Тоннаж: %.0f кг%s\n",
+	"report.compare":           "\nСравнение — %s.\n",
+	"report.bests":             "\n🏆 Лучшие результаты:\n",
+	"report.best_weight":       "• %s: %.1f кг, e1RM %.1f кг, подходов %d\n",
+	"report.best_reps":         "• %s: до %d повторений, подходов %d\n",
+	"report.new":               " (новое)",
+
+	"export.only_csv": "Пока поддерживается только CSV: /export csv",
+	"export.caption":  "📤 Все твои подходы.\nКолонки: %s",
+	"export.error":    "Ошибка при выгрузке данных. Попробуй позже.",
+
+	"mydata.error":      "Ошибка при получении данных. Попробуй позже.",
+	"mydata.nothing":    "Бот ничего о тебе не хранит.",
+	"mydata.title":      "🔐 Что бот хранит о тебе:\n\n",
+	"mydata.id":         "Telegram ID: %d\n",
+	"mydata.name":       "Имя (username, имя и фамилия из Telegram): %q\n",
+	"mydata.registered": "Зарегистрирован: %s\n",
+	"mydata.language":   "Язык: %s\n",
+	"mydata.sets":       "Подходов: %d\n",
+	"mydata.custom":     "Личных упражнений: %d\n",
+	"mydata.first":      "Первая активность: %s\n",
+	"mydata.last":       "Последняя активность: %s\n",
+	"mydata.footer":     "\nСкачать данные: /export csv или /backup\nУдалить аккаунт и все данные: /deleteme",
+
+	"delete.confirm":   "🗑 Да, удалить всё",
+	"delete.cancel":    "Отмена",
+	"delete.prompt":    "⚠️ Будут безвозвратно удалены твой профиль, все подходы и личные упражнения.\nСначала можно скачать данные: /backup\n\nУдалить всё?",
+	"delete.cancelled": "Удаление отменено.",
+	"delete.error":     "Не удалось удалить данные, ничего не изменено. Попробуй позже.",
+	"delete.done":      "Аккаунт и все данные удалены. Чтобы начать заново, нажми /start.",
+
+	"token.private":      "Токен выдаётся только в личном чате с ботом.",
+	"token.disabled":     "HTTP API отключён администратором.",
+	"token.revoke_error": "Не удалось отозвать токен. Попробуй позже.",
+	"token.revoked":      "Токен отозван, запросы с ним больше не работают.",
+	"token.issue_error":  "Не удалось выпустить токен. Попробуй позже.",
+	"token.issued": "🔑 Твой токен для HTTP API:\n\n<code>%s</code>\n\n" +
+		"Передавай его в заголовке Authorization: Bearer &lt;токен&gt;. " +
+		"Токен показывается один раз, прежний токен больше не действует. " +
+		"Описание API: /api/v1/openapi.yaml\n\nОтозвать: /token revoke",
+
+	"web.private":        "Ссылка на кабинет выдаётся только в личном чате с ботом.",
+	"web.disabled":       "Веб-кабинет отключён администратором.",
+	"web.error":          "Не удалось создать ссылку. Попробуй позже.",
+	"web.not_configured": "Веб-кабинет не настроен: администратору нужно задать PUBLIC_URL.",
+	"web.link":           "🌐 Ссылка для входа в веб-кабинет (одноразовая, действует 15 минут):\n%s",
+
+	"web.kg":                  "%s кг",
+	"web.method_not_allowed":  "Метод не поддерживается",
+	"web.internal_error":      "Внутренняя ошибка",
+	"web.not_found.title":     "Не найдено",
+	"web.not_found":           "Такой страницы нет.",
+	"web.busy.title":          "Сервер занят",
+	"web.busy":                "Сервер сейчас отвечает слишком долго. Попробуй обновить страницу через минуту.",
+	"web.failed.title":        "Ошибка",
+	"web.failed":              "Что-то пошло не так. Попробуй позже.",
+	"web.bad_month":           "Месяц указывается в формате 2006-01.",
+	"web.bad_year":            "Год указывается числом от 2000 до %d.",
+	"web.login.title":         "Вход в кабинет",
+	"web.login.button":        "Войти",
+	"web.login.hint":          "Ссылка одноразовая и действует 15 минут.",
+	"web.login.help":          "Отправь боту команду /web и перейди по ссылке из ответа.",
+	"web.login.nav":           "Вход",
+	"web.login.required":      "Чтобы открыть кабинет, отправь боту команду /web и перейди по ссылке из ответа.",
+	"web.login.expired":       "Ссылка входа уже использована или истекла. Запроси новую командой /web.",
+	"web.login.expired.title": "Ссылка устарела",
+	"web.nav.overview":        "Обзор",
+	"web.nav.history":         "История",
+	"web.nav.calendar":        "Календарь",
+	"web.nav.logout":          "Выйти",
+
+	"web.col.exercise":   "Упражнение",
+	"web.col.max_weight": "Макс. вес",
+	"web.col.max_reps":   "Макс. повторений",
+	"web.col.sets":       "Подходов",
+	"web.col.when":       "Когда",
+	"web.col.time":       "Время",
+	"web.col.day":        "День",
+	"web.col.weight":     "Вес",
+	"web.col.reps":       "Повторения",
+	"web.col.avg_weight": "Средний вес",
+	"web.col.avg_reps":   "Среднее повторений",
+	"web.col.tonnage":    "Тоннаж",
+
+	"web.dashboard.sessions":    "тренировок",
+	"web.dashboard.sets":        "подходов",
+	"web.dashboard.reps":        "повторений",
+	"web.dashboard.tonnage":     "кг тоннаж",
+	"web.dashboard.period":      "С %s по %s",
+	"web.dashboard.records":     "Личные рекорды",
+	"web.dashboard.empty":       "Пока нет подходов. Запиши первый в боте командой /add.",
+	"web.dashboard.recent":      "Последние подходы",
+	"web.dashboard.all_history": "Вся история →",
+
+	"web.history.earlier": "← Раньше",
+	"web.history.later":   "Позже →",
+	"web.history.summary": "Тренировок: %d, подходов: %d",
+	"web.history.day":     "%d повт. · %s кг",
+	"web.history.empty":   "В этом месяце тренировок нет.",
+
+	"web.exercise.primary":   "Основные мышцы: %s",
+	"web.exercise.secondary": "Вспомогательные: %s",
+	"web.exercise.no_chart":  "Для графика нужно минимум два тренировочных дня за период.",
+	"web.period.month":       "Месяц",
+	"web.period.quarter":     "3 месяца",
+	"web.period.half_year":   "Полгода",
+	"web.period.year":        "Год",
+	"web.period.all":         "Всё время",
+
+	"web.calendar.by_tonnage":    "по тоннажу",
+	"web.calendar.by_sets":       "по подходам",
+	"web.calendar.training_days": "тренировочных дней в %d",
+	"web.calendar.current":       "текущая серия, дн.",
+	"web.calendar.longest":       "самая длинная серия, дн.",
+
+	"backup.error":   "Ошибка при создании резервной копии. Попробуй позже.",
+	"backup.caption": "💾 Резервная копия всех данных. Восстановить: /restore",
+
+	"restore.prompt":      "Пришли файл резервной копии, созданный командой /backup. Данные объединятся с текущими, дубликаты не появятся.",
+	"restore.too_large":   "Файл слишком большой для резервной копии.",
+	"restore.error":       "Не удалось восстановить резервную копию, ничего не изменено. Попробуй позже.",
+	"restore.bad_json":    "Файл не похож на резервную копию: не удалось прочитать JSON.",
+	"restore.not_backup":  "Файл не похож на резервную копию, созданную командой /backup.",
+	"restore.bad_version": "Версия резервной копии %d не поддерживается этой версией бота.",
+	"restore.no_name":     "В резервной копии есть упражнение без названия.",
+	"restore.bad_set":     "В резервной копии есть некорректный подход.",
+	"restore.done":        "✅ Резервная копия от %s восстановлена.\nДобавлено подходов: %d, уже были: %d.",
+
+	"import.not_csv":        "Пришли CSV-файл экспорта из Strong, Hevy, FitNotes или /export csv.\nДля восстановления резервной копии сначала отправь /restore.",
+	"import.too_large":      "Файл слишком большой (максимум 10 МБ).",
+	"import.parse_error":    "Не удалось разобрать файл: %s",
+	"import.confirm":        "✅ Импортировать",
+	"import.cancel":         "❌ Отмена",
+	"import.error":          "Не удалось импортировать файл, ничего не сохранено. Попробуй позже.",
+	"import.cancelled":      "Импорт отменён.",
+	"import.pending":        "Сначала подтверди импорт: «%s» или «%s».",
+	"import.done":           "✅ Импортировано подходов: %d, создано упражнений: %d.\nПосмотреть: /history, /report year, /calendar",
+	"import.file":           "📥 Файл %s\n\n",
+	"import.sets":           "Подходов: %d\n",
+	"import.period":         "Период: %s — %s\n",
+	"import.matched":        "Упражнений из каталога: %d\n",
+	"import.skipped":        "Пропущено строк без повторений: %d\n",
+	"import.new_exercises":  "\nБудут созданы личные упражнения (%d):\n",
+	"import.more":           "… и ещё %d\n",
+	"import.prompt":         "\nНажми «%s», чтобы сохранить, или «%s».",
+	"import.no_sets":        "в файле нет подходов с повторениями",
+	"import.bad_header":     "не удалось прочитать заголовок CSV: %s",
+	"import.unknown_format": "неизвестный формат файла: поддерживаются экспорты Strong, Hevy, FitNotes и /export csv",
+	"import.line":           "строка %d: %s",
+	"import.bad_reps":       "некорректное количество повторений %q",
+	"import.bad_weight":     "некорректный вес %q",
+	"import.bad_date":       "некорректная дата %q",
+
+	"settings.current":             "⚙️ Настройки\n\nЯзык: %s%s\nРассылки: %s\n\nИзменить язык: /settings language ru, /settings language en или /settings language auto — по языку Telegram.\nРассылки: /settings broadcast off — отключить, /settings broadcast on — включить.",
+	"settings.auto":                " (по языку Telegram)",
+	"settings.usage":               "Используй: /settings language ru|en|auto или /settings broadcast on|off",
+	"settings.saved":               "Язык сохранён: %s.",
+	"settings.auto_saved":          "Язык будет выбираться по настройкам Telegram, сейчас: %s.",
+	"settings.error":               "Не удалось сохранить настройки. Попробуй позже.",
+	"settings.broadcast.on":        "включены",
+	"settings.broadcast.off":       "отключены",
+	"settings.broadcast.saved_on":  "📣 Рассылки включены.",
+	"settings.broadcast.saved_off": "🔕 Рассылки отключены. Включить снова: /settings broadcast on",
+
+	"broadcast.footer": "\n\n—\nОтключить рассылки: /settings broadcast off",
+
+	"admin.private": "Консоль администратора работает только в личном чате с ботом.",
+	"admin.error":   "Команда не выполнена. Подробности в логах.",
+	"admin.help": `🛠 Консоль администратора
+
+/admin stats — пользователи, активность и подходы по дням
+/admin broadcast <текст> — рассылка всем, кто её не отключил
+/admin user <chat_id> — карточка пользователя
+/admin user <chat_id> ban|unban — заблокировать или разблокировать
+/admin exercises — каталог стандартных упражнений`,
+
+	"admin.stats.title":  "📊 Статистика бота\n\n",
+	"admin.stats.users":  "Пользователей: %d, новых за неделю: %d\nЗаблокировано: %d, без рассылок: %d\n",
+	"admin.stats.active": "Активных (записали подход) за сутки: %d, за неделю: %d\n",
+	"admin.stats.sets":   "\nПодходов по дням за %d дн.:\n",
+
+	"admin.user.usage":      "Используй: /admin user <chat_id> или /admin user <chat_id> ban|unban",
+	"admin.user.not_found":  "Пользователь с таким chat ID боту не писал.",
+	"admin.user.title":      "👤 Пользователь %d\n\n",
+	"admin.user.opted_out":  "Рассылки отключены\n",
+	"admin.user.active":     "\nЗаблокировать: /admin user %d ban",
+	"admin.user.banned":     "\n⛔ Заблокирован. Разблокировать: /admin user %d unban",
+	"admin.user.ban_admin":  "Администратора заблокировать нельзя.",
+	"admin.user.ban_done":   "⛔ Пользователь %d заблокирован: бот не отвечает ему, API и веб-кабинет недоступны.",
+	"admin.user.unban_done": "✅ Пользователь %d разблокирован.",
+
+	"admin.exercises.title": "📚 Стандартные упражнения:\n\n",
+	"admin.exercises.help": `
+Добавить: /admin exercises add Название; описание; основные мышцы; вспомогательные
+Изменить: /admin exercises edit <id> Название; описание; основные; вспомогательные
+Пустое поле оставляет прежнее значение, «-» очищает его
+Удалить: /admin exercises delete <id>
+Мышцы — коды через запятую (chest, triceps) или названия (грудь, трицепс).
+Переводы есть только у исходных упражнений: переименованное или новое упражнение показывается всем как есть`,
+	"admin.exercises.usage":          "Используй: /admin exercises add Название; описание; основные; вспомогательные, /admin exercises edit <id> … или /admin exercises delete <id>",
+	"admin.exercises.not_found":      "Стандартного упражнения с таким ID нет.",
+	"admin.exercises.in_use":         "По упражнению уже записаны подходы, удалить его нельзя. Его можно переименовать.",
+	"admin.exercises.exists":         "Стандартное упражнение с таким названием уже есть.",
+	"admin.exercises.unknown_muscle": "Неизвестная группа мышц %q. Доступны: %s",
+	"admin.exercises.added":          "✅ Добавлено упражнение #%d %s",
+	"admin.exercises.edited":         "✅ Упражнение #%d изменено: %s",
+	"admin.exercises.deleted":        "🗑 Упражнение #%d удалено",
+
+	"admin.broadcast.usage":      "Используй: /admin broadcast <текст объявления>",
+	"admin.broadcast.preview":    "📣 Рассылка для %d пользователей:\n\n%s\n\nОтправить?",
+	"admin.broadcast.confirm":    "📣 Отправить",
+	"admin.broadcast.cancel":     "Отмена",
+	"admin.broadcast.pending":    "Рассылка ждёт подтверждения: нажми «%s» или «%s».",
+	"admin.broadcast.queued":     "Рассылка поставлена в очередь. Когда она закончится, пришлю итоги.",
+	"admin.broadcast.cancelled":  "Рассылка отменена.",
+	"admin.broadcast.queue_full": "В очереди слишком много рассылок, попробуй позже.",
+	"admin.broadcast.error":      "Рассылка не отправлена: не удалось получить список пользователей.",
+	"admin.broadcast.done":       "📣 Рассылка завершена: доставлено %d, недоступны %d, ошибок %d.",
+}
+
+// Формы для одного, нескольких (2–4) и многих (5–20) предметов
+var ruPlurals = map[string][]string{
+	"reps":    {"%d повторение", "%d повторения", "%d повторений"},
+	"sets":    {"%d подход", "%d подхода", "%d подходов"},
+	"times":   {"%d раз", "%d раза", "%d раз"},
+	"days":    {"%d день", "%d дня", "%d дней"},
+	"seconds": {"%d секунду", "%d секунды", "%d секунд"},
+}
@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"gofitness/src/database"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Сколько можно не получать ответ на getUpdates, прежде чем считать бота мёртвым.
+// Long polling отвечает не реже раза в несколько секунд, даже без сообщений.
+const pollStaleAfter = 2 * time.Minute
+
+// Время на проверку хранилища в /readyz
+const pingTimeout = 2 * time.Second
+
+// Health следит за связью с Telegram и хранилищем для /healthz и /readyz
+type Health struct {
+	db      database.Storage
+	webhook bool
+	started time.Time
+
+	mu         sync.Mutex
+	lastPoll   time.Time
+	webhookSet bool
+}
+
+// webhook — обновления приходят через вебхук, а не long polling
+func NewHealth(db database.Storage, webhook bool) *Health {
+	return &Health{db: db, webhook: webhook, started: time.Now()}
+}
+
+type transport struct {
+	base   http.RoundTripper
+	health *Health
+}
+
+// Transport оборачивает HTTP-клиент бота: успешные getUpdates и setWebhook
+// означают, что бот получает обновления
+func (h *Health) Transport(base http.RoundTripper) http.RoundTripper {
+	return &transport{base: base, health: h}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	t.health.mu.Lock()
+	switch {
+	case strings.HasSuffix(req.URL.Path, "/getUpdates"):
+		t.health.lastPoll = time.Now()
+	case strings.HasSuffix(req.URL.Path, "/setWebhook"):
+		t.health.webhookSet = true
+	}
+	t.health.mu.Unlock()
+
+	return resp, nil
+}
+
+// Состояние получения обновлений: "ok", "starting" или описание проблемы
+func (h *Health) pollerStatus() (string, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	switch {
+	case h.webhook && h.webhookSet:
+		return "ok", true
+	case !h.webhook && now.Sub(h.lastPoll) < pollStaleAfter:
+		return "ok", true
+	case now.Sub(h.started) < pollStaleAfter:
+		return "starting", true
+	case h.webhook:
+		return "webhook is not registered", false
+	case h.lastPoll.IsZero():
+		return "no successful getUpdates yet", false
+	default:
+		return "no successful getUpdates since " + h.lastPoll.Format(time.RFC3339), false
+	}
+}
+
+func (h *Health) databaseStatus(ctx context.Context) (string, bool) {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	if err := h.db.Ping(ctx); err != nil {
+		return err.Error(), false
+	}
+	return "ok", true
+}
+
+type healthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+func writeHealth(w http.ResponseWriter, checks map[string]string, ok bool) {
+	resp := healthResponse{Status: "ok", Checks: checks}
+	status := http.StatusOK
+	if !ok {
+		resp.Status = "fail"
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// Liveness (/healthz): бот получает обновления от Telegram
+func (h *Health) Liveness(w http.ResponseWriter, r *http.Request) {
+	poller, ok := h.pollerStatus()
+	writeHealth(w, map[string]string{"poller": poller}, ok)
+}
+
+// Readiness (/readyz): доступны и Telegram, и хранилище
+func (h *Health) Readiness(w http.ResponseWriter, r *http.Request) {
+	poller, pollerOK := h.pollerStatus()
+	db, dbOK := h.databaseStatus(r.Context())
+	writeHealth(w, map[string]string{"poller": poller, "database": db}, pollerOK && dbOK)
+}
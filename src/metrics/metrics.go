@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Метрики бота; отдаются на /metrics вместе со стандартными метриками Go и процесса
+var (
+	Updates = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gofitness_updates_total",
+		Help: "Обработанные обновления Telegram по командам.",
+	}, []string{"command"})
+
+	HandlerDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gofitness_handler_duration_seconds",
+		Help:    "Время обработки обновления по командам.",
+		Buckets: []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"command"})
+
+	HandlerErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gofitness_handler_errors_total",
+		Help: "Обработчики, вернувшие ошибку, по командам.",
+	}, []string{"command"})
+
+	DBDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gofitness_db_query_duration_seconds",
+		Help:    "Время операций с хранилищем по методам.",
+		Buckets: []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 5, 30, 120},
+	}, []string{"method"})
+
+	DBErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gofitness_db_errors_total",
+		Help: "Ошибки операций с хранилищем по методам (кроме «не найдено»).",
+	}, []string{"method"})
+
+	ChartDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gofitness_chart_render_duration_seconds",
+		Help:    "Время построения графиков по видам.",
+		Buckets: []float64{.01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"chart"})
+
+	Throttled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gofitness_throttled_updates_total",
+		Help: "Обновления, отброшенные ограничением частоты, по классам команд.",
+	}, []string{"class"})
+
+	OutboundDelay = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "gofitness_outbound_delay_seconds",
+		Help:    "Сколько исходящие сообщения ждали своей очереди из-за лимитов Telegram.",
+		Buckets: []float64{0, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	})
+
+	OutboundFloodWaits = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "gofitness_outbound_flood_waits_total",
+		Help: "Ответы Telegram 429 Too Many Requests, после которых отправка приостанавливалась.",
+	})
+)
+
+// ObserveChart записывает время построения графика; вызывается через defer
+func ObserveChart(chart string, start time.Time) {
+	ChartDuration.WithLabelValues(chart).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,224 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"gofitness/src/database"
+	"gofitness/src/model"
+	"time"
+)
+
+// storage измеряет время и считает ошибки каждой операции с хранилищем
+type storage struct {
+	database.Storage
+}
+
+// WithStorage оборачивает хранилище метриками gofitness_db_*
+func WithStorage(s database.Storage) database.Storage {
+	return &storage{Storage: s}
+}
+
+// Записываем длительность операции; «не найдено» и отказ удалить
+// упражнение с подходами ошибками хранилища не считаем
+func observe(method string, start time.Time, err *error) {
+	DBDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if *err != nil && !errors.Is(*err, sql.ErrNoRows) && !errors.Is(*err, database.ErrExerciseInUse) {
+		DBErrors.WithLabelValues(method).Inc()
+	}
+}
+
+func (s *storage) Ping(ctx context.Context) (err error) {
+	defer observe("Ping", time.Now(), &err)
+	return s.Storage.Ping(ctx)
+}
+
+func (s *storage) GetUserByChatID(ctx context.Context, chatID int64) (user *model.User, err error) {
+	defer observe("GetUserByChatID", time.Now(), &err)
+	return s.Storage.GetUserByChatID(ctx, chatID)
+}
+
+func (s *storage) GetOrCreateUser(ctx context.Context, chatID int64, username string) (user *model.User, err error) {
+	defer observe("GetOrCreateUser", time.Now(), &err)
+	return s.Storage.GetOrCreateUser(ctx, chatID, username)
+}
+
+func (s *storage) SaveUser(ctx context.Context, chatID int64, username string) (user *model.User, err error) {
+	defer observe("SaveUser", time.Now(), &err)
+	return s.Storage.SaveUser(ctx, chatID, username)
+}
+
+func (s *storage) GetAllUsers(ctx context.Context) (users []model.User, err error) {
+	defer observe("GetAllUsers", time.Now(), &err)
+	return s.Storage.GetAllUsers(ctx)
+}
+
+func (s *storage) DeleteUser(ctx context.Context, userID int64) (err error) {
+	defer observe("DeleteUser", time.Now(), &err)
+	return s.Storage.DeleteUser(ctx, userID)
+}
+
+func (s *storage) SetUserLanguage(ctx context.Context, userID int64, language string) (err error) {
+	defer observe("SetUserLanguage", time.Now(), &err)
+	return s.Storage.SetUserLanguage(ctx, userID, language)
+}
+
+func (s *storage) GetExercises(ctx context.Context, userID int64) (exercises []model.Exercise, err error) {
+	defer observe("GetExercises", time.Now(), &err)
+	return s.Storage.GetExercises(ctx, userID)
+}
+
+func (s *storage) GetExerciseByID(ctx context.Context, id int) (exercise *model.Exercise, err error) {
+	defer observe("GetExerciseByID", time.Now(), &err)
+	return s.Storage.GetExerciseByID(ctx, id)
+}
+
+func (s *storage) GetExerciseByName(ctx context.Context, userID int64, name string) (exercise *model.Exercise, err error) {
+	defer observe("GetExerciseByName", time.Now(), &err)
+	return s.Storage.GetExerciseByName(ctx, userID, name)
+}
+
+func (s *storage) GetCustomExercises(ctx context.Context, userID int64) (exercises []model.Exercise, err error) {
+	defer observe("GetCustomExercises", time.Now(), &err)
+	return s.Storage.GetCustomExercises(ctx, userID)
+}
+
+func (s *storage) CountCustomExercises(ctx context.Context, userID int64) (count int, err error) {
+	defer observe("CountCustomExercises", time.Now(), &err)
+	return s.Storage.CountCustomExercises(ctx, userID)
+}
+
+func (s *storage) SaveWorkoutSet(ctx context.Context, userID int64, exerciseID int, weight float64, reps int) (err error) {
+	defer observe("SaveWorkoutSet", time.Now(), &err)
+	return s.Storage.SaveWorkoutSet(ctx, userID, exerciseID, weight, reps)
+}
+
+func (s *storage) ImportWorkoutSets(ctx context.Context, userID int64, newExercises []string, sets []model.WorkoutSet) (err error) {
+	defer observe("ImportWorkoutSets", time.Now(), &err)
+	return s.Storage.ImportWorkoutSets(ctx, userID, newExercises, sets)
+}
+
+func (s *storage) RestoreBackup(ctx context.Context, userID int64, exercises []model.Exercise, sets []model.WorkoutSet) (inserted int, err error) {
+	defer observe("RestoreBackup", time.Now(), &err)
+	return s.Storage.RestoreBackup(ctx, userID, exercises, sets)
+}
+
+func (s *storage) GetUserWorkoutHistory(ctx context.Context, userID int64, limit int) (sets []model.WorkoutSet, err error) {
+	defer observe("GetUserWorkoutHistory", time.Now(), &err)
+	return s.Storage.GetUserWorkoutHistory(ctx, userID, limit)
+}
+
+func (s *storage) StreamWorkoutSets(ctx context.Context, userID int64, fn func(model.WorkoutSet) error) (err error) {
+	defer observe("StreamWorkoutSets", time.Now(), &err)
+	return s.Storage.StreamWorkoutSets(ctx, userID, fn)
+}
+
+func (s *storage) GetWorkoutSetsBetween(ctx context.Context, userID int64, from, to time.Time) (sets []model.WorkoutSet, err error) {
+	defer observe("GetWorkoutSetsBetween", time.Now(), &err)
+	return s.Storage.GetWorkoutSetsBetween(ctx, userID, from, to)
+}
+
+func (s *storage) GetProgressByExercise(ctx context.Context, userID int64, exerciseID int, days int) (points []model.ProgressPoint, err error) {
+	defer observe("GetProgressByExercise", time.Now(), &err)
+	return s.Storage.GetProgressByExercise(ctx, userID, exerciseID, days)
+}
+
+func (s *storage) GetWorkoutSummary(ctx context.Context, userID int64, from, to time.Time) (summary *model.WorkoutSummary, err error) {
+	defer observe("GetWorkoutSummary", time.Now(), &err)
+	return s.Storage.GetWorkoutSummary(ctx, userID, from, to)
+}
+
+func (s *storage) GetExerciseBests(ctx context.Context, userID int64, from, to time.Time) (bests []model.ExerciseBest, err error) {
+	defer observe("GetExerciseBests", time.Now(), &err)
+	return s.Storage.GetExerciseBests(ctx, userID, from, to)
+}
+
+func (s *storage) GetDailyActivity(ctx context.Context, userID int64, from, to time.Time) (days []model.DayActivity, err error) {
+	defer observe("GetDailyActivity", time.Now(), &err)
+	return s.Storage.GetDailyActivity(ctx, userID, from, to)
+}
+
+func (s *storage) GetWeeklyMuscleVolume(ctx context.Context, userID int64, from time.Time) (volumes []model.MuscleVolume, err error) {
+	defer observe("GetWeeklyMuscleVolume", time.Now(), &err)
+	return s.Storage.GetWeeklyMuscleVolume(ctx, userID, from)
+}
+
+func (s *storage) GetWorkoutSet(ctx context.Context, userID int64, setID int) (set *model.WorkoutSet, err error) {
+	defer observe("GetWorkoutSet", time.Now(), &err)
+	return s.Storage.GetWorkoutSet(ctx, userID, setID)
+}
+
+func (s *storage) CreateWorkoutSet(ctx context.Context, set model.WorkoutSet) (created *model.WorkoutSet, err error) {
+	defer observe("CreateWorkoutSet", time.Now(), &err)
+	return s.Storage.CreateWorkoutSet(ctx, set)
+}
+
+func (s *storage) UpdateWorkoutSet(ctx context.Context, set model.WorkoutSet) (err error) {
+	defer observe("UpdateWorkoutSet", time.Now(), &err)
+	return s.Storage.UpdateWorkoutSet(ctx, set)
+}
+
+func (s *storage) DeleteWorkoutSet(ctx context.Context, userID int64, setID int) (err error) {
+	defer observe("DeleteWorkoutSet", time.Now(), &err)
+	return s.Storage.DeleteWorkoutSet(ctx, userID, setID)
+}
+
+func (s *storage) ReplaceAPIToken(ctx context.Context, userID int64, tokenHash string) (err error) {
+	defer observe("ReplaceAPIToken", time.Now(), &err)
+	return s.Storage.ReplaceAPIToken(ctx, userID, tokenHash)
+}
+
+func (s *storage) GetUserByAPIToken(ctx context.Context, tokenHash string) (user *model.User, err error) {
+	defer observe("GetUserByAPIToken", time.Now(), &err)
+	return s.Storage.GetUserByAPIToken(ctx, tokenHash)
+}
+
+func (s *storage) DeleteAPITokens(ctx context.Context, userID int64) (err error) {
+	defer observe("DeleteAPITokens", time.Now(), &err)
+	return s.Storage.DeleteAPITokens(ctx, userID)
+}
+
+func (s *storage) SaveConversations(ctx context.Context, states map[int64][]byte) (err error) {
+	defer observe("SaveConversations", time.Now(), &err)
+	return s.Storage.SaveConversations(ctx, states)
+}
+
+func (s *storage) TakeConversations(ctx context.Context) (states map[int64][]byte, err error) {
+	defer observe("TakeConversations", time.Now(), &err)
+	return s.Storage.TakeConversations(ctx)
+}
+
+func (s *storage) SetUserBanned(ctx context.Context, userID int64, banned bool) (err error) {
+	defer observe("SetUserBanned", time.Now(), &err)
+	return s.Storage.SetUserBanned(ctx, userID, banned)
+}
+
+func (s *storage) SetBroadcastOptOut(ctx context.Context, userID int64, optOut bool) (err error) {
+	defer observe("SetBroadcastOptOut", time.Now(), &err)
+	return s.Storage.SetBroadcastOptOut(ctx, userID, optOut)
+}
+
+func (s *storage) CountActiveUsers(ctx context.Context, since time.Time) (count int, err error) {
+	defer observe("CountActiveUsers", time.Now(), &err)
+	return s.Storage.CountActiveUsers(ctx, since)
+}
+
+func (s *storage) GetTotalDailyActivity(ctx context.Context, from, to time.Time) (days []model.DayActivity, err error) {
+	defer observe("GetTotalDailyActivity", time.Now(), &err)
+	return s.Storage.GetTotalDailyActivity(ctx, from, to)
+}
+
+func (s *storage) CreateStandardExercise(ctx context.Context, exercise model.Exercise) (created *model.Exercise, err error) {
+	defer observe("CreateStandardExercise", time.Now(), &err)
+	return s.Storage.CreateStandardExercise(ctx, exercise)
+}
+
+func (s *storage) UpdateStandardExercise(ctx context.Context, exercise model.Exercise) (err error) {
+	defer observe("UpdateStandardExercise", time.Now(), &err)
+	return s.Storage.UpdateStandardExercise(ctx, exercise)
+}
+
+func (s *storage) DeleteStandardExercise(ctx context.Context, id int) (err error) {
+	defer observe("DeleteStandardExercise", time.Now(), &err)
+	return s.Storage.DeleteStandardExercise(ctx, id)
+}
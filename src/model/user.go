@@ -8,15 +8,23 @@ type User struct {
 	ChatID    int64
 	Username  string
 	CreatedAt time.Time
+	// Язык из /settings; пустой — по языку Telegram
+	Language string
+	// Заблокирован администратором: бот не отвечает на его сообщения
+	Banned bool
+	// Отказался от рассылок администратора
+	BroadcastOptOut bool
 }
 
 type Exercise struct {
-	ID          int
-	Name        string
-	Description string
-	IsStandard  bool
-	UserID      int64
-	CreatedAt   time.Time
+	ID               int
+	Name             string
+	Description      string
+	IsStandard       bool
+	UserID           int64
+	CreatedAt        time.Time
+	PrimaryMuscles   []MuscleGroup
+	SecondaryMuscles []MuscleGroup
 }
 
 type WorkoutSet struct {
@@ -35,4 +43,101 @@ type ProgressPoint struct {
     AvgWeight  float64   `json:"avg_weight"`
     AvgReps    float64   `json:"avg_reps"`
     SetsCount  int       `json:"sets_count"`
-}
\ No newline at end of file
+    BestE1RM   float64   `json:"best_e1rm"` // лучший расчётный разовый максимум за день (Эпли)
+    MaxReps    int       `json:"max_reps"`
+}
+
+// Сводка тренировок за период
+type WorkoutSummary struct {
+	From     time.Time
+	To       time.Time
+	Sessions int // количество тренировочных дней
+	Sets     int
+	Reps     int
+	Tonnage  float64 // сумма вес × повторения
+	FirstAt  time.Time
+	LastAt   time.Time
+}
+
+// Лучшие результаты по упражнению за период
+type ExerciseBest struct {
+	ExerciseID   int
+	ExerciseName string
+	MaxWeight    float64
+	MaxReps      int
+	BestE1RM     float64 // расчётный разовый максимум по формуле Эпли
+	Sets         int
+	Tonnage      float64
+}
+
+// Агрегат тренировок за один день
+type DayActivity struct {
+	Date    time.Time
+	Sets    int
+	Reps    int
+	Tonnage float64
+}
+
+// Группа мышц, которую нагружает упражнение
+type MuscleGroup string
+
+const (
+	MuscleChest      MuscleGroup = "chest"
+	MuscleBack       MuscleGroup = "back"
+	MuscleShoulders  MuscleGroup = "shoulders"
+	MuscleBiceps     MuscleGroup = "biceps"
+	MuscleTriceps    MuscleGroup = "triceps"
+	MuscleQuads      MuscleGroup = "quads"
+	MuscleHamstrings MuscleGroup = "hamstrings"
+	MuscleGlutes     MuscleGroup = "glutes"
+	MuscleCore       MuscleGroup = "core"
+)
+
+// Все группы мышц в порядке отображения
+var MuscleGroups = []MuscleGroup{
+	MuscleChest,
+	MuscleBack,
+	MuscleShoulders,
+	MuscleBiceps,
+	MuscleTriceps,
+	MuscleQuads,
+	MuscleHamstrings,
+	MuscleGlutes,
+	MuscleCore,
+}
+
+var muscleTitles = map[MuscleGroup]string{
+	MuscleChest:      "Грудь",
+	MuscleBack:       "Спина",
+	MuscleShoulders:  "Плечи",
+	MuscleBiceps:     "Бицепс",
+	MuscleTriceps:    "Трицепс",
+	MuscleQuads:      "Квадрицепс",
+	MuscleHamstrings: "Бицепс бедра",
+	MuscleGlutes:     "Ягодицы",
+	MuscleCore:       "Кор",
+}
+
+// Title — название группы мышц для пользователя
+func (m MuscleGroup) Title() string {
+	if title, ok := muscleTitles[m]; ok {
+		return title
+	}
+	return string(m)
+}
+
+// Недельный объём на группу мышц: основная группа — целый подход, вспомогательная — половина
+type MuscleVolume struct {
+	Week   time.Time
+	Muscle MuscleGroup
+	Sets   float64
+}
+
+// Подготовленный импорт истории из CSV, ожидающий подтверждения пользователя
+type ImportPlan struct {
+	Source       string       // формат файла: Strong, Hevy, FitNotes, GoFitness
+	Sets         []WorkoutSet // ExerciseID == 0 — упражнение будет создано при импорте
+	NewExercises []string
+	Matched      int // количество упражнений, найденных в каталоге
+	Skipped      int // строки без повторений (кардио, растяжка) и пустые строки
+}
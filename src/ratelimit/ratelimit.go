@@ -0,0 +1,122 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Как часто убирать вёдра тех, кто давно ничего не присылал
+const sweepEvery = 10 * time.Minute
+
+// Limiter — маркерные вёдра по ключу (пользователю или чату): в ведре
+// до burst маркеров, каждый запрос забирает один, а пополняется ведро
+// со скоростью rate маркеров в секунду
+type Limiter struct {
+	rate  float64
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[int64]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// Последний запрос был отклонён: о задержке уже сообщили
+	denied bool
+}
+
+// Result — решение по одному запросу
+type Result struct {
+	Allowed bool
+	// Через сколько появится следующий маркер
+	RetryAfter time.Duration
+	// Первый отказ после разрешённого запроса: о нём стоит сообщить
+	// пользователю, об остальных — нет, чтобы не отвечать на флуд флудом
+	First bool
+}
+
+// New создаёт ограничитель на perMinute запросов в минуту с запасом burst подряд
+func New(perMinute, burst int) *Limiter {
+	return PerSecond(float64(perMinute)/60, burst)
+}
+
+// PerSecond создаёт ограничитель на rate запросов в секунду с запасом burst подряд
+func PerSecond(rate float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{
+		rate:      rate,
+		burst:     float64(burst),
+		buckets:   make(map[int64]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow забирает маркер, если он есть, и ничего не ждёт
+func (l *Limiter) Allow(key int64) Result {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.bucket(key, time.Now())
+	if b.tokens >= 1 {
+		b.tokens--
+		b.denied = false
+		return Result{Allowed: true}
+	}
+
+	first := !b.denied
+	b.denied = true
+	return Result{RetryAfter: l.delay(1 - b.tokens), First: first}
+}
+
+// Reserve забирает маркер в долг и возвращает, сколько ждать до отправки.
+// Подходит для исходящих запросов: их нельзя отбросить, только отложить
+func (l *Limiter) Reserve(key int64) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.bucket(key, time.Now())
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return l.delay(-b.tokens)
+}
+
+// Ведро ключа с маркерами, накопленными к now; вызывается под l.mu
+func (l *Limiter) bucket(key int64, now time.Time) *bucket {
+	if now.Sub(l.lastSweep) > sweepEvery {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+		return b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+	}
+	b.last = now
+	return b
+}
+
+// Полное ведро ничем не отличается от нового — такие убираем
+func (l *Limiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// Время, за которое накопится tokens маркеров
+func (l *Limiter) delay(tokens float64) time.Duration {
+	return time.Duration(tokens / l.rate * float64(time.Second))
+}
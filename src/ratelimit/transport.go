@@ -0,0 +1,146 @@
+package ratelimit
+
+import (
+	"bytes"
+	"encoding/json"
+	"gofitness/src/metrics"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// В группу Telegram разрешает не больше 20 сообщений в минуту
+const groupPerMinute = 20
+
+// Сколько сообщений подряд можно отправить в один чат без паузы
+const chatBurst = 3
+
+type transport struct {
+	base   http.RoundTripper
+	global *Limiter
+	chats  *Limiter
+	groups *Limiter
+
+	mu sync.Mutex
+	// Telegram ответил 429: до этого момента ничего не отправляем
+	pausedUntil time.Time
+}
+
+// Transport оборачивает HTTP-клиент бота и выдерживает лимиты Telegram на
+// исходящие сообщения: perSecond всего и chatPerMinute в один личный чат.
+// Лишние запросы не отбрасываются, а ждут своей очереди; после ответа
+// 429 отправка приостанавливается на указанное Telegram время
+func Transport(base http.RoundTripper, perSecond, chatPerMinute int) http.RoundTripper {
+	return &transport{
+		base:   base,
+		global: PerSecond(float64(perSecond), perSecond),
+		chats:  New(chatPerMinute, chatBurst),
+		groups: New(groupPerMinute, chatBurst),
+	}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	method := path.Base(req.URL.Path)
+	if !isOutgoingMessage(method) {
+		return t.base.RoundTrip(req)
+	}
+
+	chatID := readChatID(req)
+	start := time.Now()
+	delay := t.global.Reserve(0)
+	switch {
+	case chatID < 0:
+		delay = max(delay, t.groups.Reserve(chatID))
+	case chatID > 0:
+		delay = max(delay, t.chats.Reserve(chatID))
+	}
+	t.mu.Lock()
+	if pause := time.Until(t.pausedUntil); pause > delay {
+		delay = pause
+	}
+	t.mu.Unlock()
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+	metrics.OutboundDelay.Observe(time.Since(start).Seconds())
+
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		t.pause(resp)
+	}
+	return resp, err
+}
+
+// Методы, на которые действуют лимиты сообщений
+func isOutgoingMessage(method string) bool {
+	return strings.HasPrefix(method, "send") && method != "sendChatAction" ||
+		strings.HasPrefix(method, "editMessage") ||
+		method == "copyMessage" || method == "forwardMessage"
+}
+
+// Достаёт chat_id из JSON-запроса. Тело не трогаем: читаем копию через
+// GetBody, которую http.NewRequest готовит для тел в памяти. Загрузки файлов
+// (multipart) telebot отдаёт потоком через io.Pipe — их не читаем вовсе,
+// иначе выгрузка и резервная копия целиком осели бы в памяти на время
+// ожидания лимита. Для них действует только общий лимит, а частоту в один
+// чат и так сдерживает входящий лимит тяжёлых команд.
+// 0 — чат не числовой (например, @channel), не указан или запрос с файлом
+func readChatID(req *http.Request) int64 {
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if mediaType != "application/json" || req.GetBody == nil {
+		return 0
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return 0
+	}
+	defer body.Close()
+
+	var payload struct {
+		ChatID json.RawMessage `json:"chat_id"`
+	}
+	if json.NewDecoder(body).Decode(&payload) != nil {
+		return 0
+	}
+	id, _ := strconv.ParseInt(strings.Trim(string(payload.ChatID), `"`), 10, 64)
+	return id
+}
+
+// Ответ 429: Telegram сообщает, сколько секунд ждать, в parameters.retry_after
+func (t *transport) pause(resp *http.Response) {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+
+	var payload struct {
+		Parameters struct {
+			RetryAfter int `json:"retry_after"`
+		} `json:"parameters"`
+	}
+	if json.Unmarshal(body, &payload) != nil || payload.Parameters.RetryAfter <= 0 {
+		return
+	}
+
+	until := time.Now().Add(time.Duration(payload.Parameters.RetryAfter) * time.Second)
+	t.mu.Lock()
+	if until.After(t.pausedUntil) {
+		t.pausedUntil = until
+	}
+	t.mu.Unlock()
+	metrics.OutboundFloodWaits.Inc()
+}
@@ -0,0 +1,348 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"gofitness/src/database"
+	"gofitness/src/i18n"
+	"gofitness/src/model"
+	"strings"
+	"time"
+)
+
+// Ошибки команд администратора; текст — сообщение каталога i18n
+var (
+	ErrUserNotFound     = i18n.NewError("admin.user.not_found")
+	ErrExerciseNotFound = i18n.NewError("admin.exercises.not_found")
+	ErrExerciseInUse    = i18n.NewError("admin.exercises.in_use")
+	ErrExerciseExists   = i18n.NewError("admin.exercises.exists")
+	ErrExerciseSpec     = i18n.NewError("admin.exercises.usage")
+)
+
+// Сколько дней показывать в графике подходов /admin stats
+const statsDays = 14
+
+// Ширина столбика в графике подходов
+const barWidth = 12
+
+// Repository — хранилище, нужное администраторам: пользователи, каталог упражнений, подходы и статистика
+type Repository interface {
+	database.UserRepository
+	database.ExerciseRepository
+	database.SetRepository
+	database.AdminRepository
+}
+
+type AdminService struct {
+	db Repository
+}
+
+func NewAdminService(db Repository) *AdminService {
+	return &AdminService{db: db}
+}
+
+// Stats — пользователи, активность и подходы по дням. Активные — те,
+// кто записал хотя бы один подход за последние сутки или неделю
+func (s *AdminService) Stats(ctx context.Context, loc i18n.Locale, now time.Time) (string, error) {
+	users, err := s.db.GetAllUsers(ctx)
+	if err != nil {
+		return "", fmt.Errorf("ошибка получения пользователей: %w", err)
+	}
+	var banned, optedOut, newUsers int
+	weekAgo := now.AddDate(0, 0, -7)
+	for _, user := range users {
+		if user.Banned {
+			banned++
+		}
+		if user.BroadcastOptOut {
+			optedOut++
+		}
+		if user.CreatedAt.After(weekAgo) {
+			newUsers++
+		}
+	}
+
+	dau, err := s.db.CountActiveUsers(ctx, now.Add(-24*time.Hour))
+	if err != nil {
+		return "", fmt.Errorf("ошибка подсчёта активных пользователей: %w", err)
+	}
+	wau, err := s.db.CountActiveUsers(ctx, weekAgo)
+	if err != nil {
+		return "", fmt.Errorf("ошибка подсчёта активных пользователей: %w", err)
+	}
+
+	y, m, d := now.Date()
+	from := time.Date(y, m, d, 0, 0, 0, 0, now.Location()).AddDate(0, 0, -(statsDays - 1))
+	days, err := s.db.GetTotalDailyActivity(ctx, from, now.Add(time.Minute))
+	if err != nil {
+		return "", fmt.Errorf("ошибка получения подходов по дням: %w", err)
+	}
+	sets := make(map[string]int, len(days))
+	maxSets := 0
+	for _, day := range days {
+		sets[day.Date.Format("2006-01-02")] = day.Sets
+		maxSets = max(maxSets, day.Sets)
+	}
+
+	var message strings.Builder
+	message.WriteString(loc.T("admin.stats.title"))
+	message.WriteString(loc.T("admin.stats.users", len(users), newUsers, banned, optedOut))
+	message.WriteString(loc.T("admin.stats.active", dau, wau))
+	message.WriteString(loc.T("admin.stats.sets", statsDays))
+	for i := 0; i < statsDays; i++ {
+		day := from.AddDate(0, 0, i)
+		n := sets[day.Format("2006-01-02")]
+		bar := ""
+		if maxSets > 0 {
+			bar = strings.Repeat("▇", (n*barWidth+maxSets-1)/maxSets)
+		}
+		message.WriteString(fmt.Sprintf("%s %s %d\n", day.Format(loc.T("layout.day")), bar, n))
+	}
+	return message.String(), nil
+}
+
+// InspectUser — карточка пользователя по его chat ID
+func (s *AdminService) InspectUser(ctx context.Context, loc i18n.Locale, chatID int64) (string, error) {
+	user, err := s.findUser(ctx, chatID)
+	if err != nil {
+		return "", err
+	}
+
+	summary, err := s.db.GetWorkoutSummary(ctx, user.ID, time.Time{}, time.Now().Add(time.Minute))
+	if err != nil {
+		return "", fmt.Errorf("ошибка получения сводки: %w", err)
+	}
+
+	var message strings.Builder
+	message.WriteString(loc.T("admin.user.title", user.ChatID))
+	message.WriteString(loc.T("mydata.name", user.Username))
+	if !user.CreatedAt.IsZero() {
+		message.WriteString(loc.T("mydata.registered", user.CreatedAt.Format(loc.T("layout.date"))))
+	}
+	if language, ok := i18n.Parse(user.Language); ok {
+		message.WriteString(loc.T("mydata.language", language.Name()))
+	}
+	message.WriteString(loc.T("mydata.sets", summary.Sets))
+	if summary.Sets > 0 {
+		message.WriteString(loc.T("mydata.last", summary.LastAt.Format(loc.T("layout.datetime"))))
+	}
+	if user.BroadcastOptOut {
+		message.WriteString(loc.T("admin.user.opted_out"))
+	}
+	if user.Banned {
+		message.WriteString(loc.T("admin.user.banned", user.ChatID))
+	} else {
+		message.WriteString(loc.T("admin.user.active", user.ChatID))
+	}
+	return message.String(), nil
+}
+
+// SetBanned блокирует пользователя или снимает блокировку
+func (s *AdminService) SetBanned(ctx context.Context, chatID int64, banned bool) error {
+	user, err := s.findUser(ctx, chatID)
+	if err != nil {
+		return err
+	}
+	if err := s.db.SetUserBanned(ctx, user.ID, banned); err != nil {
+		return fmt.Errorf("ошибка блокировки пользователя: %w", err)
+	}
+	return nil
+}
+
+func (s *AdminService) findUser(ctx context.Context, chatID int64) (*model.User, error) {
+	user, err := s.db.GetUserByChatID(ctx, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения пользователя: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// StandardExercises — каталог стандартных упражнений с ID для правки
+func (s *AdminService) StandardExercises(ctx context.Context, loc i18n.Locale) (string, error) {
+	exercises, err := s.db.GetExercises(ctx, 0)
+	if err != nil {
+		return "", fmt.Errorf("ошибка получения упражнений: %w", err)
+	}
+
+	var message strings.Builder
+	message.WriteString(loc.T("admin.exercises.title"))
+	for _, ex := range exercises {
+		if !ex.IsStandard {
+			continue
+		}
+		message.WriteString(fmt.Sprintf("#%d %s", ex.ID, ex.Name))
+		if ex.Description != "" {
+			message.WriteString(fmt.Sprintf(" - %s", ex.Description))
+		}
+		if len(ex.PrimaryMuscles) > 0 {
+			message.WriteString(fmt.Sprintf("\n  💪 %s", muscleCodes(ex.PrimaryMuscles)))
+			if len(ex.SecondaryMuscles) > 0 {
+				message.WriteString(fmt.Sprintf(" (+ %s)", muscleCodes(ex.SecondaryMuscles)))
+			}
+		}
+		message.WriteString("\n")
+	}
+	message.WriteString(loc.T("admin.exercises.help"))
+	return message.String(), nil
+}
+
+// AddExercise добавляет стандартное упражнение по описанию
+// «Название; описание; основные мышцы; вспомогательные мышцы»
+func (s *AdminService) AddExercise(ctx context.Context, spec string) (*model.Exercise, error) {
+	var exercise model.Exercise
+	if err := applySpec(&exercise, spec); err != nil {
+		return nil, err
+	}
+	if exercise.Name == "" {
+		return nil, ErrExerciseSpec
+	}
+	if err := s.checkName(ctx, exercise.Name, 0); err != nil {
+		return nil, err
+	}
+
+	created, err := s.db.CreateStandardExercise(ctx, exercise)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания упражнения: %w", err)
+	}
+	return created, nil
+}
+
+// EditExercise меняет стандартное упражнение; пустые поля описания оставляют
+// прежние значения
+func (s *AdminService) EditExercise(ctx context.Context, id int, spec string) (*model.Exercise, error) {
+	exercise, err := s.db.GetExerciseByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) || err == nil && !exercise.IsStandard {
+		return nil, ErrExerciseNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения упражнения: %w", err)
+	}
+
+	if err := applySpec(exercise, spec); err != nil {
+		return nil, err
+	}
+	if err := s.checkName(ctx, exercise.Name, id); err != nil {
+		return nil, err
+	}
+
+	err = s.db.UpdateStandardExercise(ctx, *exercise)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrExerciseNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка изменения упражнения: %w", err)
+	}
+	return exercise, nil
+}
+
+// DeleteExercise удаляет стандартное упражнение, по которому нет подходов
+func (s *AdminService) DeleteExercise(ctx context.Context, id int) error {
+	err := s.db.DeleteStandardExercise(ctx, id)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrExerciseNotFound
+	case errors.Is(err, database.ErrExerciseInUse):
+		return ErrExerciseInUse
+	case err != nil:
+		return fmt.Errorf("ошибка удаления упражнения: %w", err)
+	}
+	return nil
+}
+
+// Название стандартного упражнения не должно совпадать с другим стандартным
+func (s *AdminService) checkName(ctx context.Context, name string, id int) error {
+	existing, err := s.db.GetExerciseByName(ctx, 0, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка поиска упражнения: %w", err)
+	}
+	if existing.ID != id {
+		return ErrExerciseExists
+	}
+	return nil
+}
+
+// Разбирает «Название; описание; основные; вспомогательные» в exercise.
+// Пропущенные и пустые поля не меняются, «-» очищает описание и мышцы
+func applySpec(exercise *model.Exercise, spec string) error {
+	fields := strings.Split(spec, ";")
+	if len(fields) > 4 {
+		return ErrExerciseSpec
+	}
+	for i, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if field == "-" {
+			field = ""
+		}
+
+		switch i {
+		case 0:
+			if field == "" {
+				return ErrExerciseSpec
+			}
+			exercise.Name = field
+		case 1:
+			exercise.Description = field
+		case 2, 3:
+			muscles, err := parseMuscles(field)
+			if err != nil {
+				return err
+			}
+			if i == 2 {
+				exercise.PrimaryMuscles = muscles
+			} else {
+				exercise.SecondaryMuscles = muscles
+			}
+		}
+	}
+	return nil
+}
+
+// Группы мышц через запятую: код (chest) или название на любом языке (грудь)
+func parseMuscles(list string) ([]model.MuscleGroup, error) {
+	var muscles []model.MuscleGroup
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		muscle, ok := findMuscle(name)
+		if !ok {
+			return nil, i18n.NewError("admin.exercises.unknown_muscle", name, muscleCodes(model.MuscleGroups))
+		}
+		muscles = append(muscles, muscle)
+	}
+	return muscles, nil
+}
+
+func findMuscle(name string) (model.MuscleGroup, bool) {
+	for _, muscle := range model.MuscleGroups {
+		if name == string(muscle) {
+			return muscle, true
+		}
+		for _, loc := range i18n.Locales {
+			if name == strings.ToLower(loc.Muscle(muscle)) {
+				return muscle, true
+			}
+		}
+	}
+	return "", false
+}
+
+func muscleCodes(muscles []model.MuscleGroup) string {
+	codes := make([]string, 0, len(muscles))
+	for _, muscle := range muscles {
+		codes = append(codes, string(muscle))
+	}
+	return strings.Join(codes, ", ")
+}
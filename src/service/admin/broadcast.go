@@ -0,0 +1,172 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gofitness/src/database"
+	"gofitness/src/i18n"
+	"gofitness/src/logging"
+	"gofitness/src/ratelimit"
+
+	"gopkg.in/telebot.v3"
+)
+
+// Сколько рассылок может ждать своей очереди
+const queueSize = 8
+
+// ErrQueueFull — в очереди уже слишком много рассылок
+var ErrQueueFull = i18n.NewError("admin.broadcast.queue_full")
+
+// Broadcast — объявление администратора для всех пользователей
+type Broadcast struct {
+	Text string
+	// Кому и на каком языке сообщить об итогах
+	AdminChatID int64
+	AdminLocale i18n.Locale
+}
+
+// Broadcaster рассылает объявления по очереди, по одному за раз и не быстрее
+// perSecond сообщений в секунду, чтобы бот успевал отвечать остальным.
+// Заблокированным и отказавшимся от рассылок объявления не приходят
+type Broadcaster struct {
+	bot     *telebot.Bot
+	db      database.UserRepository
+	limiter *ratelimit.Limiter
+	queue   chan Broadcast
+	// Отменяется в Stop: прерывает текущую рассылку
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewBroadcaster(b *telebot.Bot, db database.UserRepository, perSecond int) *Broadcaster {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Broadcaster{
+		bot:     b,
+		db:      db,
+		limiter: ratelimit.PerSecond(float64(perSecond), 1),
+		queue:   make(chan Broadcast, queueSize),
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+}
+
+// Start запускает рассылку в отдельной горутине
+func (b *Broadcaster) Start() {
+	go b.run()
+}
+
+// Stop прерывает текущую рассылку и ждёт её остановки. Рассылки из очереди
+// не сохраняются: после перезапуска их нужно отправить заново
+func (b *Broadcaster) Stop() {
+	b.cancel()
+	<-b.done
+}
+
+// Enqueue ставит рассылку в очередь
+func (b *Broadcaster) Enqueue(broadcast Broadcast) error {
+	select {
+	case b.queue <- broadcast:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Recipients — сколько пользователей получат рассылку
+func (b *Broadcaster) Recipients(ctx context.Context) (int, error) {
+	users, err := b.db.GetAllUsers(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка получения пользователей: %w", err)
+	}
+	count := 0
+	for _, user := range users {
+		if !user.Banned && !user.BroadcastOptOut {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (b *Broadcaster) run() {
+	defer close(b.done)
+
+	for {
+		select {
+		case <-b.ctx.Done():
+			if n := len(b.queue); n > 0 {
+				slog.Warn("Рассылки из очереди не отправлены", "count", n)
+			}
+			return
+		case broadcast := <-b.queue:
+			b.send(broadcast)
+		}
+	}
+}
+
+// Итоги рассылки
+type result struct {
+	sent, blocked, failed int
+}
+
+func (b *Broadcaster) send(broadcast Broadcast) {
+	ctx := logging.With(b.ctx, "admin_id", broadcast.AdminChatID)
+	users, err := b.db.GetAllUsers(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения пользователей для рассылки", "err", err)
+		b.report(broadcast, broadcast.AdminLocale.T("admin.broadcast.error"))
+		return
+	}
+
+	slog.InfoContext(ctx, "Рассылка начата", "users", len(users))
+	var res result
+	for _, user := range users {
+		if user.Banned || user.BroadcastOptOut {
+			continue
+		}
+
+		if delay := b.limiter.Reserve(0); delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				slog.WarnContext(ctx, "Рассылка прервана остановкой бота", "sent", res.sent)
+				return
+			case <-timer.C:
+			}
+		}
+
+		// Вне обновления языка Telegram не знаем, поэтому без /settings — язык по умолчанию
+		loc, ok := i18n.Parse(user.Language)
+		if !ok {
+			loc = i18n.Default
+		}
+		text := broadcast.Text + loc.T("broadcast.footer")
+		_, err := b.bot.Send(telebot.ChatID(user.ChatID), text)
+		switch {
+		case err == nil:
+			res.sent++
+		case errors.Is(err, telebot.ErrBlockedByUser), errors.Is(err, telebot.ErrUserIsDeactivated),
+			errors.Is(err, telebot.ErrNotStartedByUser), errors.Is(err, telebot.ErrChatNotFound):
+			res.blocked++
+		default:
+			res.failed++
+			slog.WarnContext(ctx, "Ошибка отправки рассылки", "user_id", user.ChatID, "err", err)
+		}
+	}
+
+	slog.InfoContext(ctx, "Рассылка завершена", "sent", res.sent, "blocked", res.blocked, "failed", res.failed)
+	b.report(broadcast, broadcast.AdminLocale.T("admin.broadcast.done", res.sent, res.blocked, res.failed))
+}
+
+// Сообщаем администратору об итогах рассылки
+func (b *Broadcaster) report(broadcast Broadcast, text string) {
+	if _, err := b.bot.Send(telebot.ChatID(broadcast.AdminChatID), text); err != nil {
+		slog.Warn("Не удалось сообщить итоги рассылки", "admin_id", broadcast.AdminChatID, "err", err)
+	}
+}
@@ -0,0 +1,193 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"gofitness/src/database"
+	"gofitness/src/i18n"
+	"gofitness/src/model"
+	"io"
+	"time"
+)
+
+// Version — текущая версия формата резервной копии.
+// При несовместимых изменениях формата версия увеличивается,
+// а Restore продолжает принимать все предыдущие версии.
+const Version = 1
+
+// Максимальный размер файла резервной копии
+const MaxFileSize = 20 << 20
+
+// Backup — резервная копия всех данных пользователя
+type Backup struct {
+	App       string     `json:"app"`
+	Version   int        `json:"version"`
+	CreatedAt time.Time  `json:"created_at"`
+	Profile   Profile    `json:"profile"`
+	Exercises []Exercise `json:"exercises"`
+	Sets      []Set      `json:"sets"`
+}
+
+type Profile struct {
+	ChatID    int64     `json:"chat_id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+	// Язык из /settings; в копиях без него язык не меняется
+	Language string `json:"language,omitempty"`
+	// Отказ от рассылок из /settings broadcast off
+	BroadcastOptOut bool `json:"broadcast_opt_out,omitempty"`
+}
+
+// Exercise — личное упражнение пользователя
+type Exercise struct {
+	Name             string              `json:"name"`
+	Description      string              `json:"description,omitempty"`
+	PrimaryMuscles   []model.MuscleGroup `json:"primary_muscles,omitempty"`
+	SecondaryMuscles []model.MuscleGroup `json:"secondary_muscles,omitempty"`
+}
+
+// Set — подход; упражнение указывается по имени, чтобы копию можно было
+// перенести в другую установку бота с другими идентификаторами
+type Set struct {
+	Exercise  string    `json:"exercise"`
+	Weight    float64   `json:"weight"`
+	Reps      int       `json:"reps"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+const appName = "gofitness"
+
+// Repository — хранилище, нужное резервным копиям: профиль, личные упражнения и подходы
+type Repository interface {
+	database.UserRepository
+	database.ExerciseRepository
+	database.SetRepository
+}
+
+type BackupService struct {
+	db Repository
+}
+
+func NewBackupService(db Repository) *BackupService {
+	return &BackupService{db: db}
+}
+
+// Create собирает резервную копию пользователя в JSON
+func (s *BackupService) Create(ctx context.Context, user *model.User) (*bytes.Buffer, error) {
+	backup := Backup{
+		App:       appName,
+		Version:   Version,
+		CreatedAt: time.Now().UTC(),
+		Profile: Profile{
+			ChatID:    user.ChatID,
+			Username:  user.Username,
+			CreatedAt: user.CreatedAt,
+			Language:  user.Language,
+
+			BroadcastOptOut: user.BroadcastOptOut,
+		},
+		Exercises: []Exercise{},
+		Sets:      []Set{},
+	}
+
+	exercises, err := s.db.GetCustomExercises(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения упражнений: %w", err)
+	}
+	for _, ex := range exercises {
+		backup.Exercises = append(backup.Exercises, Exercise{
+			Name:             ex.Name,
+			Description:      ex.Description,
+			PrimaryMuscles:   ex.PrimaryMuscles,
+			SecondaryMuscles: ex.SecondaryMuscles,
+		})
+	}
+
+	err = s.db.StreamWorkoutSets(ctx, user.ID, func(set model.WorkoutSet) error {
+		backup.Sets = append(backup.Sets, Set{
+			Exercise:  set.ExerciseName,
+			Weight:    set.Weight,
+			Reps:      set.Reps,
+			CreatedAt: set.CreatedAt,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения подходов: %w", err)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	encoder := json.NewEncoder(buf)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(backup); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// Restore объединяет резервную копию с данными пользователя.
+// Повторное восстановление того же файла не создаёт дубликатов.
+// Язык из копии применяется, только если пользователь ещё не выбрал свой;
+// отказ от рассылок из копии включается, но согласие из копии его не отменяет.
+func (s *BackupService) Restore(ctx context.Context, loc i18n.Locale, user *model.User, r io.Reader) (string, error) {
+	var backup Backup
+	if err := json.NewDecoder(io.LimitReader(r, MaxFileSize)).Decode(&backup); err != nil {
+		return loc.T("restore.bad_json"), nil
+	}
+	if backup.App != appName {
+		return loc.T("restore.not_backup"), nil
+	}
+	if backup.Version < 1 || backup.Version > Version {
+		return loc.T("restore.bad_version", backup.Version), nil
+	}
+	language, hasLanguage := i18n.Parse(backup.Profile.Language)
+
+	var exercises []model.Exercise
+	for _, ex := range backup.Exercises {
+		if ex.Name == "" {
+			return loc.T("restore.no_name"), nil
+		}
+		exercises = append(exercises, model.Exercise{
+			Name:             ex.Name,
+			Description:      ex.Description,
+			PrimaryMuscles:   ex.PrimaryMuscles,
+			SecondaryMuscles: ex.SecondaryMuscles,
+		})
+	}
+
+	var sets []model.WorkoutSet
+	for _, set := range backup.Sets {
+		if set.Exercise == "" || set.Reps <= 0 || set.Weight < 0 || set.CreatedAt.IsZero() {
+			return loc.T("restore.bad_set"), nil
+		}
+		sets = append(sets, model.WorkoutSet{
+			ExerciseName: set.Exercise,
+			Weight:       set.Weight,
+			Reps:         set.Reps,
+			CreatedAt:    set.CreatedAt,
+		})
+	}
+
+	inserted, err := s.db.RestoreBackup(ctx, user.ID, exercises, sets)
+	if err != nil {
+		return "", fmt.Errorf("ошибка восстановления: %w", err)
+	}
+
+	if hasLanguage && user.Language == "" {
+		if err := s.db.SetUserLanguage(ctx, user.ID, string(language)); err != nil {
+			return "", fmt.Errorf("ошибка сохранения языка: %w", err)
+		}
+		loc = language
+	}
+	if backup.Profile.BroadcastOptOut && !user.BroadcastOptOut {
+		if err := s.db.SetBroadcastOptOut(ctx, user.ID, true); err != nil {
+			return "", fmt.Errorf("ошибка сохранения отказа от рассылок: %w", err)
+		}
+	}
+
+	return loc.T("restore.done",
+		backup.CreatedAt.Local().Format(loc.T("layout.datetime")), inserted, len(sets)-inserted), nil
+}
@@ -1,38 +1,49 @@
 package exercise
 
 import (
+	"context"
 	"fmt"
 	"gofitness/src/database"
+	"gofitness/src/i18n"
+	"gofitness/src/model"
 	"strings"
 
 	"gopkg.in/telebot.v3"
 )
 
 type ExerciseService struct { 
-    db *database.Postgres
+    db database.ExerciseRepository
 }
 
-func NewExerciseService(db *database.Postgres) *ExerciseService {
+func NewExerciseService(db database.ExerciseRepository) *ExerciseService {
     return &ExerciseService{
         db: db,
     }
 }
 
-func (s *ExerciseService) GetExercises() (string, error) {
-    exercises, err := s.db.GetExercises()
+func (s *ExerciseService) GetExercises(ctx context.Context, loc i18n.Locale, user *model.User) (string, error) {
+    exercises, err := s.db.GetExercises(ctx, user.ID)
 
     if err != nil { 
-        fmt.Println(err)
-        return "Ошибка при получение упражнений", err
+        return loc.T("exercises.error"), err
     }
 
     var message strings.Builder
-    message.WriteString("🏋️ Все упражнения:\n\n")
+    message.WriteString(loc.T("exercises.title"))
 
     for _, ex := range exercises {
-        message.WriteString(fmt.Sprintf("• %s", ex.Name))
-        if ex.Description != "" {
-            message.WriteString(fmt.Sprintf(" - %s", ex.Description))
+        message.WriteString(fmt.Sprintf("• %s", loc.Exercise(ex.Name)))
+        if !ex.IsStandard {
+            message.WriteString(loc.T("exercises.custom"))
+        }
+        if description := loc.ExerciseDescription(ex.Name, ex.Description); description != "" {
+            message.WriteString(fmt.Sprintf(" - %s", description))
+        }
+        if len(ex.PrimaryMuscles) > 0 {
+            message.WriteString(fmt.Sprintf("\n  💪 %s", muscleList(loc, ex.PrimaryMuscles)))
+            if len(ex.SecondaryMuscles) > 0 {
+                message.WriteString(fmt.Sprintf(" (+ %s)", muscleList(loc, ex.SecondaryMuscles)))
+            }
         }
 
         message.WriteString("\n")
@@ -40,10 +51,19 @@ func (s *ExerciseService) GetExercises() (string, error) {
     return message.String(), nil
 }
 
-func (s *ExerciseService) ShowExerciseSelection(c telebot.Context) (*telebot.ReplyMarkup, error) {
-    exercises, err := s.db.GetExercises()
+// Названия групп мышц через запятую
+func muscleList(loc i18n.Locale, muscles []model.MuscleGroup) string {
+	titles := make([]string, 0, len(muscles))
+	for _, m := range muscles {
+		titles = append(titles, strings.ToLower(loc.Muscle(m)))
+	}
+	return strings.Join(titles, ", ")
+}
+
+func (s *ExerciseService) ShowExerciseSelection(ctx context.Context, loc i18n.Locale, user *model.User) (*telebot.ReplyMarkup, error) {
+    exercises, err := s.db.GetExercises(ctx, user.ID)
 	if err != nil {
-		return nil, fmt.Errorf("Ошибка при получении списка упражнений")
+		return nil, fmt.Errorf("ошибка получения упражнений: %w", err)
 	}
 
 	menu := &telebot.ReplyMarkup{}
@@ -51,10 +71,10 @@ func (s *ExerciseService) ShowExerciseSelection(c telebot.Context) (*telebot.Rep
 
 	for i := 0; i < len(exercises); i += 2 {
 		var row telebot.Row
-		btn1 := menu.Text(exercises[i].Name)
+		btn1 := menu.Text(loc.Exercise(exercises[i].Name))
 		row = append(row, btn1)
 		if i+1 < len(exercises) {
-			btn2 := menu.Text(exercises[i+1].Name)
+			btn2 := menu.Text(loc.Exercise(exercises[i+1].Name))
 			row = append(row, btn2)
 		}
 		rows = append(rows, row)
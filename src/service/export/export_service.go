@@ -0,0 +1,77 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"gofitness/src/database"
+	"gofitness/src/model"
+	"io"
+	"strconv"
+)
+
+// CSVHeader — колонки CSV-экспорта. Состав и порядок колонок стабилен:
+// новые колонки добавляются только в конец, существующие не переименовываются.
+//
+//	date      — дата подхода, YYYY-MM-DD
+//	time      — время подхода, HH:MM:SS
+//	exercise  — название упражнения
+//	weight_kg — вес в килограммах, десятичный разделитель — точка (0 — без веса)
+//	reps      — количество повторений
+//	e1rm_kg   — расчётный разовый максимум по формуле Эпли (0 — без веса)
+//	set_id    — идентификатор подхода в базе бота
+//
+// Файл в UTF-8 с BOM, чтобы Excel корректно показывал кириллицу.
+var CSVHeader = []string{"date", "time", "exercise", "weight_kg", "reps", "e1rm_kg", "set_id"}
+
+const utf8BOM = "\uFEFF"
+
+type ExportService struct {
+	db database.SetRepository
+}
+
+func NewExportService(db database.SetRepository) *ExportService {
+	return &ExportService{db: db}
+}
+
+// WriteCSV пишет все подходы пользователя в w по мере чтения из базы.
+// Возвращает количество выгруженных подходов.
+func (s *ExportService) WriteCSV(ctx context.Context, user *model.User, w io.Writer) (int, error) {
+	if _, err := io.WriteString(w, utf8BOM); err != nil {
+		return 0, err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(CSVHeader); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err := s.db.StreamWorkoutSets(ctx, user.ID, func(set model.WorkoutSet) error {
+		count++
+		return writer.Write(csvRecord(set))
+	})
+	if err != nil {
+		return count, fmt.Errorf("ошибка выгрузки подходов: %w", err)
+	}
+
+	writer.Flush()
+	return count, writer.Error()
+}
+
+func csvRecord(set model.WorkoutSet) []string {
+	var e1rm float64
+	if set.Weight > 0 {
+		e1rm = set.Weight * (1 + float64(set.Reps)/30)
+	}
+
+	return []string{
+		set.CreatedAt.Format("2006-01-02"),
+		set.CreatedAt.Format("15:04:05"),
+		set.ExerciseName,
+		strconv.FormatFloat(set.Weight, 'f', -1, 64),
+		strconv.Itoa(set.Reps),
+		strconv.FormatFloat(e1rm, 'f', 1, 64),
+		strconv.Itoa(set.ID),
+	}
+}
@@ -3,23 +3,28 @@ package history
 import (
 	"bytes"
 	"fmt"
-	"log"
+	"html"
+	"math"
 	"sort"
 	"time"
 
+	"gofitness/src/i18n"
+	"gofitness/src/metrics"
 	"gofitness/src/model"
 
 	"github.com/wcharczuk/go-chart/v2"
+	"github.com/wcharczuk/go-chart/v2/drawing"
 )
 
 // GenerateProgressChart — строит график прогресса с двумя линиями (PNG)
-func GenerateProgressChart(points []model.ProgressPoint, exerciseName string) (*bytes.Buffer, error) {
+func GenerateProgressChart(loc i18n.Locale, points []model.ProgressPoint, exerciseName string) (*bytes.Buffer, error) {
+	defer metrics.ObserveChart("progress", time.Now())
+
 	if len(points) < 2 {
-		log.Printf("недостаточно данных: %d точек", len(points))
 		return nil, fmt.Errorf("недостаточно данных")
 	}
 
-	// Сортировка
+	points = append([]model.ProgressPoint(nil), points...)
 	sort.Slice(points, func(i, j int) bool {
 		return points[i].Date.Before(points[j].Date)
 	})
@@ -27,81 +32,540 @@ func GenerateProgressChart(points []model.ProgressPoint, exerciseName string) (*
 	var dates []time.Time
 	var weights []float64
 	var reps []float64
+	for _, p := range points {
+		dates = append(dates, p.Date)
+		weights = append(weights, p.AvgWeight)
+		reps = append(reps, p.AvgReps)
+	}
 
-	// Начальная дата
-	startDate := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	graph := chart.Chart{
+		Title: exerciseName,
+		Background: chart.Style{
+			Padding: chart.Box{Top: 50, Left: 20, Right: 20, Bottom: 20},
+		},
+		XAxis: chart.XAxis{
+			ValueFormatter: func(v interface{}) string {
+				return chart.TimeFromFloat64(v.(float64)).Format(loc.T("layout.day"))
+			},
+		},
+		Series: []chart.Series{
+			chart.TimeSeries{
+				Name:    loc.T("chart.weight_kg"),
+				XValues: dates,
+				YValues: weights,
+			},
+			chart.TimeSeries{
+				Name:    loc.T("chart.reps"),
+				YAxis:   chart.YAxisSecondary,
+				XValues: dates,
+				YValues: reps,
+			},
+		},
+	}
 
-	// Заполняем тестовыми данными (7 точек)
-	for i := 0; i < 7; i++ {
-		currentDate := startDate.AddDate(0, 0, i) // +1 день
-		dates = append(dates, currentDate)
+	graph.Elements = []chart.Renderable{chart.Legend(&graph)}
 
-		// Пример значений (можно заменить на реальные)
-		weight := 100.0 + float64(i*5)  // 100, 105, 110, ...
-		rep := 8.0 + float64(i)         // 8, 9, 10, ...
+	buf := bytes.NewBuffer([]byte{})
+	if err := graph.Render(chart.PNG, buf); err != nil {
+		return nil, fmt.Errorf("ошибка рендеринга: %w", err)
+	}
 
-		weights = append(weights, weight)
-		reps = append(reps, rep)
+	return buf, nil
+}
 
-		// Выводим каждую итерацию
-		log.Printf("Итерация %d: дата = %s, вес = %.1f, повторения = %.1f",
-			i, currentDate.Format("02.01.2006"), weight, rep)
-	}
+// GenerateProgressChartSVG — график силы по дням (e1RM, а без веса — максимум
+// повторений) и тоннажа на второй оси, в SVG для веб-кабинета
+func GenerateProgressChartSVG(loc i18n.Locale, points []model.ProgressPoint, exerciseName string) (*bytes.Buffer, error) {
+	defer metrics.ObserveChart("progress_svg", time.Now())
 
-	// Выводим весь слайс dates в конце
-	log.Println("=== Все dates ===")
-	for i, d := range dates {
-		log.Printf("dates[%d] = %s", i, d.Format("02.01.2006"))
+	if len(points) < 2 {
+		return nil, fmt.Errorf("недостаточно данных")
 	}
 
-	log.Println("=== weights ===")
-	log.Printf("%v", weights)
+	points = append([]model.ProgressPoint(nil), points...)
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Date.Before(points[j].Date)
+	})
 
-	log.Println("=== reps ===")
-	log.Printf("%v", reps)
+	byWeight := strengthByWeight(points)
+	strengthName := loc.T("chart.e1rm_kg")
+	if !byWeight {
+		strengthName = loc.T("chart.max_reps")
+	}
 
-	// Отладка
-	log.Println("=== Отладка данных ===")
-	log.Printf("Точек: %d", len(dates))
-	for i := 0; i < len(dates); i++ {
-		log.Printf("[%d] %s | вес=%.1f | повторы=%.1f", i, dates[i].Format("02.01"), weights[i], reps[i])
+	var dates, strengthDates []time.Time
+	var strength, volume []float64
+	for _, p := range points {
+		dates = append(dates, p.Date)
+		volume = append(volume, p.TotalVolume)
+		// Дни без веса в силовой серии пропускаем, а не рисуем нулём
+		if v := strengthValue(p, byWeight); v > 0 {
+			strengthDates = append(strengthDates, p.Date)
+			strength = append(strength, v)
+		}
 	}
 
 	graph := chart.Chart{
-		// XAxis: chart.XAxis{
-		// 	TickPosition: chart.TickPositionBetweenTicks,
-		// 	ValueFormatter: func(v interface{}) string {
-		// 		typed := v.(float64)
-		// 		typedDate := chart.TimeFromFloat64(typed)
-		// 		return fmt.Sprintf("%d-%d\n%d", typedDate.Month(), typedDate.Day(), typedDate.Year())
-		// 	},
-		// },
+		// go-chart пишет текст в SVG как есть, а название упражнения задаёт пользователь
+		Title:  html.EscapeString(exerciseName),
+		Width:  900,
+		Height: 400,
+		Background: chart.Style{
+			Padding: chart.Box{Top: 50, Left: 20, Right: 20, Bottom: 20},
+		},
 		XAxis: chart.XAxis{
-			ValueFormatter: chart.TimeHourValueFormatter,
+			ValueFormatter: func(v interface{}) string {
+				return chart.TimeFromFloat64(v.(float64)).Format(loc.T("layout.chart"))
+			},
+		},
+		YAxis: chart.YAxis{
+			Name: strengthName,
+		},
+		YAxisSecondary: chart.YAxis{
+			Name: loc.T("chart.tonnage_kg"),
 		},
 		Series: []chart.Series{
 			chart.TimeSeries{
-				XValues: dates,
-				YValues: reps,
+				Name:    strengthName,
+				XValues: strengthDates,
+				YValues: strength,
+				Style:   chart.Style{StrokeWidth: 2, DotWidth: 3},
 			},
 			chart.TimeSeries{
+				Name:    loc.T("chart.tonnage"),
 				YAxis:   chart.YAxisSecondary,
 				XValues: dates,
-				YValues: weights,
+				YValues: volume,
+				Style:   chart.Style{StrokeWidth: 1, StrokeDashArray: []float64{4, 3}},
 			},
 		},
 	}
-
 	graph.Elements = []chart.Renderable{chart.Legend(&graph)}
 
 	buf := bytes.NewBuffer([]byte{})
-	err := graph.Render(chart.PNG, buf)
-	
+	if err := graph.Render(chart.SVG, buf); err != nil {
+		return nil, fmt.Errorf("ошибка рендеринга: %w", err)
+	}
+
+	return buf, nil
+}
+
+// GenerateBarChart — столбчатая диаграмма (PNG), например тоннаж по дням
+func GenerateBarChart(title string, labels []string, values []float64) (*bytes.Buffer, error) {
+	defer metrics.ObserveChart("bar", time.Now())
+
+	if len(labels) != len(values) {
+		return nil, fmt.Errorf("количество подписей и значений не совпадает")
+	}
+
+	var hasData bool
+	bars := make([]chart.Value, 0, len(values))
+	for i, v := range values {
+		if v > 0 {
+			hasData = true
+		}
+		bars = append(bars, chart.Value{Label: labels[i], Value: v})
+	}
+	if !hasData {
+		return nil, fmt.Errorf("недостаточно данных")
+	}
+
+	barWidth, barSpacing := 40, 20
+	if len(bars) > 12 {
+		barWidth, barSpacing = 16, 6
+	}
+
+	graph := chart.BarChart{
+		Title:    title,
+		Height:   400,
+		Width:      len(bars)*(barWidth+barSpacing) + 150,
+		BarWidth:   barWidth,
+		BarSpacing: barSpacing,
+		Background: chart.Style{
+			Padding: chart.Box{Top: 40},
+		},
+		Bars: bars,
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+	if err := graph.Render(chart.PNG, buf); err != nil {
+		return nil, fmt.Errorf("ошибка рендеринга: %w", err)
+	}
+
+	return buf, nil
+}
+
+// Цвета тепловой карты: от «нет тренировки» до максимальной нагрузки
+var heatmapColors = []drawing.Color{
+	drawing.ColorFromHex("ebedf0"),
+	drawing.ColorFromHex("9be9a8"),
+	drawing.ColorFromHex("40c463"),
+	drawing.ColorFromHex("30a14e"),
+	drawing.ColorFromHex("216e39"),
+}
+
+// GenerateCalendarHeatmap — календарь тренировок за год в стиле GitHub (PNG).
+// Интенсивность клетки — тоннаж дня или количество подходов (bySets).
+func GenerateCalendarHeatmap(loc i18n.Locale, days []model.DayActivity, year int, bySets bool) (*bytes.Buffer, error) {
+	return renderCalendarHeatmap(loc, days, year, bySets, chart.PNG)
+}
+
+// GenerateCalendarHeatmapSVG — тот же календарь в SVG для веб-кабинета
+func GenerateCalendarHeatmapSVG(loc i18n.Locale, days []model.DayActivity, year int, bySets bool) (*bytes.Buffer, error) {
+	return renderCalendarHeatmap(loc, days, year, bySets, chart.SVG)
+}
+
+func renderCalendarHeatmap(loc i18n.Locale, days []model.DayActivity, year int, bySets bool, provider chart.RendererProvider) (*bytes.Buffer, error) {
+	defer metrics.ObserveChart("calendar", time.Now())
+
+	const (
+		cell   = 14
+		gap    = 3
+		left   = 36
+		top    = 44
+		bottom = 16
+	)
+
+	values := make(map[string]float64)
+	var maxValue float64
+	for _, d := range days {
+		if d.Date.Year() != year {
+			continue
+		}
+		v := d.Tonnage
+		if bySets {
+			v = float64(d.Sets)
+		}
+		values[d.Date.Format("2006-01-02")] = v
+		if v > maxValue {
+			maxValue = v
+		}
+	}
+
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, 0)
+	// Колонка — неделя (с понедельника), строка — день недели
+	offset := (int(start.Weekday()) + 6) % 7
+	weeks := (offset + int(end.Sub(start).Hours()/24) + 6) / 7
+
+	width := left + weeks*(cell+gap) + gap
+	height := top + 7*(cell+gap) + bottom
+
+	r, err := provider(width, height)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка рендеринга: %w", err)
+	}
+
+	font, err := chart.GetDefaultFont()
 	if err != nil {
-		log.Printf("ошибка рендеринга: %w", err)
+		return nil, fmt.Errorf("ошибка загрузки шрифта: %w", err)
+	}
+
+	fillRect(r, drawing.ColorWhite, 0, 0, width, height)
+
+	r.SetFont(font)
+	r.SetFontColor(drawing.ColorFromHex("57606a"))
+	r.SetFontSize(11)
+	r.Text(fmt.Sprintf("%d", year), left, 16)
+
+	r.SetFontSize(8)
+	weekdays := loc.List("weekdays.short")
+	for i := 0; i < 3; i++ {
+		y := top + (i*2)*(cell+gap) + cell - 3
+		r.Text(weekdays[i*2], 4, y)
+	}
+
+	months := loc.List("months.calendar")
+
+	for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+		index := offset + int(d.Sub(start).Hours()/24)
+		col, row := index/7, index%7
+		x := left + col*(cell+gap)
+		y := top + row*(cell+gap)
+
+		if d.Day() == 1 {
+			r.SetFontSize(8)
+			r.Text(months[d.Month()-1], x, top-6)
+		}
+
+		level := 0
+		if v := values[d.Format("2006-01-02")]; v > 0 {
+			level = 1 + int(v/maxValue*float64(len(heatmapColors)-2)+0.5)
+			if level >= len(heatmapColors) {
+				level = len(heatmapColors) - 1
+			}
+		} else if _, trained := values[d.Format("2006-01-02")]; trained {
+			// Тренировка без веса при подсчёте по тоннажу — минимальная интенсивность
+			level = 1
+		}
+		fillRect(r, heatmapColors[level], x, y, cell, cell)
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+	if err := r.Save(buf); err != nil {
+		return nil, fmt.Errorf("ошибка рендеринга: %w", err)
+	}
+
+	return buf, nil
+}
+
+func fillRect(r chart.Renderer, color drawing.Color, x, y, w, h int) {
+	r.SetFillColor(color)
+	r.SetStrokeColor(color)
+	r.SetStrokeWidth(0)
+	r.MoveTo(x, y)
+	r.LineTo(x+w, y)
+	r.LineTo(x+w, y+h)
+	r.LineTo(x, y+h)
+	r.Close()
+	r.Fill()
+}
+
+// Цвета групп мышц на графике объёма
+var muscleColors = map[model.MuscleGroup]drawing.Color{
+	model.MuscleChest:      drawing.ColorFromHex("e15759"),
+	model.MuscleBack:       drawing.ColorFromHex("4e79a7"),
+	model.MuscleShoulders:  drawing.ColorFromHex("f28e2b"),
+	model.MuscleBiceps:     drawing.ColorFromHex("76b7b2"),
+	model.MuscleTriceps:    drawing.ColorFromHex("59a14f"),
+	model.MuscleQuads:      drawing.ColorFromHex("edc948"),
+	model.MuscleHamstrings: drawing.ColorFromHex("b07aa1"),
+	model.MuscleGlutes:     drawing.ColorFromHex("ff9da7"),
+	model.MuscleCore:       drawing.ColorFromHex("9c755f"),
+}
+
+// GenerateMuscleVolumeChart — столбцы по неделям, сложенные из подходов на каждую группу мышц (PNG)
+func GenerateMuscleVolumeChart(loc i18n.Locale, volumes []model.MuscleVolume, weeks []time.Time) (*bytes.Buffer, error) {
+	defer metrics.ObserveChart("muscle_volume", time.Now())
+
+	const (
+		barWidth  = 48
+		barGap    = 28
+		left      = 48
+		top       = 40
+		bottom    = 36
+		legendW   = 130
+		plotH     = 320
+		tickCount = 5
+	)
+
+	perWeek := make(map[string]map[model.MuscleGroup]float64)
+	var maxTotal float64
+	for _, v := range volumes {
+		key := v.Week.Format("2006-01-02")
+		if perWeek[key] == nil {
+			perWeek[key] = make(map[model.MuscleGroup]float64)
+		}
+		perWeek[key][v.Muscle] += v.Sets
+	}
+	for _, week := range perWeek {
+		var total float64
+		for _, sets := range week {
+			total += sets
+		}
+		if total > maxTotal {
+			maxTotal = total
+		}
+	}
+	if maxTotal == 0 {
+		return nil, fmt.Errorf("недостаточно данных")
+	}
+
+	step := math.Ceil(maxTotal / tickCount)
+	scaleMax := step * tickCount
+
+	width := left + len(weeks)*(barWidth+barGap) + barGap + legendW
+	if width < 480 {
+		// Чтобы поместился заголовок при малом числе недель
+		width = 480
+	}
+	height := top + plotH + bottom
+
+	r, err := chart.PNG(width, height)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка рендеринга: %w", err)
+	}
+	font, err := chart.GetDefaultFont()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка загрузки шрифта: %w", err)
+	}
+
+	fillRect(r, drawing.ColorWhite, 0, 0, width, height)
+	r.SetFont(font)
+	r.SetFontColor(drawing.ColorFromHex("57606a"))
+	r.SetFontSize(11)
+	r.Text(loc.T("chart.volume_title"), left, 20)
+
+	// Горизонтальная сетка и подписи оси Y
+	r.SetFontSize(8)
+	gridColor := drawing.ColorFromHex("d0d7de")
+	for i := 0; i <= tickCount; i++ {
+		value := step * float64(i)
+		y := top + plotH - int(value/scaleMax*plotH)
+		fillRect(r, gridColor, left, y, len(weeks)*(barWidth+barGap)+barGap, 1)
+		r.Text(fmt.Sprintf("%.0f", value), 8, y+3)
+	}
+
+	for i, week := range weeks {
+		x := left + barGap + i*(barWidth+barGap)
+		y := top + plotH
+		sets := perWeek[week.Format("2006-01-02")]
+		for _, muscle := range model.MuscleGroups {
+			h := int(math.Round(sets[muscle] / scaleMax * plotH))
+			if h == 0 {
+				continue
+			}
+			y -= h
+			fillRect(r, muscleColors[muscle], x, y, barWidth, h)
+		}
+		r.Text(week.Format(loc.T("layout.day")), x+10, top+plotH+16)
+	}
+
+	// Легенда
+	legendX := width - legendW + 8
+	for i, muscle := range model.MuscleGroups {
+		y := top + i*18
+		fillRect(r, muscleColors[muscle], legendX, y, 10, 10)
+		r.Text(loc.Muscle(muscle), legendX+16, y+9)
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+	if err := r.Save(buf); err != nil {
+		return nil, fmt.Errorf("ошибка рендеринга: %w", err)
+	}
+
+	return buf, nil
+}
+
+// CompareMode — как нормировать линии на графике сравнения упражнений
+type CompareMode string
+
+const (
+	CompareChange CompareMode = "pct"  // % изменения от первой тренировки в периоде
+	CompareE1RM   CompareMode = "e1rm" // e1RM в % от лучшего результата за период
+)
+
+// ComparisonSeries — точки прогресса одного упражнения
+type ComparisonSeries struct {
+	Name   string
+	Points []model.ProgressPoint
+}
+
+// Показатель силы выбирается один на всю серию: e1RM, если хоть в одном дне был вес,
+// иначе максимум повторений. Иначе линия скакала бы между килограммами и повторениями
+func strengthByWeight(points []model.ProgressPoint) bool {
+	for _, p := range points {
+		if p.BestE1RM > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Показатель силы за день; 0 — в этот день нет данных для выбранного показателя
+func strengthValue(p model.ProgressPoint, byWeight bool) float64 {
+	if byWeight {
+		return p.BestE1RM
+	}
+	return float64(p.MaxReps)
+}
+
+// GenerateComparisonChart — одна линия на упражнение в одной шкале процентов (PNG)
+func GenerateComparisonChart(loc i18n.Locale, series []ComparisonSeries, mode CompareMode) (*bytes.Buffer, error) {
+	defer metrics.ObserveChart("comparison", time.Now())
+
+	var lines []chart.Series
+	var minDate, maxDate time.Time
+
+	for _, s := range series {
+		if len(s.Points) == 0 {
+			continue
+		}
+
+		byWeight := strengthByWeight(s.Points)
+		var points []model.ProgressPoint
+		for _, p := range s.Points {
+			if strengthValue(p, byWeight) > 0 {
+				points = append(points, p)
+			}
+		}
+		if len(points) == 0 {
+			continue
+		}
+		sort.Slice(points, func(i, j int) bool {
+			return points[i].Date.Before(points[j].Date)
+		})
+
+		base := strengthValue(points[0], byWeight)
+		if mode == CompareE1RM {
+			for _, p := range points {
+				base = math.Max(base, strengthValue(p, byWeight))
+			}
+		}
+
+		var dates []time.Time
+		var values []float64
+		for _, p := range points {
+			dates = append(dates, p.Date)
+			if mode == CompareE1RM {
+				values = append(values, strengthValue(p, byWeight)/base*100)
+			} else {
+				values = append(values, (strengthValue(p, byWeight)/base-1)*100)
+			}
+		}
+
+		if minDate.IsZero() || dates[0].Before(minDate) {
+			minDate = dates[0]
+		}
+		if dates[len(dates)-1].After(maxDate) {
+			maxDate = dates[len(dates)-1]
+		}
+
+		lines = append(lines, chart.TimeSeries{
+			Name:    s.Name,
+			XValues: dates,
+			YValues: values,
+			Style: chart.Style{
+				StrokeWidth: 2,
+				DotWidth:    3,
+			},
+		})
+	}
+
+	if len(lines) == 0 || !maxDate.After(minDate) {
+		return nil, fmt.Errorf("недостаточно данных")
+	}
+
+	yName := loc.T("chart.compare_change")
+	if mode == CompareE1RM {
+		yName = loc.T("chart.compare_e1rm")
+	}
+
+	graph := chart.Chart{
+		Width:  900,
+		Height: 450,
+		Background: chart.Style{
+			Padding: chart.Box{Top: 20, Left: 20, Right: 20, Bottom: 60},
+		},
+		XAxis: chart.XAxis{
+			ValueFormatter: func(v interface{}) string {
+				return chart.TimeFromFloat64(v.(float64)).Format(loc.T("layout.chart"))
+			},
+		},
+		YAxis: chart.YAxis{
+			Name: yName,
+			ValueFormatter: func(v interface{}) string {
+				return fmt.Sprintf("%.0f%%", v.(float64))
+			},
+		},
+		Series: lines,
+	}
+	graph.Elements = []chart.Renderable{chart.Legend(&graph)}
+
+	buf := bytes.NewBuffer([]byte{})
+	if err := graph.Render(chart.PNG, buf); err != nil {
 		return nil, fmt.Errorf("ошибка рендеринга: %w", err)
 	}
 
-	log.Printf("PNG создан, размер: %d байт", buf.Len())
 	return buf, nil
-}
\ No newline at end of file
+}
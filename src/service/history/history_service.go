@@ -2,10 +2,13 @@ package history
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"gofitness/src/database"
+	"gofitness/src/i18n"
+	"gofitness/src/model"
 	"gofitness/src/state"
-	"log"
+	"log/slog"
 	"strconv"
 	"strings"
 	"time"
@@ -13,12 +16,18 @@ import (
 	"gopkg.in/telebot.v3"
 )
 
+// Repository — хранилище, нужное истории и диалогу записи подходов: упражнения и подходы
+type Repository interface {
+	database.ExerciseRepository
+	database.SetRepository
+}
+
 type HistoryService struct { 
-	db *database.Postgres
+	db Repository
 }
 
 // NewHistoryService - конструктор для HistoryService
-func NewHistoryService(db *database.Postgres) *HistoryService {
+func NewHistoryService(db Repository) *HistoryService {
     return &HistoryService{
         db: db,
     }
@@ -29,60 +38,59 @@ var (
 	btnSkipWeight     = telebot.Btn{Text: "➡️ Без веса"}
 )
 
-func (s *HistoryService) GetHistory(chatID int64, username string, countList int) (string, error) { 
-	user, err := s.db.GetOrCreateUser(chatID, username)
-
-	sets, err := s.db.GetUserWorkoutHistory(user.ID, countList)
+func (s *HistoryService) GetHistory(ctx context.Context, loc i18n.Locale, user *model.User, countList int) (string, error) { 
+	sets, err := s.db.GetUserWorkoutHistory(ctx, user.ID, countList)
 	if err != nil {
-		return "Ошибка при получении истории тренировок", nil
+		return "", fmt.Errorf("ошибка получения истории: %w", err)
 	}
 
 	if len(sets) == 0 {
-		return "У тебя пока нет записанных подходов. Используй /add чтобы добавить первый подход!", nil
+		return loc.T("history.empty"), nil
 	}
 
 	var message strings.Builder
-	message.WriteString("📊 Последние подходы:\n\n")
+	message.WriteString(loc.T("history.title"))
 	
 	for _, set := range sets {
-		timeStr := set.CreatedAt.Format("02.01 15:04")
+		timeStr := set.CreatedAt.Format(loc.T("layout.short"))
 		if set.Weight > 0 {
-			message.WriteString(fmt.Sprintf("• %s: %.1f кг × %d\n  %s\n", 
-				set.ExerciseName, set.Weight, set.Reps, timeStr))
+			message.WriteString(loc.T("history.set_weight", 
+				loc.Exercise(set.ExerciseName), set.Weight, set.Reps, timeStr))
 		} else {
-			message.WriteString(fmt.Sprintf("• %s: %d раз\n  %s\n", 
-				set.ExerciseName, set.Reps, timeStr))
+			message.WriteString(loc.T("history.set_reps", 
+				loc.Exercise(set.ExerciseName), loc.N("times", set.Reps), timeStr))
 		}
 	}
 	return message.String(), nil
 }	
 
-func (s *HistoryService) GetUserWorkoutHistory(chatID int64, username string, countList int) (*bytes.Buffer, error) { 
-	user, err := s.db.GetOrCreateUser(chatID, username)
+// ErrNoStats — у пользователя ещё нет подходов для статистики
+var ErrNoStats = i18n.NewError("stats.no_data")
 
+func (s *HistoryService) GetUserWorkoutHistory(ctx context.Context, loc i18n.Locale, user *model.User, countList int) (*bytes.Buffer, error) { 
 	// points, err := historyService.GetProgressPoints(userID, exerciseID, 90) // твоя функция из БД
 	// if err != nil || len(points) < 2 {
 	// 	return c.Send("Недостаточно данных для графика (нужно минимум 2 тренировки)")
 	// }
 
 
-	sets, err := s.db.GetUserWorkoutHistory(user.ID, countList)
+	sets, err := s.db.GetUserWorkoutHistory(ctx, user.ID, countList)
 	if err != nil {
-		return nil, fmt.Errorf("Ошибка при получении статистики")
+		return nil, fmt.Errorf("ошибка получения истории: %w", err)
 	}
 
 	if len(sets) == 0 {
-		return nil,  fmt.Errorf("Пока нет данных для статистики")
+		return nil, ErrNoStats
 	}
 
-	points, err := s.db.GetProgressByExercise(user.ID, sets[0].ExerciseID, 90)
+	points, err := s.db.GetProgressByExercise(ctx, user.ID, sets[0].ExerciseID, 90)
 
 	if err != nil {
-		return nil,  fmt.Errorf(err.Error())
+		return nil, fmt.Errorf("ошибка получения прогресса: %w", err)
 	}
-	buf, err := GenerateProgressChart(points, "Приседания со штангой")
+	buf, err := GenerateProgressChart(loc, points, loc.Exercise(sets[0].ExerciseName))
     if err != nil {
-        log.Printf("Ошибка генерации графика: %v", err)
+        slog.ErrorContext(ctx, "Ошибка генерации графика", "err", err)
         return nil, fmt.Errorf("Ошибка генерации графика")
     }
 	// exerciseCount := make(map[string]int)
@@ -109,73 +117,248 @@ func (s *HistoryService) GetUserWorkoutHistory(chatID int64, username string, co
 	return buf, err
 }
 
-func (s *HistoryService) HandlerStart(chatID int64, username string) (string) {
-	var _, err = s.db.SaveUser(chatID, username)
-	// Сохраняем пользователя в БД
+// GetCalendar — тепловая карта тренировок за год и подпись с сериями тренировочных дней
+func (s *HistoryService) GetCalendar(ctx context.Context, loc i18n.Locale, user *model.User, year int, bySets bool) (*bytes.Buffer, string, error) {
+	// Серии считаем по всей истории, а не только по выбранному году
+	now := time.Now()
+	days, err := s.db.GetDailyActivity(ctx, user.ID, time.Time{}, now.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, "", fmt.Errorf("ошибка получения активности: %w", err)
+	}
+
+	trainingDays := 0
+	for _, d := range days {
+		if d.Date.Year() == year {
+			trainingDays++
+		}
+	}
+
+	buf, err := GenerateCalendarHeatmap(loc, days, year, bySets)
+	if err != nil {
+		return nil, "", err
+	}
+
+	current, longest := TrainingStreaks(days, now)
+	caption := loc.T("calendar.caption",
+		year, trainingDays, loc.N("days", current), loc.N("days", longest),
+	)
+
+	return buf, caption, nil
+}
+
+// Рекомендуемый недельный объём на группу мышц, тяжёлых подходов
+const (
+	targetWeeklySetsMin = 10
+	targetWeeklySetsMax = 20
+)
+
+// GetMuscleVolume — недельный объём по группам мышц за последние weeks недель: текст и график
+func (s *HistoryService) GetMuscleVolume(ctx context.Context, loc i18n.Locale, user *model.User, weeks int) (string, *bytes.Buffer, error) {
+	// Недели начинаются с понедельника, как DATE_TRUNC('week')
+	now := time.Now()
+	y, m, d := now.Date()
+	monday := time.Date(y, m, d-(int(now.Weekday())+6)%7, 0, 0, 0, 0, now.Location())
+	from := monday.AddDate(0, 0, -7*(weeks-1))
+
+	volumes, err := s.db.GetWeeklyMuscleVolume(ctx, user.ID, from)
+	if err != nil {
+		return "", nil, fmt.Errorf("ошибка получения объёма: %w", err)
+	}
+
+	if len(volumes) == 0 {
+		return loc.T("volume.empty"), nil, nil
+	}
+
+	var weekStarts []time.Time
+	for w := from; !w.After(monday); w = w.AddDate(0, 0, 7) {
+		weekStarts = append(weekStarts, w)
+	}
+
+	current := make(map[model.MuscleGroup]float64)
+	for _, v := range volumes {
+		if v.Week.Format("2006-01-02") == monday.Format("2006-01-02") {
+			current[v.Muscle] += v.Sets
+		}
+	}
+
+	var message strings.Builder
+	message.WriteString(loc.T("volume.title", targetWeeklySetsMin, targetWeeklySetsMax))
+	for _, muscle := range model.MuscleGroups {
+		sets := current[muscle]
+		mark := "✅"
+		switch {
+		case sets < targetWeeklySetsMin:
+			mark = "⬇️"
+		case sets > targetWeeklySetsMax:
+			mark = "⬆️"
+		}
+		message.WriteString(fmt.Sprintf("%s %s: %g\n", mark, loc.Muscle(muscle), sets))
+	}
+	message.WriteString(loc.T("volume.note"))
+
+	buf, err := GenerateMuscleVolumeChart(loc, volumes, weekStarts)
 	if err != nil {
-		log.Printf("Failed to save user: %v", err)
+		slog.ErrorContext(ctx, "Ошибка генерации графика объёма", "err", err)
+		return message.String(), nil, nil
+	}
+
+	return message.String(), buf, nil
+}
+
+// ParseCompareArgs разбирает аргументы /compare:
+// «Жим лежа, Приседания, Становая тяга 180d e1rm» — упражнения через запятую,
+// в конце необязательные период в днях и режим (pct или e1rm)
+func ParseCompareArgs(payload string) ([]string, int, CompareMode, error) {
+	days := 90
+	mode := CompareChange
+
+	var names []string
+	parts := strings.Split(payload, ",")
+	for i, part := range parts {
+		fields := strings.Fields(part)
+		if i == len(parts)-1 {
+			// Служебные параметры снимаем с конца последнего упражнения
+			for len(fields) > 0 {
+				last := strings.ToLower(fields[len(fields)-1])
+				if n, err := strconv.Atoi(strings.TrimSuffix(last, "d")); err == nil && strings.HasSuffix(last, "d") {
+					if n < 7 || n > 3650 {
+						return nil, 0, "", i18n.NewError("compare.period_range")
+					}
+					days = n
+				} else if last == string(CompareChange) || last == string(CompareE1RM) {
+					mode = CompareMode(last)
+				} else {
+					break
+				}
+				fields = fields[:len(fields)-1]
+			}
+		}
+		if name := strings.Join(fields, " "); name != "" {
+			names = append(names, name)
+		}
 	}
-	return `🏋️‍♂️ Привет! Я твой фитнес-помощник!
 
-Доступные команды:
-/add - Добавить подход
-/history - История тренировок  
-/exercises - Список упражнений
-/stats - Статистика тренировок
+	if len(names) < 2 {
+		return nil, 0, "", i18n.NewError("compare.min_two")
+	}
+	if len(names) > 6 {
+		return nil, 0, "", i18n.NewError("compare.max_six")
+	}
 
-Нажми /add чтобы начать тренировку!
-Набираем по всякому! ходж твинс!`;
+	return names, days, mode, nil
+}
+
+// CompareExercises — график сравнения прогресса нескольких упражнений за days дней.
+// Стандартные упражнения можно называть на любом поддерживаемом языке
+func (s *HistoryService) CompareExercises(ctx context.Context, loc i18n.Locale, user *model.User, names []string, days int, mode CompareMode) (*bytes.Buffer, string, error) {
+	var series []ComparisonSeries
+	var missing []string
+	for _, name := range names {
+		exercise, err := s.db.GetExerciseByName(ctx, user.ID, i18n.ExerciseName(name))
+		if err != nil {
+			missing = append(missing, loc.T("compare.not_found", name))
+			continue
+		}
+
+		points, err := s.db.GetProgressByExercise(ctx, user.ID, exercise.ID, days)
+		if err != nil {
+			return nil, "", fmt.Errorf("ошибка получения прогресса: %w", err)
+		}
+		if len(points) < 2 {
+			missing = append(missing, loc.T("compare.too_few", loc.Exercise(exercise.Name)))
+			continue
+		}
+
+		series = append(series, ComparisonSeries{Name: loc.Exercise(exercise.Name), Points: points})
+	}
+
+	var caption strings.Builder
+	if mode == CompareE1RM {
+		caption.WriteString(loc.T("compare.caption_e1rm", loc.N("days", days)))
+	} else {
+		caption.WriteString(loc.T("compare.caption_change", loc.N("days", days)))
+	}
+	if len(missing) > 0 {
+		caption.WriteString(loc.T("compare.missing") + strings.Join(missing, "\n• "))
+	}
+
+	if len(series) == 0 {
+		return nil, caption.String(), nil
+	}
+
+	buf, err := GenerateComparisonChart(loc, series, mode)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка генерации графика сравнения", "err", err)
+		return nil, caption.String(), nil
+	}
+
+	return buf, caption.String(), nil
+}
+
+// HandlerStart возвращает приветствие со списком команд commands
+func (s *HistoryService) HandlerStart(loc i18n.Locale, commands string) (string) {
+	return loc.T("start.text", commands)
+}
+
+// FinishWorkout — итог сегодняшней тренировки: подходы, повторения и тоннаж
+func (s *HistoryService) FinishWorkout(ctx context.Context, loc i18n.Locale, user *model.User) (string, error) {
+	now := time.Now()
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+	summary, err := s.db.GetWorkoutSummary(ctx, user.ID, today, now.Add(time.Minute))
+	if err != nil {
+		return "", fmt.Errorf("ошибка получения итогов: %w", err)
+	}
+
+	if summary.Sets == 0 {
+		return loc.T("finish.empty"), nil
+	}
+
+	message := loc.T("finish.done", loc.N("sets", summary.Sets), loc.N("reps", summary.Reps))
+	if summary.Tonnage > 0 {
+		message += loc.T("finish.tonnage", summary.Tonnage)
+	}
+	return message, nil
 }
 
 // HistoryService
-func (s *HistoryService) SaveHistory(
-    chatID int64,
+func (s *HistoryService) SaveHistory(ctx context.Context, loc i18n.Locale, user *model.User,
     message string,
-	username string,
     state *state.UserState,
 ) (string, error) { 
-	user, err := s.db.GetOrCreateUser(chatID, username)
-    if err != nil {
-        return "", fmt.Errorf("ошибка получения/создания пользователя: %w", err)
-    }
-
     // 2. Обрабатываем в зависимости от состояния
     if state == nil {
-        return "Состояние не найдено. Нажми /add чтобы начать.", nil
+        return loc.T("add.no_state"), nil
     }
 
     if state.WaitingForReps {
         reps, err := strconv.Atoi(message)
         if err != nil || reps <= 0 {
-            return "Пожалуйста, введи положительное число повторений.", nil
+            return loc.T("add.bad_reps"), nil
         }
 
         state.TempReps = reps
         state.WaitingForReps = false
         state.WaitingForWeight = true
 
-        return fmt.Sprintf(
-            "Отлично! Теперь введи вес (кг, 0 — без веса). Повторений: %d",
-            reps,
-        ), nil
+        return loc.T("add.enter_weight", reps), nil
     }
 
     if state.WaitingForWeight {
         weight, err := strconv.ParseFloat(message, 64)
         if err != nil || weight < 0 {
-            return "Введи корректный вес (>= 0).", nil
+            return loc.T("add.bad_weight"), nil
         }
 
         // Сохраняем подход в базу
-        err = s.db.SaveWorkoutSet(user.ID, state.CurrentExerciseID, weight, state.TempReps)
+        err = s.db.SaveWorkoutSet(ctx, user.ID, state.CurrentExerciseID, weight, state.TempReps)
         if err != nil {
             return "", fmt.Errorf("ошибка сохранения подхода: %w", err)
         }
 
 
-        msg := fmt.Sprintf(
-            "Подход сохранён: %s — %d повторений, %.1f кг.",
-            state.CurrentExerciseName, state.TempReps, weight,
+        msg := loc.T("add.saved",
+            loc.Exercise(state.CurrentExerciseName), loc.N("reps", state.TempReps), weight,
         )
 
         // Сбрасываем состояние
@@ -184,17 +367,17 @@ func (s *HistoryService) SaveHistory(
         state.CurrentExerciseID = 0
         state.CurrentExerciseName = ""
 
-        return msg + "\n\nЧто дальше?", nil
+        return msg, nil
     }
 
-	exercises, err := s.db.GetExercises()
+	exercises, err := s.db.GetExercises(ctx, user.ID)
 	if err != nil {
-		return "Ошибка при получении упражнений.", nil
+		return "", fmt.Errorf("ошибка получения упражнений: %w", err)
 	}
 
 	var found bool
 	for _, ex := range exercises {
-		if ex.Name == message {
+		if ex.Name == message || loc.Exercise(ex.Name) == message {
 			state.CurrentExerciseID = ex.ID // Предполагаю, что в модели Exercise есть ID
 			state.CurrentExerciseName = ex.Name
 			state.WaitingForReps = true
@@ -203,14 +386,14 @@ func (s *HistoryService) SaveHistory(
 		}
 	}
 	if !found {
-		return "Неизвестное упражнение. Выбери из списка с помощью /add.", nil
+		return loc.T("add.unknown_exercise"), nil
 	}
 
-	return fmt.Sprintf("Выбрано: %s. Теперь введи количество повторений (например, 10).", message), nil
+	return loc.T("add.selected", message), nil
 }
 
 // Обработчик инлайн-кнопок упражнений
-func SetupInlineHandlers(b *telebot.Bot, db *database.Postgres) {
+func SetupInlineHandlers(b *telebot.Bot, db Repository) {
 	b.Handle(telebot.OnCallback, func(c telebot.Context) error {
 		// user := c.S ender()
 		data := c.Callback().Data
@@ -221,7 +404,7 @@ func SetupInlineHandlers(b *telebot.Bot, db *database.Postgres) {
 			if err != nil {
 				return c.Respond(&telebot.CallbackResponse{Text: "Ошибка выбора упражнения"})
 			}
-			return handleExerciseSelection(c, db, exerciseID)
+			return handleExerciseSelection(context.Background(), c, db, exerciseID)
 		}
 
 		return nil
@@ -229,9 +412,9 @@ func SetupInlineHandlers(b *telebot.Bot, db *database.Postgres) {
 }
 
 // Обработчик выбора упражнения
-func handleExerciseSelection(c telebot.Context, db *database.Postgres, exerciseID int) error {
+func handleExerciseSelection(ctx context.Context, c telebot.Context, db Repository, exerciseID int) error {
 	// user := c.Sender()
-	exercise, err := db.GetExerciseByID(exerciseID)
+	exercise, err := db.GetExerciseByID(ctx, exerciseID)
 	if err != nil {
 		return c.Send("Упражнение не найдено")
 	}
@@ -266,7 +449,7 @@ func handleRepsInput(message string, WaitingForReps bool, WaitingForWeight bool)
 }
 
 // Обработчик ввода веса
-func (s *HistoryService) handleWeightInput(chatID int64, CurrentExerciseID int, CurrentExerciseName, message string) string {
+func (s *HistoryService) handleWeightInput(ctx context.Context, chatID int64, CurrentExerciseID int, CurrentExerciseName, message string) string {
 	// user := c.Sender()
 	// state := userStates[user.ID]
 
@@ -283,7 +466,7 @@ func (s *HistoryService) handleWeightInput(chatID int64, CurrentExerciseID int,
 	// }
 
 	// Сохраняем подход в БД
-	if err := s.db.SaveWorkoutSet(chatID, CurrentExerciseID, weight, 0); err != nil {
+	if err := s.db.SaveWorkoutSet(ctx, chatID, CurrentExerciseID, weight, 0); err != nil {
 		// Предположим, что reps уже были сохранены или нужно исправить логику
 		// log.Printf("Failed to save workout set: %v", err)
 		return "Ошибка при сохранении подхода"
@@ -307,7 +490,7 @@ func (s *HistoryService) handleWeightInput(chatID int64, CurrentExerciseID int,
 	return response
 }
 
-func handleWorkoutMessage(c telebot.Context, db *database.Postgres, message string) error {
+func handleWorkoutMessage(ctx context.Context, c telebot.Context, db Repository, message string) error {
 	user := c.Sender()
 	parts := strings.Fields(message)
 
@@ -322,12 +505,12 @@ func handleWorkoutMessage(c telebot.Context, db *database.Postgres, message stri
 			return c.Send("Неверный формат повторений")
 		}
 
-		exercise, err := db.GetExerciseByName(exerciseName)
+		exercise, err := db.GetExerciseByName(ctx, user.ID, exerciseName)
 		if err != nil {
 			return c.Send(fmt.Sprintf("Упражнение '%s' не найдено", exerciseName))
 		}
 
-		if err := db.SaveWorkoutSet(user.ID, exercise.ID, 0, reps); err != nil {
+		if err := db.SaveWorkoutSet(ctx, user.ID, exercise.ID, 0, reps); err != nil {
 			return c.Send("Ошибка при сохранении подхода")
 		}
 
@@ -336,3 +519,40 @@ func handleWorkoutMessage(c telebot.Context, db *database.Postgres, message stri
 
 	return c.Send("Используй формат: Упражнение Повторения\nИли нажми /add для выбора из списка")
 }
+
+
+// TrainingStreaks считает текущую и самую длинную серию тренировочных дней подряд.
+// Текущая серия не прерывается, если сегодня ещё не было тренировки.
+func TrainingStreaks(days []model.DayActivity, today time.Time) (int, int) {
+	trained := make(map[string]bool, len(days))
+	for _, d := range days {
+		trained[d.Date.Format("2006-01-02")] = true
+	}
+
+	longest, run := 0, 0
+	var prev time.Time
+	for i, d := range days {
+		day := time.Date(d.Date.Year(), d.Date.Month(), d.Date.Day(), 0, 0, 0, 0, time.UTC)
+		if i > 0 && day.Sub(prev) == 24*time.Hour {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+		prev = day
+	}
+
+	current := 0
+	day := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	if !trained[day.Format("2006-01-02")] {
+		day = day.AddDate(0, 0, -1)
+	}
+	for trained[day.Format("2006-01-02")] {
+		current++
+		day = day.AddDate(0, 0, -1)
+	}
+
+	return current, longest
+}
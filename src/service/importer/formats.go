@@ -0,0 +1,256 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"gofitness/src/i18n"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Форматы CSV, которые умеет распознавать импорт
+const (
+	FormatStrong    = "Strong"
+	FormatHevy      = "Hevy"
+	FormatFitNotes  = "FitNotes"
+	FormatGoFitness = "GoFitness"
+)
+
+const kgPerLb = 0.45359237
+
+// Подход, прочитанный из файла
+type importedSet struct {
+	Time     time.Time
+	Exercise string
+	WeightKg float64
+	Reps     int
+}
+
+// Колонки заголовка: имя в нижнем регистре → индекс
+type columns map[string]int
+
+func (c columns) has(names ...string) bool {
+	for _, name := range names {
+		if _, ok := c[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Счётчик подходов внутри одной тренировки: у Strong и Hevy все строки
+// тренировки несут её время начала, у FitNotes — только дату
+type workoutOrder struct {
+	last  string
+	order int
+}
+
+// next возвращает номер подхода в тренировке key, начиная с 1
+func (w *workoutOrder) next(key string) int {
+	if key != w.last {
+		w.last, w.order = key, 0
+	}
+	w.order++
+	return w.order
+}
+
+// offset сдвигает подход на order секунд, чтобы сохранить порядок строк файла
+func offset(set importedSet, ok bool, err error, order int) (importedSet, bool, error) {
+	set.Time = set.Time.Add(time.Duration(order) * time.Second)
+	return set, ok, err
+}
+
+func (c columns) get(record []string, name string) string {
+	i, ok := c[name]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// parseCSV определяет формат по заголовку и читает все подходы.
+// lbs — вес в фунтах для Strong, если в файле нет колонки с единицами.
+func parseCSV(r io.Reader, lbs bool) (string, []importedSet, int, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", nil, 0, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\uFEFF"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = detectDelimiter(data)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return "", nil, 0, i18n.NewError("import.bad_header", err)
+	}
+
+	cols := make(columns, len(header))
+	for i, name := range header {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	var format string
+	var parse func(record []string) (importedSet, bool, error)
+	decimalComma := reader.Comma == ';'
+
+	switch {
+	case cols.has("exercise name", "set order", "weight", "reps"):
+		format = FormatStrong
+		var workout workoutOrder
+		parse = func(record []string) (importedSet, bool, error) {
+			unitLbs := lbs
+			if unit := strings.ToLower(cols.get(record, "weight unit")); unit != "" {
+				unitLbs = strings.HasPrefix(unit, "lb")
+			}
+			// Set Order сбрасывается на каждом упражнении, поэтому считаем строки тренировки
+			date := cols.get(record, "date")
+			set, ok, err := buildSet(
+				date, []string{"2006-01-02 15:04:05", "2006-01-02 15:04"},
+				cols.get(record, "exercise name"),
+				cols.get(record, "weight"), unitLbs,
+				cols.get(record, "reps"), decimalComma,
+			)
+			return offset(set, ok, err, workout.next(date))
+		}
+	case cols.has("exercise_title", "start_time", "reps"):
+		format = FormatHevy
+		weightCol, unitLbs := "weight_kg", false
+		if cols.has("weight_lbs") {
+			weightCol, unitLbs = "weight_lbs", true
+		}
+		var workout workoutOrder
+		parse = func(record []string) (importedSet, bool, error) {
+			start := cols.get(record, "start_time")
+			set, ok, err := buildSet(
+				start, []string{"2 Jan 2006, 15:04", "2006-01-02 15:04:05", time.RFC3339},
+				cols.get(record, "exercise_title"),
+				cols.get(record, weightCol), unitLbs,
+				cols.get(record, "reps"), decimalComma,
+			)
+			return offset(set, ok, err, workout.next(start))
+		}
+	case cols.has("date", "exercise", "category", "reps"):
+		format = FormatFitNotes
+		weightCol, unitLbs := "weight (kgs)", false
+		if cols.has("weight (lbs)") {
+			weightCol, unitLbs = "weight (lbs)", true
+		}
+		// FitNotes хранит только дату: раскладываем подходы дня по секундам, сохраняя порядок
+		var workout workoutOrder
+		parse = func(record []string) (importedSet, bool, error) {
+			day := cols.get(record, "date")
+			set, ok, err := buildSet(
+				day, []string{"2006-01-02"},
+				cols.get(record, "exercise"),
+				cols.get(record, weightCol), unitLbs,
+				cols.get(record, "reps"), decimalComma,
+			)
+			set.Time = set.Time.Add(12 * time.Hour)
+			return offset(set, ok, err, workout.next(day))
+		}
+	case cols.has("date", "time", "exercise", "weight_kg", "reps"):
+		format = FormatGoFitness
+		parse = func(record []string) (importedSet, bool, error) {
+			return buildSet(
+				cols.get(record, "date")+" "+cols.get(record, "time"), []string{"2006-01-02 15:04:05"},
+				cols.get(record, "exercise"),
+				cols.get(record, "weight_kg"), false,
+				cols.get(record, "reps"), decimalComma,
+			)
+		}
+	default:
+		return "", nil, 0, i18n.NewError("import.unknown_format")
+	}
+
+	var sets []importedSet
+	skipped := 0
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return "", nil, 0, i18n.NewError("import.line", line, err)
+		}
+
+		set, ok, err := parse(record)
+		if err != nil {
+			return "", nil, 0, i18n.NewError("import.line", line, err)
+		}
+		if !ok {
+			skipped++
+			continue
+		}
+		sets = append(sets, set)
+	}
+
+	return format, sets, skipped, nil
+}
+
+// buildSet разбирает поля строки; ok == false — строку нужно пропустить
+// (кардио или упражнение на время без повторений)
+func buildSet(date string, layouts []string, exercise, weight string, lbs bool, reps string, decimalComma bool) (importedSet, bool, error) {
+	if exercise == "" || reps == "" {
+		return importedSet{}, false, nil
+	}
+
+	repsValue, err := parseNumber(reps, decimalComma)
+	if err != nil {
+		return importedSet{}, false, i18n.NewError("import.bad_reps", reps)
+	}
+	if repsValue <= 0 {
+		return importedSet{}, false, nil
+	}
+
+	var weightValue float64
+	if weight != "" {
+		weightValue, err = parseNumber(weight, decimalComma)
+		if err != nil || weightValue < 0 {
+			return importedSet{}, false, i18n.NewError("import.bad_weight", weight)
+		}
+	}
+	if lbs {
+		weightValue = weightValue * kgPerLb
+	}
+
+	var at time.Time
+	for _, layout := range layouts {
+		if at, err = time.ParseInLocation(layout, date, time.Local); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return importedSet{}, false, i18n.NewError("import.bad_date", date)
+	}
+
+	return importedSet{
+		Time:     at,
+		Exercise: exercise,
+		WeightKg: float64(int(weightValue*100+0.5)) / 100,
+		Reps:     int(repsValue + 0.5),
+	}, true, nil
+}
+
+func parseNumber(value string, decimalComma bool) (float64, error) {
+	if decimalComma {
+		value = strings.ReplaceAll(value, ",", ".")
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// Разделитель по первой строке: Strong в некоторых локалях пишет через «;»
+func detectDelimiter(data []byte) rune {
+	line, _ := bufio.NewReader(bytes.NewReader(data)).ReadString('\n')
+	if strings.Count(line, ";") > strings.Count(line, ",") {
+		return ';'
+	}
+	return ','
+}
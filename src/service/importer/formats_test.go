@@ -0,0 +1,182 @@
+package importer
+
+import (
+	"errors"
+	"gofitness/src/i18n"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Время в местном часовом поясе, как его разбирает parseCSV
+func at(value string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", value, time.Local)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		lbs     bool
+		format  string
+		sets    []importedSet
+		skipped int
+	}{
+		{
+			name: "Strong",
+			csv: "Date,Workout Name,Duration,Exercise Name,Set Order,Weight,Reps,Distance,Seconds,Notes,Workout Notes,RPE\n" +
+				"2024-01-05 18:00:00,Push,1h,Bench Press (Barbell),1,60,8,0,0,,,\n" +
+				"2024-01-05 18:00:00,Push,1h,Bench Press (Barbell),2,60,8,0,0,,,\n" +
+				"2024-01-05 18:00:00,Push,1h,Plank,1,0,,0,60,,,\n" +
+				"2024-01-05 18:00:00,Push,1h,Pull Up,1,0,10,0,0,,,\n" +
+				"2024-01-07 18:30:00,Legs,1h,Squat (Barbell),1,100,5,0,0,,,\n",
+			format: FormatStrong,
+			sets: []importedSet{
+				{Time: at("2024-01-05 18:00:01"), Exercise: "Bench Press (Barbell)", WeightKg: 60, Reps: 8},
+				{Time: at("2024-01-05 18:00:02"), Exercise: "Bench Press (Barbell)", WeightKg: 60, Reps: 8},
+				// Set Order начинается заново, но порядок строк тренировки сохраняется
+				{Time: at("2024-01-05 18:00:04"), Exercise: "Pull Up", WeightKg: 0, Reps: 10},
+				{Time: at("2024-01-07 18:30:01"), Exercise: "Squat (Barbell)", WeightKg: 100, Reps: 5},
+			},
+			skipped: 1,
+		},
+		{
+			name: "Strong в фунтах",
+			csv: "Date,Exercise Name,Set Order,Weight,Reps\n" +
+				"2024-01-05 18:00,Deadlift (Barbell),1,225,5\n",
+			lbs:    true,
+			format: FormatStrong,
+			sets: []importedSet{
+				{Time: at("2024-01-05 18:00:01"), Exercise: "Deadlift (Barbell)", WeightKg: 102.06, Reps: 5},
+			},
+		},
+		{
+			name: "Strong с колонкой единиц",
+			csv: "Date,Exercise Name,Set Order,Weight,Weight Unit,Reps\n" +
+				"2024-01-05 18:00:00,Squat (Barbell),1,100,lbs,5\n" +
+				"2024-01-05 18:00:00,Squat (Barbell),2,100,kg,5\n",
+			format: FormatStrong,
+			sets: []importedSet{
+				{Time: at("2024-01-05 18:00:01"), Exercise: "Squat (Barbell)", WeightKg: 45.36, Reps: 5},
+				{Time: at("2024-01-05 18:00:02"), Exercise: "Squat (Barbell)", WeightKg: 100, Reps: 5},
+			},
+		},
+		{
+			name: "Strong через точку с запятой",
+			csv: "Date;Workout Name;Exercise Name;Set Order;Weight;Reps\n" +
+				"2024-01-05 18:00:00;Push;Bench Press (Barbell);1;62,5;8\n" +
+				"2024-01-05 18:00:00;Push;Bench Press (Barbell);2;62,5;7\n",
+			format: FormatStrong,
+			sets: []importedSet{
+				{Time: at("2024-01-05 18:00:01"), Exercise: "Bench Press (Barbell)", WeightKg: 62.5, Reps: 8},
+				{Time: at("2024-01-05 18:00:02"), Exercise: "Bench Press (Barbell)", WeightKg: 62.5, Reps: 7},
+			},
+		},
+		{
+			name: "Hevy",
+			csv: "title,start_time,end_time,description,exercise_title,superset_id,exercise_notes,set_index,set_type,weight_kg,reps,distance_km,duration_seconds,rpe\n" +
+				"Push,\"5 Jan 2024, 18:00\",\"5 Jan 2024, 19:00\",,Bench Press (Barbell),,,0,normal,60,8,,,\n" +
+				"Push,\"5 Jan 2024, 18:00\",\"5 Jan 2024, 19:00\",,Overhead Press (Barbell),,,0,normal,40,10,,,\n" +
+				"Push,\"5 Jan 2024, 18:00\",\"5 Jan 2024, 19:00\",,Treadmill,,,0,normal,,,2,600,\n",
+			format: FormatHevy,
+			sets: []importedSet{
+				{Time: at("2024-01-05 18:00:01"), Exercise: "Bench Press (Barbell)", WeightKg: 60, Reps: 8},
+				{Time: at("2024-01-05 18:00:02"), Exercise: "Overhead Press (Barbell)", WeightKg: 40, Reps: 10},
+			},
+			skipped: 1,
+		},
+		{
+			name: "Hevy в фунтах",
+			csv: "title,start_time,exercise_title,set_index,weight_lbs,reps\n" +
+				"Legs,2024-01-07 10:00:00,Squat (Barbell),0,100,5\n",
+			format: FormatHevy,
+			sets: []importedSet{
+				{Time: at("2024-01-07 10:00:01"), Exercise: "Squat (Barbell)", WeightKg: 45.36, Reps: 5},
+			},
+		},
+		{
+			name: "FitNotes",
+			csv: "Date,Exercise,Category,Weight (kgs),Reps,Distance,Distance Unit,Time,Comment\n" +
+				"2024-01-05,Flat Barbell Bench Press,Chest,60.0,8,,,,\n" +
+				"2024-01-05,Pull Up,Back,,10,,,,\n" +
+				"2024-01-06,Barbell Squat,Legs,100.0,5,,,,\n",
+			format: FormatFitNotes,
+			sets: []importedSet{
+				{Time: at("2024-01-05 12:00:01"), Exercise: "Flat Barbell Bench Press", WeightKg: 60, Reps: 8},
+				{Time: at("2024-01-05 12:00:02"), Exercise: "Pull Up", WeightKg: 0, Reps: 10},
+				{Time: at("2024-01-06 12:00:01"), Exercise: "Barbell Squat", WeightKg: 100, Reps: 5},
+			},
+		},
+		{
+			name: "FitNotes в фунтах",
+			csv: "Date,Exercise,Category,Weight (lbs),Reps\n" +
+				"2024-01-05,Deadlift,Back,315,3\n",
+			format: FormatFitNotes,
+			sets: []importedSet{
+				{Time: at("2024-01-05 12:00:01"), Exercise: "Deadlift", WeightKg: 142.88, Reps: 3},
+			},
+		},
+		{
+			name: "GoFitness",
+			csv: "\uFEFFdate,time,exercise,weight_kg,reps\n" +
+				"2024-01-05,18:00:00,Жим лежа,60,8\n" +
+				"2024-01-05,18:05:00,Жим лежа,62.5,6\n",
+			format: FormatGoFitness,
+			sets: []importedSet{
+				{Time: at("2024-01-05 18:00:00"), Exercise: "Жим лежа", WeightKg: 60, Reps: 8},
+				{Time: at("2024-01-05 18:05:00"), Exercise: "Жим лежа", WeightKg: 62.5, Reps: 6},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, sets, skipped, err := parseCSV(strings.NewReader(tt.csv), tt.lbs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if format != tt.format {
+				t.Errorf("формат %q, ожидался %q", format, tt.format)
+			}
+			if skipped != tt.skipped {
+				t.Errorf("пропущено %d, ожидалось %d", skipped, tt.skipped)
+			}
+			if len(sets) != len(tt.sets) {
+				t.Fatalf("подходов %d, ожидалось %d: %+v", len(sets), len(tt.sets), sets)
+			}
+			for i, want := range tt.sets {
+				got := sets[i]
+				if !got.Time.Equal(want.Time) || got.Exercise != want.Exercise || got.WeightKg != want.WeightKg || got.Reps != want.Reps {
+					t.Errorf("подход %d: %+v, ожидался %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseCSVErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		key  string
+	}{
+		{"неизвестный формат", "a,b,c\n1,2,3\n", "import.unknown_format"},
+		{"пустой файл", "", "import.bad_header"},
+		{"плохая дата", "date,time,exercise,weight_kg,reps\n05.01.2024,18:00:00,Жим лежа,60,8\n", "import.line"},
+		{"плохой вес", "date,time,exercise,weight_kg,reps\n2024-01-05,18:00:00,Жим лежа,много,8\n", "import.line"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, _, err := parseCSV(strings.NewReader(tt.csv), false)
+			var localized *i18n.Error
+			if !errors.As(err, &localized) || localized.Key != tt.key {
+				t.Errorf("ошибка %v, ожидался ключ %s", err, tt.key)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"gofitness/src/database"
+	"gofitness/src/i18n"
+	"gofitness/src/model"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Максимальный размер импортируемого файла
+const MaxFileSize = 10 << 20
+
+// Названия упражнений из других приложений, соответствующие стандартному каталогу
+var exerciseAliases = map[string]string{
+	"squat":                              "Приседания",
+	"squat (barbell)":                    "Приседания",
+	"barbell squat":                      "Приседания",
+	"back squat":                         "Приседания",
+	"bench press":                        "Жим лежа",
+	"bench press (barbell)":              "Жим лежа",
+	"flat barbell bench press":           "Жим лежа",
+	"deadlift":                           "Становая тяга",
+	"deadlift (barbell)":                 "Становая тяга",
+	"pull up":                            "Подтягивания",
+	"pull-up":                            "Подтягивания",
+	"pull up (weighted)":                 "Подтягивания",
+	"push up":                            "Отжимания",
+	"push-up":                            "Отжимания",
+	"overhead press":                     "Жим стоя",
+	"overhead press (barbell)":           "Жим стоя",
+	"strict press":                       "Жим стоя",
+	"bent over row (barbell)":            "Тяга штанги",
+	"barbell row":                        "Тяга штанги",
+	"bent over barbell row":              "Тяга штанги",
+	"bicep curl (barbell)":               "Бицепс",
+	"barbell curl":                       "Бицепс",
+	"close grip bench press":             "Трицепс",
+	"close grip barbell bench press":     "Трицепс",
+	"bench press - close grip (barbell)": "Трицепс",
+	"plank":                              "Планка",
+}
+
+// Repository — хранилище, нужное импорту: каталог упражнений и подходы
+type Repository interface {
+	database.ExerciseRepository
+	database.SetRepository
+}
+
+type ImporterService struct {
+	db Repository
+}
+
+func NewImporterService(db Repository) *ImporterService {
+	return &ImporterService{db: db}
+}
+
+// Prepare разбирает CSV и сопоставляет упражнения с каталогом.
+// Ничего не пишет в базу: план нужно подтвердить и передать в Commit.
+// Ошибки разбора файла — *i18n.Error, их текст можно показать пользователю.
+func (s *ImporterService) Prepare(ctx context.Context, user *model.User, r io.Reader, lbs bool) (*model.ImportPlan, error) {
+	format, rows, skipped, err := parseCSV(io.LimitReader(r, MaxFileSize), lbs)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, i18n.NewError("import.no_sets")
+	}
+
+	exercises, err := s.db.GetExercises(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения упражнений: %w", err)
+	}
+
+	catalog := make(map[string]model.Exercise, len(exercises))
+	for _, ex := range exercises {
+		catalog[strings.ToLower(ex.Name)] = ex
+	}
+
+	plan := &model.ImportPlan{Source: format, Skipped: skipped}
+	matched := make(map[int]bool)
+	created := make(map[string]string) // нижний регистр → название нового упражнения
+
+	for _, row := range rows {
+		key := strings.ToLower(row.Exercise)
+		if alias, ok := exerciseAliases[key]; ok {
+			key = strings.ToLower(alias)
+		}
+
+		set := model.WorkoutSet{
+			UserID:    user.ID,
+			Weight:    row.WeightKg,
+			Reps:      row.Reps,
+			CreatedAt: row.Time,
+		}
+
+		if ex, ok := catalog[key]; ok {
+			set.ExerciseID = ex.ID
+			set.ExerciseName = ex.Name
+			matched[ex.ID] = true
+		} else {
+			name, ok := created[key]
+			if !ok {
+				name = row.Exercise
+				created[key] = name
+				plan.NewExercises = append(plan.NewExercises, name)
+			}
+			set.ExerciseName = name
+		}
+
+		plan.Sets = append(plan.Sets, set)
+	}
+
+	sort.Slice(plan.Sets, func(i, j int) bool {
+		return plan.Sets[i].CreatedAt.Before(plan.Sets[j].CreatedAt)
+	})
+	sort.Strings(plan.NewExercises)
+	plan.Matched = len(matched)
+
+	return plan, nil
+}
+
+// Summary — описание плана импорта для подтверждения
+func Summary(loc i18n.Locale, plan *model.ImportPlan) string {
+	first := plan.Sets[0].CreatedAt
+	last := plan.Sets[len(plan.Sets)-1].CreatedAt
+	layout := loc.T("layout.date")
+
+	var message strings.Builder
+	message.WriteString(loc.T("import.file", plan.Source))
+	message.WriteString(loc.T("import.sets", len(plan.Sets)))
+	message.WriteString(loc.T("import.period", first.Format(layout), last.Format(layout)))
+	message.WriteString(loc.T("import.matched", plan.Matched))
+	if plan.Skipped > 0 {
+		message.WriteString(loc.T("import.skipped", plan.Skipped))
+	}
+
+	if len(plan.NewExercises) > 0 {
+		message.WriteString(loc.T("import.new_exercises", len(plan.NewExercises)))
+		const maxListed = 15
+		for i, name := range plan.NewExercises {
+			if i == maxListed {
+				message.WriteString(loc.T("import.more", len(plan.NewExercises)-maxListed))
+				break
+			}
+			message.WriteString(fmt.Sprintf("• %s\n", name))
+		}
+	}
+
+	message.WriteString(loc.T("import.prompt", loc.T("import.confirm"), loc.T("import.cancel")))
+	return message.String()
+}
+
+// Commit сохраняет подтверждённый план одной транзакцией
+func (s *ImporterService) Commit(ctx context.Context, loc i18n.Locale, user *model.User, plan *model.ImportPlan) (string, error) {
+	if err := s.db.ImportWorkoutSets(ctx, user.ID, plan.NewExercises, plan.Sets); err != nil {
+		return "", fmt.Errorf("ошибка импорта: %w", err)
+	}
+
+	return loc.T("import.done", len(plan.Sets), len(plan.NewExercises)), nil
+}
@@ -0,0 +1,239 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"gofitness/src/database"
+	"gofitness/src/i18n"
+	"gofitness/src/model"
+	"gofitness/src/service/history"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Period — период сводного отчёта
+type Period string
+
+const (
+	PeriodWeek  Period = "week"
+	PeriodMonth Period = "month"
+	PeriodYear  Period = "year"
+)
+
+// ParsePeriod разбирает аргумент команды /report (по умолчанию неделя)
+func ParsePeriod(arg string) (Period, bool) {
+	switch strings.ToLower(strings.TrimSpace(arg)) {
+	case "", "week", "неделя":
+		return PeriodWeek, true
+	case "month", "месяц":
+		return PeriodMonth, true
+	case "year", "год":
+		return PeriodYear, true
+	}
+	return "", false
+}
+
+// Report — сводка за период и сравнение с предыдущим таким же периодом
+type Report struct {
+	Period   Period
+	Current  *model.WorkoutSummary
+	Previous *model.WorkoutSummary
+	Bests    []model.ExerciseBest
+	Days     []model.DayActivity
+}
+
+// Repository — хранилище, нужное отчётам: пользователи для рассылки и агрегаты подходов
+type Repository interface {
+	database.UserRepository
+	database.SetRepository
+}
+
+type ReportService struct {
+	db Repository
+}
+
+func NewReportService(db Repository) *ReportService {
+	return &ReportService{db: db}
+}
+
+// GetReport — отчёт для команды /report: текст и график (график может быть nil)
+func (s *ReportService) GetReport(ctx context.Context, loc i18n.Locale, user *model.User, period Period) (string, *bytes.Buffer, error) {
+	report, err := s.BuildReport(ctx, user.ID, period, time.Now())
+	if err != nil {
+		return loc.T("report.error"), nil, err
+	}
+
+	return FormatReport(loc, report), s.Chart(loc, report), nil
+}
+
+// BuildReport собирает отчёт за календарный период, в который попадает момент at
+func (s *ReportService) BuildReport(ctx context.Context, userID int64, period Period, at time.Time) (*Report, error) {
+	from, to := periodRange(period, at)
+	prevFrom, prevTo := periodRange(period, from.AddDate(0, 0, -1))
+
+	current, err := s.db.GetWorkoutSummary(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := s.db.GetWorkoutSummary(ctx, userID, prevFrom, prevTo)
+	if err != nil {
+		return nil, err
+	}
+
+	bests, err := s.db.GetExerciseBests(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	days, err := s.db.GetDailyActivity(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Report{
+		Period:   period,
+		Current:  current,
+		Previous: previous,
+		Bests:    bests,
+		Days:     days,
+	}, nil
+}
+
+// FormatReport — текст отчёта для Telegram
+func FormatReport(loc i18n.Locale, r *Report) string {
+	cur, prev := r.Current, r.Previous
+	to := cur.To.AddDate(0, 0, -1)
+	layout := loc.T("layout.date")
+
+	var message strings.Builder
+	message.WriteString(loc.T("report.header",
+		loc.T("report.title."+string(r.Period)), cur.From.Format(layout), to.Format(layout)))
+
+	if cur.Sets == 0 {
+		message.WriteString(loc.T("report.empty"))
+		if prev.Sets > 0 {
+			message.WriteString(loc.T("report.empty_previous",
+				loc.T("report.previous_in."+string(r.Period)), prev.Sessions))
+		}
+		return message.String()
+	}
+
+	message.WriteString(loc.T("report.sessions", cur.Sessions,
+		formatDelta(loc, float64(cur.Sessions), float64(prev.Sessions))))
+	message.WriteString(loc.T("report.sets", cur.Sets,
+		formatDelta(loc, float64(cur.Sets), float64(prev.Sets))))
+	message.WriteString(loc.T("report.reps", cur.Reps,
+		formatDelta(loc, float64(cur.Reps), float64(prev.Reps))))
+	message.WriteString(loc.T("report.tonnage", cur.Tonnage,
+		formatDelta(loc, cur.Tonnage, prev.Tonnage)))
+	message.WriteString(loc.T("report.compare", loc.T("report.previous."+string(r.Period))))
+
+	if len(r.Bests) > 0 {
+		message.WriteString(loc.T("report.bests"))
+		for _, best := range r.Bests {
+			if best.MaxWeight > 0 {
+				message.WriteString(loc.T("report.best_weight",
+					loc.Exercise(best.ExerciseName), best.MaxWeight, best.BestE1RM, best.Sets))
+			} else {
+				message.WriteString(loc.T("report.best_reps",
+					loc.Exercise(best.ExerciseName), best.MaxReps, best.Sets))
+			}
+		}
+	}
+
+	return message.String()
+}
+
+// Chart — столбчатая диаграмма тоннажа (или подходов, если всё без веса) по периоду
+func (s *ReportService) Chart(loc i18n.Locale, r *Report) *bytes.Buffer {
+	if r.Current.Sets == 0 {
+		return nil
+	}
+
+	useTonnage := r.Current.Tonnage > 0
+	value := func(d model.DayActivity) float64 {
+		if useTonnage {
+			return d.Tonnage
+		}
+		return float64(d.Sets)
+	}
+
+	var labels []string
+	var values []float64
+	from, to := r.Current.From, r.Current.To
+
+	switch r.Period {
+	case PeriodYear:
+		// За год — по месяцам, иначе столбцов слишком много
+		monthNames := loc.List("months.short")
+		for m := from; m.Before(to); m = m.AddDate(0, 1, 0) {
+			labels = append(labels, monthNames[m.Month()-1])
+			values = append(values, 0)
+		}
+		for _, d := range r.Days {
+			values[d.Date.Month()-1] += value(d)
+		}
+	default:
+		weekdayNames := loc.List("weekdays.short")
+		index := make(map[string]int)
+		for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+			index[d.Format("2006-01-02")] = len(values)
+			if r.Period == PeriodWeek {
+				labels = append(labels, weekdayNames[(int(d.Weekday())+6)%7])
+			} else {
+				labels = append(labels, fmt.Sprintf("%d", d.Day()))
+			}
+			values = append(values, 0)
+		}
+		for _, d := range r.Days {
+			if i, ok := index[d.Date.Format("2006-01-02")]; ok {
+				values[i] += value(d)
+			}
+		}
+	}
+
+	title := loc.T("chart.sets")
+	if useTonnage {
+		title = loc.T("chart.tonnage_kg")
+	}
+
+	buf, err := history.GenerateBarChart(title, labels, values)
+	if err != nil {
+		slog.Error("Ошибка генерации графика отчёта", "err", err)
+		return nil
+	}
+	return buf
+}
+
+// Границы календарного периода [from, to), в который попадает момент at
+func periodRange(period Period, at time.Time) (time.Time, time.Time) {
+	y, m, d := at.Date()
+	switch period {
+	case PeriodMonth:
+		from := time.Date(y, m, 1, 0, 0, 0, 0, at.Location())
+		return from, from.AddDate(0, 1, 0)
+	case PeriodYear:
+		from := time.Date(y, time.January, 1, 0, 0, 0, 0, at.Location())
+		return from, from.AddDate(1, 0, 0)
+	default:
+		// Неделя начинается с понедельника
+		offset := (int(at.Weekday()) + 6) % 7
+		from := time.Date(y, m, d-offset, 0, 0, 0, 0, at.Location())
+		return from, from.AddDate(0, 0, 7)
+	}
+}
+
+// Изменение относительно предыдущего периода, например " (+12%)"
+func formatDelta(loc i18n.Locale, current, previous float64) string {
+	if previous == 0 {
+		if current == 0 {
+			return ""
+		}
+		return loc.T("report.new")
+	}
+	change := (current - previous) / previous * 100
+	return fmt.Sprintf(" (%+.0f%%)", change)
+}
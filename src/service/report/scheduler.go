@@ -0,0 +1,137 @@
+package report
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"gofitness/src/i18n"
+	"gofitness/src/logging"
+
+	"gopkg.in/telebot.v3"
+)
+
+// Час (по локальному времени), в который рассылаются отчёты
+const reportHour = 9
+
+// Scheduler рассылает недельные отчёты по понедельникам
+// и месячные — первого числа каждого месяца
+type Scheduler struct {
+	bot     *telebot.Bot
+	service *ReportService
+	// Отменяется в Stop: прерывает ожидание и запросы текущей рассылки
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewScheduler(b *telebot.Bot, service *ReportService) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		bot:     b,
+		service: service,
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+}
+
+// Start запускает планировщик в отдельной горутине
+func (s *Scheduler) Start() {
+	go s.run()
+}
+
+// Stop останавливает планировщик и ждёт завершения текущей рассылки
+func (s *Scheduler) Stop() {
+	s.cancel()
+	<-s.done
+}
+
+func (s *Scheduler) run() {
+	defer close(s.done)
+
+	for {
+		next := nextRun(time.Now())
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			if next.Weekday() == time.Monday {
+				s.sendAll(PeriodWeek, next)
+			}
+			if next.Day() == 1 {
+				s.sendAll(PeriodMonth, next)
+			}
+		}
+	}
+}
+
+// Рассылает отчёт за прошедший период всем, кто тренировался в нём или в предыдущем
+func (s *Scheduler) sendAll(period Period, at time.Time) {
+	ctx := logging.With(s.ctx, "period", string(period))
+	users, err := s.service.db.GetAllUsers(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения пользователей для рассылки", "err", err)
+		return
+	}
+
+	sent := 0
+	for _, user := range users {
+		select {
+		case <-s.ctx.Done():
+			return
+		default:
+		}
+
+		if user.Banned {
+			continue
+		}
+
+		userCtx := logging.With(ctx, "user_id", user.ChatID)
+		report, err := s.service.BuildReport(userCtx, user.ID, period, at.AddDate(0, 0, -1))
+		if err != nil {
+			slog.ErrorContext(userCtx, "Ошибка построения отчёта", "err", err)
+			continue
+		}
+		if report.Current.Sets == 0 && report.Previous.Sets == 0 {
+			continue
+		}
+
+		// Языка Telegram вне обновления не знаем, поэтому без /settings — язык по умолчанию
+		loc, ok := i18n.Parse(user.Language)
+		if !ok {
+			loc = i18n.Default
+		}
+
+		chat := telebot.ChatID(user.ChatID)
+		if _, err := s.bot.Send(chat, FormatReport(loc, report)); err != nil {
+			slog.ErrorContext(userCtx, "Ошибка отправки отчёта", "err", err)
+			continue
+		}
+		if buf := s.service.Chart(loc, report); buf != nil {
+			if _, err := s.bot.Send(chat, &telebot.Photo{File: telebot.FromReader(buf)}); err != nil {
+				slog.ErrorContext(userCtx, "Ошибка отправки графика", "err", err)
+			}
+		}
+		// Темп рассылки задаёт ограничитель исходящих сообщений в HTTP-клиенте бота
+		sent++
+	}
+
+	slog.InfoContext(ctx, "Рассылка отчётов завершена", "recipients", sent)
+}
+
+// Ближайший момент рассылки: понедельник или первое число месяца в reportHour
+func nextRun(now time.Time) time.Time {
+	y, m, d := now.Date()
+	next := time.Date(y, m, d, reportHour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	for next.Weekday() != time.Monday && next.Day() != 1 {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
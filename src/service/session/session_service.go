@@ -0,0 +1,142 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"gofitness/src/database"
+	"gofitness/src/model"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Сроки действия одноразовой ссылки и сессии веб-кабинета
+const (
+	LoginTTL   = 15 * time.Minute
+	SessionTTL = 30 * 24 * time.Hour
+)
+
+type entry struct {
+	chatID  int64
+	expires time.Time
+}
+
+// SessionService выдаёт одноразовые ссылки входа в веб-кабинет и хранит сессии.
+// Всё хранится в памяти процесса: после перезапуска нужно снова запросить /web.
+type SessionService struct {
+	db        database.UserRepository
+	publicURL string
+
+	mu       sync.Mutex
+	logins   map[string]entry
+	sessions map[string]entry
+}
+
+// publicURL — адрес, по которому пользователи открывают веб-кабинет;
+// пустой — веб-кабинет не настроен
+func NewSessionService(db database.UserRepository, publicURL string) *SessionService {
+	return &SessionService{
+		db:        db,
+		publicURL: strings.TrimRight(publicURL, "/"),
+		logins:    make(map[string]entry),
+		sessions:  make(map[string]entry),
+	}
+}
+
+func randomID() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("ошибка генерации ключа: %w", err)
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// Удаляем просроченные записи; вызывается под mu
+func (s *SessionService) cleanup(now time.Time) {
+	for id, e := range s.logins {
+		if now.After(e.expires) {
+			delete(s.logins, id)
+		}
+	}
+	for id, e := range s.sessions {
+		if now.After(e.expires) {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+// LoginURL создаёт одноразовую ссылку входа. Пустая строка — веб-кабинет не настроен.
+func (s *SessionService) LoginURL(user *model.User) (string, error) {
+	if s.publicURL == "" {
+		return "", nil
+	}
+
+	code, err := randomID()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	s.cleanup(now)
+	s.logins[code] = entry{chatID: user.ChatID, expires: now.Add(LoginTTL)}
+	s.mu.Unlock()
+
+	return s.publicURL + "/login?code=" + url.QueryEscape(code), nil
+}
+
+// Login обменивает код из ссылки на сессию. Код действует один раз.
+func (s *SessionService) Login(code string) (string, bool) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cleanup(now)
+	login, ok := s.logins[code]
+	if !ok {
+		return "", false
+	}
+	delete(s.logins, code)
+
+	id, err := randomID()
+	if err != nil {
+		return "", false
+	}
+	s.sessions[id] = entry{chatID: login.chatID, expires: now.Add(SessionTTL)}
+	return id, true
+}
+
+// User возвращает пользователя сессии или nil, если сессии нет, аккаунт удалён
+// или заблокирован администратором
+func (s *SessionService) User(ctx context.Context, sessionID string) (*model.User, error) {
+	s.mu.Lock()
+	e, ok := s.sessions[sessionID]
+	if ok && time.Now().After(e.expires) {
+		delete(s.sessions, sessionID)
+		ok = false
+	}
+	s.mu.Unlock()
+	if !ok {
+		return nil, nil
+	}
+
+	user, err := s.db.GetUserByChatID(ctx, e.chatID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.Banned {
+		s.Logout(sessionID)
+		return nil, nil
+	}
+	return user, nil
+}
+
+func (s *SessionService) Logout(sessionID string) {
+	s.mu.Lock()
+	delete(s.sessions, sessionID)
+	s.mu.Unlock()
+}
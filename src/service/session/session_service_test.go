@@ -0,0 +1,156 @@
+package session
+
+import (
+	"context"
+	"gofitness/src/database"
+	"gofitness/src/model"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func newService(t *testing.T) (*SessionService, *database.Memory, *model.User) {
+	t.Helper()
+	db := database.NewMemory()
+	user, err := db.GetOrCreateUser(context.Background(), 100, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewSessionService(db, "https://fit.example.com/"), db, user
+}
+
+// Код из одноразовой ссылки входа
+func loginCode(t *testing.T, s *SessionService, user *model.User) string {
+	t.Helper()
+	link, err := s.LoginURL(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("некорректная ссылка %q: %v", link, err)
+	}
+	if parsed.Host != "fit.example.com" || parsed.Path != "/login" {
+		t.Fatalf("ссылка %q ведёт не на страницу входа", link)
+	}
+	return parsed.Query().Get("code")
+}
+
+func TestLoginURLWithoutPublicURL(t *testing.T) {
+	s := NewSessionService(database.NewMemory(), "")
+	link, err := s.LoginURL(&model.User{ChatID: 100})
+	if err != nil || link != "" {
+		t.Fatalf("без адреса кабинета ссылка %q, ошибка %v", link, err)
+	}
+}
+
+func TestLoginCodeIsOneTime(t *testing.T) {
+	s, _, user := newService(t)
+	code := loginCode(t, s, user)
+
+	id, ok := s.Login(code)
+	if !ok || id == "" {
+		t.Fatalf("код из ссылки не принят")
+	}
+	if _, ok := s.Login(code); ok {
+		t.Fatalf("код принят повторно")
+	}
+	if _, ok := s.Login("unknown"); ok {
+		t.Fatalf("принят неизвестный код")
+	}
+
+	got, err := s.User(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.ID != user.ID {
+		t.Fatalf("сессия вернула %+v, ожидался пользователь %d", got, user.ID)
+	}
+}
+
+func TestExpired(t *testing.T) {
+	s, _, user := newService(t)
+
+	// Просроченный код не меняется на сессию
+	code := loginCode(t, s, user)
+	s.mu.Lock()
+	s.logins[code] = entry{chatID: user.ChatID, expires: time.Now().Add(-time.Second)}
+	s.mu.Unlock()
+	if _, ok := s.Login(code); ok {
+		t.Fatalf("принят просроченный код")
+	}
+
+	// Просроченная сессия не пускает и удаляется
+	id, ok := s.Login(loginCode(t, s, user))
+	if !ok {
+		t.Fatalf("код из ссылки не принят")
+	}
+	s.mu.Lock()
+	s.sessions[id] = entry{chatID: user.ChatID, expires: time.Now().Add(-time.Second)}
+	s.mu.Unlock()
+
+	got, err := s.User(context.Background(), id)
+	if err != nil || got != nil {
+		t.Fatalf("просроченная сессия вернула %+v, ошибка %v", got, err)
+	}
+	s.mu.Lock()
+	_, kept := s.sessions[id]
+	s.mu.Unlock()
+	if kept {
+		t.Errorf("просроченная сессия не удалена")
+	}
+}
+
+func TestSessionDropped(t *testing.T) {
+	tests := []struct {
+		name string
+		drop func(db *database.Memory, user *model.User) error
+	}{
+		{"блокировка", func(db *database.Memory, user *model.User) error {
+			return db.SetUserBanned(context.Background(), user.ID, true)
+		}},
+		{"удаление аккаунта", func(db *database.Memory, user *model.User) error {
+			return db.DeleteUser(context.Background(), user.ID)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db, user := newService(t)
+			id, ok := s.Login(loginCode(t, s, user))
+			if !ok {
+				t.Fatalf("код из ссылки не принят")
+			}
+
+			if err := tt.drop(db, user); err != nil {
+				t.Fatal(err)
+			}
+			got, err := s.User(context.Background(), id)
+			if err != nil || got != nil {
+				t.Fatalf("сессия вернула %+v, ошибка %v", got, err)
+			}
+
+			// Сессия удалена: разблокировка её не вернёт
+			s.mu.Lock()
+			_, kept := s.sessions[id]
+			s.mu.Unlock()
+			if kept {
+				t.Errorf("сессия не удалена")
+			}
+		})
+	}
+}
+
+func TestLogout(t *testing.T) {
+	s, _, user := newService(t)
+	id, ok := s.Login(loginCode(t, s, user))
+	if !ok {
+		t.Fatalf("код из ссылки не принят")
+	}
+
+	s.Logout(id)
+	got, err := s.User(context.Background(), id)
+	if err != nil || got != nil {
+		t.Fatalf("после выхода сессия вернула %+v, ошибка %v", got, err)
+	}
+}
@@ -0,0 +1,62 @@
+package token
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"gofitness/src/database"
+	"gofitness/src/model"
+	"strings"
+)
+
+// Префикс персонального токена, чтобы его было легко узнать в конфигах и логах
+const Prefix = "gf_"
+
+type TokenService struct {
+	db database.TokenRepository
+}
+
+func NewTokenService(db database.TokenRepository) *TokenService {
+	return &TokenService{db: db}
+}
+
+// Хеш токена, который хранится в базе
+func hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Issue выпускает новый токен HTTP API; прежний токен пользователя перестаёт работать.
+// Сам токен нигде не сохраняется — его нужно сразу показать пользователю.
+func (s *TokenService) Issue(ctx context.Context, user *model.User) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("ошибка генерации токена: %w", err)
+	}
+	token := Prefix + hex.EncodeToString(raw)
+
+	if err := s.db.ReplaceAPIToken(ctx, user.ID, hash(token)); err != nil {
+		return "", fmt.Errorf("ошибка сохранения токена: %w", err)
+	}
+	return token, nil
+}
+
+// Authenticate возвращает владельца токена или nil, если токен неизвестен
+// или владелец заблокирован администратором
+func (s *TokenService) Authenticate(ctx context.Context, token string) (*model.User, error) {
+	if !strings.HasPrefix(token, Prefix) {
+		return nil, nil
+	}
+	user, err := s.db.GetUserByAPIToken(ctx, hash(token))
+	if err != nil || user == nil || user.Banned {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Revoke отзывает токен пользователя, если он был
+func (s *TokenService) Revoke(ctx context.Context, user *model.User) error {
+	return s.db.DeleteAPITokens(ctx, user.ID)
+}
@@ -2,20 +2,50 @@ package user
 
 import (
 	"context"
+	"fmt"
 	"gofitness/src/database"
+	"gofitness/src/i18n"
 	"gofitness/src/model"
+	"strings"
+	"time"
 )
 
+// Repository — хранилище, нужное сервису пользователей: сами пользователи и сводка по их данным
+type Repository interface {
+	database.UserRepository
+	database.ExerciseRepository
+	database.SetRepository
+}
+
 type UserService struct {
-    db *database.Postgres
+    db Repository
 }
 
-func NewUserService(db *database.Postgres) *UserService {
+func NewUserService(db Repository) *UserService {
     return &UserService{db: db}
 }
 
+// GetUser возвращает пользователя или nil, если его ещё нет. Имя из Telegram
+// сохраняем, только если оно изменилось
+func (s *UserService) GetUser(ctx context.Context, chatID int64, username string) (*model.User, error) {
+	user, err := s.db.GetUserByChatID(ctx, chatID)
+	if err != nil || user == nil {
+		return user, err
+	}
+	if user.Username == username {
+		return user, nil
+	}
+
+	updated, err := s.db.SaveUser(ctx, chatID, username)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка обновления имени пользователя: %w", err)
+	}
+	return updated, nil
+}
+
+// GetUserOrCreate — как GetUser, но при первом обращении создаёт пользователя
 func (s *UserService) GetUserOrCreate(ctx context.Context, chatID int64, username string) (*model.User, error) {
-	var user, e = s.db.GetUserByChatID(chatID)
+	var user, e = s.GetUser(ctx, chatID, username)
 
     if e != nil {
         return nil, e
@@ -25,7 +55,7 @@ func (s *UserService) GetUserOrCreate(ctx context.Context, chatID int64, usernam
     	return user, nil
     }
 
-    var userSave, err = s.db.SaveUser(chatID, username)
+    var userSave, err = s.db.SaveUser(ctx, chatID, username)
     
     if err != nil {
         return nil, err
@@ -35,9 +65,103 @@ func (s *UserService) GetUserOrCreate(ctx context.Context, chatID int64, usernam
 }
 
 func (s *UserService) GetUserStats(ctx context.Context, userID int64) (map[string]interface{}, error) {
-    // Бизнес-логика для статистики пользователя
-    return map[string]interface{}{
-        "total_messages": 10,
-        "last_activity":  "2024-01-01",
-    }, nil
-}
\ No newline at end of file
+	summary, err := s.db.GetWorkoutSummary(ctx, userID, time.Time{}, time.Now().Add(time.Minute))
+	if err != nil {
+		return nil, err
+	}
+
+	stats := map[string]interface{}{
+		"sessions":       summary.Sessions,
+		"total_sets":     summary.Sets,
+		"total_reps":     summary.Reps,
+		"tonnage":        summary.Tonnage,
+		"first_activity": nil,
+		"last_activity":  nil,
+	}
+	if summary.Sets > 0 {
+		stats["first_activity"] = summary.FirstAt
+		stats["last_activity"] = summary.LastAt
+	}
+
+	return stats, nil
+}
+
+// GetMyData — что бот хранит о пользователе; nil — пользователя ещё нет
+func (s *UserService) GetMyData(ctx context.Context, loc i18n.Locale, user *model.User) (string, error) {
+	if user == nil {
+		return loc.T("mydata.nothing"), nil
+	}
+
+	summary, err := s.db.GetWorkoutSummary(ctx, user.ID, time.Time{}, time.Now().Add(time.Minute))
+	if err != nil {
+		return "", err
+	}
+
+	customExercises, err := s.db.CountCustomExercises(ctx, user.ID)
+	if err != nil {
+		return "", err
+	}
+
+	var message strings.Builder
+	message.WriteString(loc.T("mydata.title"))
+	message.WriteString(loc.T("mydata.id", user.ChatID))
+	message.WriteString(loc.T("mydata.name", user.Username))
+	if !user.CreatedAt.IsZero() {
+		message.WriteString(loc.T("mydata.registered", user.CreatedAt.Format(loc.T("layout.date"))))
+	}
+	if language, ok := i18n.Parse(user.Language); ok {
+		message.WriteString(loc.T("mydata.language", language.Name()))
+	}
+	message.WriteString(loc.T("mydata.sets", summary.Sets))
+	message.WriteString(loc.T("mydata.custom", customExercises))
+	if summary.Sets > 0 {
+		message.WriteString(loc.T("mydata.first", summary.FirstAt.Format(loc.T("layout.datetime"))))
+		message.WriteString(loc.T("mydata.last", summary.LastAt.Format(loc.T("layout.datetime"))))
+	}
+	message.WriteString(loc.T("mydata.footer"))
+
+	return message.String(), nil
+}
+
+// Settings — настройки пользователя из /settings
+type Settings struct {
+	// Пустой — по языку Telegram
+	Language        string
+	BroadcastOptOut bool
+}
+
+// GetSettings — настройки пользователя; у незарегистрированного (nil) — по умолчанию
+func (s *UserService) GetSettings(user *model.User) Settings {
+	if user == nil {
+		return Settings{}
+	}
+	return Settings{Language: user.Language, BroadcastOptOut: user.BroadcastOptOut}
+}
+
+// SetLanguage сохраняет язык пользователя; пустая строка — выбирать по языку Telegram
+func (s *UserService) SetLanguage(ctx context.Context, user *model.User, language string) error {
+	if err := s.db.SetUserLanguage(ctx, user.ID, language); err != nil {
+		return fmt.Errorf("ошибка сохранения языка: %w", err)
+	}
+	return nil
+}
+
+// SetBroadcastOptOut отключает (optOut) или снова включает рассылки администратора
+func (s *UserService) SetBroadcastOptOut(ctx context.Context, user *model.User, optOut bool) error {
+	if err := s.db.SetBroadcastOptOut(ctx, user.ID, optOut); err != nil {
+		return fmt.Errorf("ошибка сохранения настройки рассылок: %w", err)
+	}
+	return nil
+}
+
+// DeleteAccount удаляет пользователя и все его данные. false — пользователя и так нет.
+func (s *UserService) DeleteAccount(ctx context.Context, user *model.User) (bool, error) {
+	if user == nil {
+		return false, nil
+	}
+
+	if err := s.db.DeleteUser(ctx, user.ID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,172 @@
+package workout
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"gofitness/src/database"
+	"gofitness/src/model"
+	"time"
+)
+
+// Ошибки, которые HTTP API превращает в 404 и 400
+var (
+	ErrNotFound = errors.New("не найдено")
+	ErrInvalid  = errors.New("некорректные данные")
+)
+
+// Ограничения на выборки через API
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+	DefaultDays  = 90
+	MaxDays      = 3650
+)
+
+// Repository — хранилище, нужное операциям с тренировками: упражнения и подходы
+type Repository interface {
+	database.ExerciseRepository
+	database.SetRepository
+}
+
+// WorkoutService — операции с тренировками пользователя по его внутреннему ID
+// (HTTP API знает пользователя по токену, а не по чату)
+type WorkoutService struct {
+	db Repository
+}
+
+func NewWorkoutService(db Repository) *WorkoutService {
+	return &WorkoutService{db: db}
+}
+
+// Exercises — стандартные и личные упражнения пользователя
+func (s *WorkoutService) Exercises(ctx context.Context, userID int64) ([]model.Exercise, error) {
+	return s.db.GetExercises(ctx, userID)
+}
+
+// Упражнение, доступное пользователю: стандартное или его личное
+func (s *WorkoutService) exercise(ctx context.Context, userID int64, exerciseID int) (*model.Exercise, error) {
+	ex, err := s.db.GetExerciseByID(ctx, exerciseID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !ex.IsStandard && ex.UserID != userID) {
+		return nil, fmt.Errorf("%w: упражнение %d", ErrNotFound, exerciseID)
+	}
+	return ex, err
+}
+
+// Sets — последние limit подходов, новые первыми
+func (s *WorkoutService) Sets(ctx context.Context, userID int64, limit int) ([]model.WorkoutSet, error) {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+	return s.db.GetUserWorkoutHistory(ctx, userID, limit)
+}
+
+func (s *WorkoutService) Set(ctx context.Context, userID int64, setID int) (*model.WorkoutSet, error) {
+	set, err := s.db.GetWorkoutSet(ctx, userID, setID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: подход %d", ErrNotFound, setID)
+	}
+	return set, err
+}
+
+// Проверяем подход так же, как бот при ручном вводе
+func (s *WorkoutService) validate(ctx context.Context, set model.WorkoutSet) error {
+	if set.Reps <= 0 {
+		return fmt.Errorf("%w: количество повторений должно быть больше 0", ErrInvalid)
+	}
+	if set.Weight < 0 {
+		return fmt.Errorf("%w: вес не может быть отрицательным", ErrInvalid)
+	}
+	if set.CreatedAt.After(time.Now().Add(time.Minute)) {
+		return fmt.Errorf("%w: время подхода в будущем", ErrInvalid)
+	}
+	if _, err := s.exercise(ctx, set.UserID, set.ExerciseID); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("%w: упражнение %d не найдено", ErrInvalid, set.ExerciseID)
+		}
+		return err
+	}
+	return nil
+}
+
+// CreateSet сохраняет подход; без времени — текущее
+func (s *WorkoutService) CreateSet(ctx context.Context, set model.WorkoutSet) (*model.WorkoutSet, error) {
+	if err := s.validate(ctx, set); err != nil {
+		return nil, err
+	}
+	return s.db.CreateWorkoutSet(ctx, set)
+}
+
+// UpdateSet заменяет подход целиком; без времени — прежнее
+func (s *WorkoutService) UpdateSet(ctx context.Context, set model.WorkoutSet) (*model.WorkoutSet, error) {
+	current, err := s.Set(ctx, set.UserID, set.ID)
+	if err != nil {
+		return nil, err
+	}
+	if set.CreatedAt.IsZero() {
+		set.CreatedAt = current.CreatedAt
+	}
+	if err := s.validate(ctx, set); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.UpdateWorkoutSet(ctx, set); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: подход %d", ErrNotFound, set.ID)
+		}
+		return nil, err
+	}
+	return s.Set(ctx, set.UserID, set.ID)
+}
+
+func (s *WorkoutService) DeleteSet(ctx context.Context, userID int64, setID int) error {
+	err := s.db.DeleteWorkoutSet(ctx, userID, setID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: подход %d", ErrNotFound, setID)
+	}
+	return err
+}
+
+// Progress — точки прогресса по упражнению за days дней
+func (s *WorkoutService) Progress(ctx context.Context, userID int64, exerciseID int, days int) (*model.Exercise, []model.ProgressPoint, error) {
+	if days <= 0 {
+		days = DefaultDays
+	}
+	if days > MaxDays {
+		days = MaxDays
+	}
+
+	ex, err := s.exercise(ctx, userID, exerciseID)
+	if err != nil {
+		return nil, nil, err
+	}
+	points, err := s.db.GetProgressByExercise(ctx, userID, exerciseID, days)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ex, points, nil
+}
+
+// Records — личные рекорды по каждому упражнению за всё время
+func (s *WorkoutService) Records(ctx context.Context, userID int64) ([]model.ExerciseBest, error) {
+	return s.db.GetExerciseBests(ctx, userID, time.Time{}, time.Now().Add(time.Minute))
+}
+
+// Summary — сводка тренировок за всё время
+func (s *WorkoutService) Summary(ctx context.Context, userID int64) (*model.WorkoutSummary, error) {
+	return s.db.GetWorkoutSummary(ctx, userID, time.Time{}, time.Now().Add(time.Minute))
+}
+
+// SetsBetween — подходы за [from, to) в хронологическом порядке
+func (s *WorkoutService) SetsBetween(ctx context.Context, userID int64, from, to time.Time) ([]model.WorkoutSet, error) {
+	return s.db.GetWorkoutSetsBetween(ctx, userID, from, to)
+}
+
+// Activity — тренировочные дни за [from, to)
+func (s *WorkoutService) Activity(ctx context.Context, userID int64, from, to time.Time) ([]model.DayActivity, error) {
+	return s.db.GetDailyActivity(ctx, userID, from, to)
+}
@@ -1,9 +1,21 @@
 package state
 
+import "gofitness/src/model"
+
 type UserState struct {
 	WaitingForReps     bool
 	WaitingForWeight   bool
 	CurrentExerciseID  int
 	CurrentExerciseName string
 	TempReps            int
-}
\ No newline at end of file
+	PendingImport       *model.ImportPlan // импорт CSV, ожидающий подтверждения
+	WaitingForRestore   bool              // следующий документ — резервная копия
+	WaitingForDeleteConfirm bool          // ждём подтверждения /deleteme
+	PendingBroadcast        string        // рассылка администратора, ожидающая подтверждения
+}
+
+// Active — пользователь посреди диалога: бот ждёт от него ответа
+func (s *UserState) Active() bool {
+	return s.WaitingForReps || s.WaitingForWeight || s.PendingImport != nil ||
+		s.WaitingForRestore || s.WaitingForDeleteConfirm || s.PendingBroadcast != ""
+}
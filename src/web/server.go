@@ -0,0 +1,414 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"gofitness/src/database"
+	"gofitness/src/i18n"
+	"gofitness/src/model"
+	"gofitness/src/service/history"
+	"gofitness/src/service/session"
+	"gofitness/src/service/workout"
+	"html/template"
+	"io/fs"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Шаблоны страниц и статика встроены в бинарник
+//
+//go:embed templates static
+var files embed.FS
+
+// Имя cookie с идентификатором сессии
+const sessionCookie = "gf_session"
+
+// Server — веб-кабинет: история, прогресс, рекорды и календарь в браузере.
+// Вход — по одноразовой ссылке из команды /web.
+type Server struct {
+	sessions *session.SessionService
+	workouts *workout.WorkoutService
+	pages    map[i18n.Locale]map[string]*template.Template
+	mux      *http.ServeMux
+}
+
+// Функции шаблонов на языке loc: шаблоны разбираются отдельно для каждого языка
+func templateFuncs(loc i18n.Locale) template.FuncMap {
+	return template.FuncMap{
+		"t":           loc.T,
+		"date":        func(t time.Time) string { return t.Format(loc.T("layout.date")) },
+		"datetime":    func(t time.Time) string { return t.Format(loc.T("layout.datetime")) },
+		"clock":       func(t time.Time) string { return t.Format("15:04") },
+		"weight":      func(w float64) string { return formatWeight(loc, w) },
+		"number":      formatNumber,
+		"exercise":    loc.Exercise,
+		"description": loc.ExerciseDescription,
+		"muscles": func(groups []model.MuscleGroup) string {
+			titles := make([]string, 0, len(groups))
+			for _, g := range groups {
+				titles = append(titles, loc.Muscle(g))
+			}
+			return strings.Join(titles, ", ")
+		},
+	}
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', 0, 64)
+}
+
+// Вес с точностью до 0.1 без лишних нулей; 0 — без отягощения
+func formatWeight(loc i18n.Locale, w float64) string {
+	if w == 0 {
+		return "—"
+	}
+	return loc.T("web.kg", strconv.FormatFloat(math.Round(w*10)/10, 'f', -1, 64))
+}
+
+// Язык страницы: из настроек пользователя, иначе по заголовку Accept-Language браузера
+func locale(r *http.Request, user *model.User) i18n.Locale {
+	if user != nil {
+		if loc, ok := i18n.Parse(user.Language); ok {
+			return loc
+		}
+	}
+	lang, _, _ := strings.Cut(r.Header.Get("Accept-Language"), ",")
+	lang, _, _ = strings.Cut(lang, ";")
+	return i18n.Detect(strings.TrimSpace(lang))
+}
+
+func NewServer(db workout.Repository, sessions *session.SessionService) *Server {
+	s := &Server{
+		sessions: sessions,
+		workouts: workout.NewWorkoutService(db),
+		pages:    make(map[i18n.Locale]map[string]*template.Template),
+		mux:      http.NewServeMux(),
+	}
+
+	// Каждая страница — отдельный набор из общего макета и своего содержимого
+	for _, loc := range i18n.Locales {
+		s.pages[loc] = make(map[string]*template.Template)
+		for _, page := range []string{"message", "login", "dashboard", "history", "exercise", "calendar"} {
+			s.pages[loc][page] = template.Must(template.New("layout.html").Funcs(templateFuncs(loc)).
+				ParseFS(files, "templates/layout.html", "templates/"+page+".html"))
+		}
+	}
+
+	static, err := fs.Sub(files, "static")
+	if err != nil {
+		panic(err)
+	}
+	s.mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.FS(static))))
+	s.mux.HandleFunc("/login", s.login)
+	s.mux.HandleFunc("/logout", s.logout)
+	s.mux.HandleFunc("/", s.authenticated(s.dashboard))
+	s.mux.HandleFunc("/history", s.authenticated(s.history))
+	s.mux.HandleFunc("/calendar", s.authenticated(s.calendar))
+	s.mux.HandleFunc("/exercises/", s.authenticated(s.exercise))
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Рендерим страницу целиком в буфер, чтобы при ошибке шаблона не отдать половину HTML
+func (s *Server) render(w http.ResponseWriter, loc i18n.Locale, status int, page string, data map[string]interface{}) {
+	data["Lang"] = string(loc)
+
+	var buf bytes.Buffer
+	if err := s.pages[loc][page].Execute(&buf, data); err != nil {
+		slog.Error("Ошибка шаблона", "page", page, "err", err)
+		http.Error(w, loc.T("web.internal_error"), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
+
+// Страница с заголовком и текстом сообщений titleKey и textKey
+func (s *Server) message(w http.ResponseWriter, loc i18n.Locale, status int, titleKey, textKey string, args ...interface{}) {
+	s.render(w, loc, status, "message", map[string]interface{}{"Title": loc.T(titleKey), "Text": loc.T(textKey, args...)})
+}
+
+func (s *Server) notFound(w http.ResponseWriter, loc i18n.Locale) {
+	s.message(w, loc, http.StatusNotFound, "web.not_found.title", "web.not_found")
+}
+
+// Страница ошибки хранилища
+func (s *Server) fail(w http.ResponseWriter, loc i18n.Locale, err error) {
+	switch {
+	case errors.Is(err, workout.ErrNotFound):
+		s.notFound(w, loc)
+	case errors.Is(err, database.ErrTimeout) || errors.Is(err, context.DeadlineExceeded):
+		w.Header().Set("Retry-After", "60")
+		s.message(w, loc, http.StatusServiceUnavailable, "web.busy.title", "web.busy")
+	default:
+		slog.Error("Ошибка веб-кабинета", "err", err)
+		s.message(w, loc, http.StatusInternalServerError, "web.failed.title", "web.failed")
+	}
+}
+
+// Пускаем только пользователей с действующей сессией
+func (s *Server) authenticated(next func(w http.ResponseWriter, r *http.Request, user *model.User, loc i18n.Locale)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, locale(r, nil).T("web.method_not_allowed"), http.StatusMethodNotAllowed)
+			return
+		}
+
+		var user *model.User
+		if cookie, err := r.Cookie(sessionCookie); err == nil {
+			user, err = s.sessions.User(r.Context(), cookie.Value)
+			if err != nil {
+				s.fail(w, locale(r, nil), err)
+				return
+			}
+		}
+		if user == nil {
+			s.message(w, locale(r, nil), http.StatusUnauthorized, "web.login.nav", "web.login.required")
+			return
+		}
+
+		w.Header().Set("Cache-Control", "private, no-store")
+		next(w, r, user, locale(r, user))
+	}
+}
+
+// GET показывает кнопку входа, POST обменивает код на сессию.
+// Код не тратится на GET, чтобы его не съел предпросмотр ссылки в Telegram.
+func (s *Server) login(w http.ResponseWriter, r *http.Request) {
+	loc := locale(r, nil)
+	switch r.Method {
+	case http.MethodGet:
+		s.render(w, loc, http.StatusOK, "login", map[string]interface{}{"Code": r.URL.Query().Get("code")})
+	case http.MethodPost:
+		id, ok := s.sessions.Login(r.PostFormValue("code"))
+		if !ok {
+			s.message(w, loc, http.StatusUnauthorized, "web.login.expired.title", "web.login.expired")
+			return
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name:     sessionCookie,
+			Value:    id,
+			Path:     "/",
+			MaxAge:   int(session.SessionTTL.Seconds()),
+			HttpOnly: true,
+			Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+			SameSite: http.SameSiteLaxMode,
+		})
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, loc.T("web.method_not_allowed"), http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) logout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, locale(r, nil).T("web.method_not_allowed"), http.StatusMethodNotAllowed)
+		return
+	}
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		s.sessions.Logout(cookie.Value)
+	}
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Path: "/", MaxAge: -1, HttpOnly: true})
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func (s *Server) dashboard(w http.ResponseWriter, r *http.Request, user *model.User, loc i18n.Locale) {
+	if r.URL.Path != "/" {
+		s.notFound(w, loc)
+		return
+	}
+
+	ctx := r.Context()
+	summary, err := s.workouts.Summary(ctx, user.ID)
+	if err != nil {
+		s.fail(w, loc, err)
+		return
+	}
+	records, err := s.workouts.Records(ctx, user.ID)
+	if err != nil {
+		s.fail(w, loc, err)
+		return
+	}
+	recent, err := s.workouts.Sets(ctx, user.ID, 20)
+	if err != nil {
+		s.fail(w, loc, err)
+		return
+	}
+
+	s.render(w, loc, http.StatusOK, "dashboard", map[string]interface{}{
+		"Title":   loc.T("web.nav.overview"),
+		"User":    user,
+		"Summary": summary,
+		"Records": records,
+		"Recent":  recent,
+	})
+}
+
+// Подходы одного дня
+type day struct {
+	Date    time.Time
+	Sets    []model.WorkoutSet
+	Reps    int
+	Tonnage float64
+}
+
+func (s *Server) history(w http.ResponseWriter, r *http.Request, user *model.User, loc i18n.Locale) {
+	now := time.Now()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	if raw := r.URL.Query().Get("month"); raw != "" {
+		parsed, err := time.ParseInLocation("2006-01", raw, time.Local)
+		if err != nil {
+			s.message(w, loc, http.StatusBadRequest, "web.failed.title", "web.bad_month")
+			return
+		}
+		month = parsed
+	}
+	next := month.AddDate(0, 1, 0)
+
+	sets, err := s.workouts.SetsBetween(r.Context(), user.ID, month, next)
+	if err != nil {
+		s.fail(w, loc, err)
+		return
+	}
+
+	// Группируем по дням, свежие дни сверху
+	var days []*day
+	for _, set := range sets {
+		y, m, d := set.CreatedAt.Date()
+		date := time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+		if len(days) == 0 || !days[0].Date.Equal(date) {
+			days = append([]*day{{Date: date}}, days...)
+		}
+		days[0].Sets = append(days[0].Sets, set)
+		days[0].Reps += set.Reps
+		days[0].Tonnage += set.Weight * float64(set.Reps)
+	}
+
+	data := map[string]interface{}{
+		"Title": loc.T("web.nav.history"),
+		"User":  user,
+		"Month": fmt.Sprintf("%s %d", loc.List("months.full")[month.Month()-1], month.Year()),
+		"Days":  days,
+		"Sets":  len(sets),
+		"Prev":  month.AddDate(0, -1, 0).Format("2006-01"),
+	}
+	if next.Before(now) {
+		data["Next"] = next.Format("2006-01")
+	}
+	s.render(w, loc, http.StatusOK, "history", data)
+}
+
+// Периоды графика прогресса; Title — ключ сообщения
+var progressPeriods = []struct {
+	Days  int
+	Title string
+}{
+	{30, "web.period.month"},
+	{90, "web.period.quarter"},
+	{180, "web.period.half_year"},
+	{365, "web.period.year"},
+	{workout.MaxDays, "web.period.all"},
+}
+
+func (s *Server) exercise(w http.ResponseWriter, r *http.Request, user *model.User, loc i18n.Locale) {
+	id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/exercises/"))
+	if err != nil || id <= 0 {
+		s.notFound(w, loc)
+		return
+	}
+	days, err := strconv.Atoi(r.URL.Query().Get("days"))
+	if err != nil || days <= 0 || days > workout.MaxDays {
+		days = workout.DefaultDays
+	}
+
+	ex, points, err := s.workouts.Progress(r.Context(), user.ID, id, days)
+	if err != nil {
+		s.fail(w, loc, err)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Title":    loc.Exercise(ex.Name),
+		"User":     user,
+		"Exercise": ex,
+		"Days":     days,
+		"Periods":  progressPeriods,
+	}
+	if buf, err := history.GenerateProgressChartSVG(loc, points, loc.Exercise(ex.Name)); err == nil {
+		data["Chart"] = template.HTML(buf.String())
+	}
+
+	// Таблица — свежие дни сверху
+	rows := make([]model.ProgressPoint, 0, len(points))
+	for i := len(points) - 1; i >= 0; i-- {
+		rows = append(rows, points[i])
+	}
+	data["Points"] = rows
+
+	s.render(w, loc, http.StatusOK, "exercise", data)
+}
+
+func (s *Server) calendar(w http.ResponseWriter, r *http.Request, user *model.User, loc i18n.Locale) {
+	now := time.Now()
+	year := now.Year()
+	if raw := r.URL.Query().Get("year"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 2000 || parsed > year {
+			s.message(w, loc, http.StatusBadRequest, "web.failed.title", "web.bad_year", year)
+			return
+		}
+		year = parsed
+	}
+	bySets := r.URL.Query().Get("by") == "sets"
+
+	// Серии считаем по всей истории, как в команде /calendar
+	days, err := s.workouts.Activity(r.Context(), user.ID, time.Time{}, now.AddDate(0, 0, 1))
+	if err != nil {
+		s.fail(w, loc, err)
+		return
+	}
+
+	trainingDays := 0
+	for _, d := range days {
+		if d.Date.Year() == year {
+			trainingDays++
+		}
+	}
+	current, longest := history.TrainingStreaks(days, now)
+
+	data := map[string]interface{}{
+		"Title":        loc.T("web.nav.calendar"),
+		"User":         user,
+		"Year":         year,
+		"BySets":       bySets,
+		"TrainingDays": trainingDays,
+		"Current":      current,
+		"Longest":      longest,
+		"Prev":         year - 1,
+	}
+	if year < now.Year() {
+		data["Next"] = year + 1
+	}
+	buf, err := history.GenerateCalendarHeatmapSVG(loc, days, year, bySets)
+	if err != nil {
+		s.fail(w, loc, err)
+		return
+	}
+	data["Chart"] = template.HTML(buf.String())
+
+	s.render(w, loc, http.StatusOK, "calendar", data)
+}
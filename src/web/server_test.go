@@ -0,0 +1,258 @@
+package web
+
+import (
+	"context"
+	"gofitness/src/database"
+	"gofitness/src/model"
+	"gofitness/src/service/session"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Тестовое окружение: веб-кабинет поверх хранилища в памяти
+type harness struct {
+	t        *testing.T
+	db       *database.Memory
+	sessions *session.SessionService
+	server   *Server
+	user     *model.User
+}
+
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+	db := database.NewMemory()
+	if err := db.Init(); err != nil {
+		t.Fatalf("не удалось подготовить хранилище: %v", err)
+	}
+	user, err := db.GetOrCreateUser(context.Background(), 100, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessions := session.NewSessionService(db, "https://fit.example.com")
+	return &harness{t: t, db: db, sessions: sessions, server: NewServer(db, sessions), user: user}
+}
+
+// Код из одноразовой ссылки, которую бот отправляет по команде /web
+func (h *harness) code() string {
+	h.t.Helper()
+	link, err := h.sessions.LoginURL(h.user)
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	parsed, err := url.Parse(link)
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	return parsed.Query().Get("code")
+}
+
+func (h *harness) do(req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.server.ServeHTTP(rec, req)
+	return rec
+}
+
+// POST /login с кодом
+func (h *harness) login(code string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(url.Values{"code": {code}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return h.do(req)
+}
+
+// Входим по свежему коду и возвращаем cookie сессии
+func (h *harness) session() *http.Cookie {
+	h.t.Helper()
+	rec := h.login(h.code())
+	if rec.Code != http.StatusSeeOther {
+		h.t.Fatalf("POST /login: код %d, ожидался 303", rec.Code)
+	}
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == sessionCookie && c.Value != "" {
+			return c
+		}
+	}
+	h.t.Fatalf("POST /login не выставил cookie %s", sessionCookie)
+	return nil
+}
+
+// GET страницы кабинета с cookie сессии
+func (h *harness) get(path string, cookie *http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	return h.do(req)
+}
+
+func TestLogin(t *testing.T) {
+	h := newHarness(t)
+	code := h.code()
+
+	// Предпросмотр ссылки в Telegram делает GET — код не должен потратиться
+	for i := 0; i < 2; i++ {
+		rec := h.get("/login?code="+url.QueryEscape(code), nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET /login: код %d, ожидался 200", rec.Code)
+		}
+		if !strings.Contains(rec.Body.String(), code) {
+			t.Fatalf("форма входа не содержит код")
+		}
+	}
+
+	rec := h.login(code)
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/" {
+		t.Fatalf("POST /login: код %d, Location %q", rec.Code, rec.Header().Get("Location"))
+	}
+	var cookie *http.Cookie
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == sessionCookie {
+			cookie = c
+		}
+	}
+	if cookie == nil || !cookie.HttpOnly {
+		t.Fatalf("нет HttpOnly cookie сессии: %+v", cookie)
+	}
+	if rec := h.get("/", cookie); rec.Code != http.StatusOK {
+		t.Fatalf("кабинет после входа: код %d, ожидался 200", rec.Code)
+	}
+
+	// Повторно тот же код не принимается
+	if rec := h.login(code); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("повторный POST /login: код %d, ожидался 401", rec.Code)
+	}
+	if rec := h.login("unknown"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("неизвестный код: код %d, ожидался 401", rec.Code)
+	}
+}
+
+func TestUnauthorized(t *testing.T) {
+	h := newHarness(t)
+
+	tests := []struct {
+		name   string
+		cookie *http.Cookie
+	}{
+		{"без cookie", nil},
+		{"неизвестная сессия", &http.Cookie{Name: sessionCookie, Value: "unknown"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, path := range []string{"/", "/history", "/calendar"} {
+				if rec := h.get(path, tt.cookie); rec.Code != http.StatusUnauthorized {
+					t.Errorf("GET %s: код %d, ожидался 401", path, rec.Code)
+				}
+			}
+		})
+	}
+}
+
+func TestLogout(t *testing.T) {
+	h := newHarness(t)
+	cookie := h.session()
+
+	req := httptest.NewRequest(http.MethodPost, "/logout", nil)
+	req.AddCookie(cookie)
+	if rec := h.do(req); rec.Code != http.StatusSeeOther {
+		t.Fatalf("POST /logout: код %d, ожидался 303", rec.Code)
+	}
+	if rec := h.get("/", cookie); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("после выхода: код %d, ожидался 401", rec.Code)
+	}
+}
+
+func TestSessionDropped(t *testing.T) {
+	tests := []struct {
+		name string
+		drop func(h *harness) error
+	}{
+		{"блокировка", func(h *harness) error {
+			return h.db.SetUserBanned(context.Background(), h.user.ID, true)
+		}},
+		{"удаление аккаунта", func(h *harness) error {
+			return h.db.DeleteUser(context.Background(), h.user.ID)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHarness(t)
+			cookie := h.session()
+			if rec := h.get("/", cookie); rec.Code != http.StatusOK {
+				t.Fatalf("до блокировки: код %d, ожидался 200", rec.Code)
+			}
+
+			if err := tt.drop(h); err != nil {
+				t.Fatal(err)
+			}
+			if rec := h.get("/", cookie); rec.Code != http.StatusUnauthorized {
+				t.Fatalf("код %d, ожидался 401", rec.Code)
+			}
+		})
+	}
+}
+
+func TestQueryValidation(t *testing.T) {
+	h := newHarness(t)
+	cookie := h.session()
+	year := time.Now().Year()
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/history", http.StatusOK},
+		{"/history?month=2024-03", http.StatusOK},
+		{"/history?month=2024-13", http.StatusBadRequest},
+		{"/history?month=03.2024", http.StatusBadRequest},
+		{"/history?month=март", http.StatusBadRequest},
+		{"/calendar", http.StatusOK},
+		{"/calendar?year=2000", http.StatusOK},
+		{"/calendar?year=" + strconv.Itoa(year), http.StatusOK},
+		{"/calendar?year=1999", http.StatusBadRequest},
+		{"/calendar?year=" + strconv.Itoa(year+1), http.StatusBadRequest},
+		{"/calendar?year=abc", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if rec := h.get(tt.path, cookie); rec.Code != tt.status {
+				t.Fatalf("код %d, ожидался %d", rec.Code, tt.status)
+			}
+		})
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	h := newHarness(t)
+	cookie := h.session()
+
+	tests := []struct {
+		method string
+		path   string
+		allow  string
+	}{
+		{http.MethodPost, "/history", "GET, HEAD"},
+		{http.MethodDelete, "/login", "GET, POST"},
+		{http.MethodGet, "/logout", "POST"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.AddCookie(cookie)
+			rec := h.do(req)
+			if rec.Code != http.StatusMethodNotAllowed {
+				t.Fatalf("код %d, ожидался 405", rec.Code)
+			}
+			if got := rec.Header().Get("Allow"); got != tt.allow {
+				t.Errorf("Allow %q, ожидался %q", got, tt.allow)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package webhook
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sync"
+	"time"
+
+	"gofitness/src/logging"
+
+	"gopkg.in/telebot.v3"
+)
+
+// Заголовок, в котором Telegram передаёт секрет, указанный при setWebhook
+const secretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// Максимальный размер одного обновления
+const maxUpdateSize = 1 << 20
+
+// Пауза между попытками зарегистрировать вебхук
+const retryInterval = 10 * time.Second
+
+// Допустимый секрет по правилам Telegram: 1–256 символов A-Z, a-z, 0-9, _ и -
+var secretPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+// Config — настройки режима вебхука
+type Config struct {
+	// Адрес, на котором слушать, например :8443. Пустой — обработчик
+	// подключается к общему HTTP-серверу бота по пути из PublicURL.
+	Listen string
+	// Публичный HTTPS-адрес, на который Telegram отправляет обновления
+	// (обычно адрес на reverse proxy)
+	PublicURL string
+	// Секрет, который Telegram присылает в каждом запросе
+	SecretToken string
+	// Сертификат и ключ для TLS на Listen; оба пустые — обычный HTTP
+	TLSCert string
+	TLSKey  string
+}
+
+func (c Config) Validate() error {
+	u, err := url.Parse(c.PublicURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("публичный адрес вебхука должен быть вида https://host/path, получено %q", c.PublicURL)
+	}
+	if !secretPattern.MatchString(c.SecretToken) {
+		return errors.New("секрет вебхука обязателен: 1–256 символов A-Z, a-z, 0-9, _ и -")
+	}
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		return errors.New("для TLS нужны и сертификат, и ключ")
+	}
+	return nil
+}
+
+// Poller получает обновления от Telegram через вебхук вместо long polling.
+// Реализует telebot.Poller и http.Handler.
+type Poller struct {
+	cfg  Config
+	path string
+
+	mu   sync.RWMutex
+	dest chan<- telebot.Update
+	stop chan struct{}
+}
+
+func New(cfg Config) (*Poller, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	u, _ := url.Parse(cfg.PublicURL)
+	path := u.Path
+	if path == "" || path == "/" {
+		if cfg.Listen == "" {
+			return nil, errors.New("вебхук на общем HTTP-сервере требует путь в публичном адресе, например https://host/telegram/webhook")
+		}
+		path = "/"
+	}
+	return &Poller{cfg: cfg, path: path}, nil
+}
+
+// Path — путь, по которому ожидаются обновления
+func (p *Poller) Path() string {
+	return p.path
+}
+
+// Listen — собственный адрес вебхука; пустой — используется общий HTTP-сервер
+func (p *Poller) Listen() string {
+	return p.cfg.Listen
+}
+
+// Poll регистрирует вебхук в Telegram и передаёт обновления боту до остановки
+func (p *Poller) Poll(b *telebot.Bot, dest chan telebot.Update, stop chan struct{}) {
+	hook := &telebot.Webhook{
+		SecretToken: p.cfg.SecretToken,
+		Endpoint:    &telebot.WebhookEndpoint{PublicURL: p.cfg.PublicURL},
+	}
+	for {
+		err := b.SetWebhook(hook)
+		if err == nil {
+			break
+		}
+		slog.Warn("Не удалось зарегистрировать вебхук", "err", err, "retry_in", retryInterval)
+		select {
+		case <-stop:
+			return
+		case <-time.After(retryInterval):
+		}
+	}
+	slog.Info("Вебхук зарегистрирован", "url", p.cfg.PublicURL)
+
+	p.mu.Lock()
+	p.dest, p.stop = dest, stop
+	p.mu.Unlock()
+
+	if p.cfg.Listen == "" {
+		<-stop
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(p.path, p)
+	server := &http.Server{
+		Addr:              p.cfg.Listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		var err error
+		if p.cfg.TLSCert != "" {
+			err = server.ListenAndServeTLS(p.cfg.TLSCert, p.cfg.TLSKey)
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logging.Fatal("Сервер вебхука остановился", "err", err)
+		}
+	}()
+
+	<-stop
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	server.Shutdown(ctx)
+}
+
+// ServeHTTP принимает одно обновление от Telegram
+func (p *Poller) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != p.path {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	secret := r.Header.Get(secretHeader)
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(p.cfg.SecretToken)) != 1 {
+		http.Error(w, "invalid secret token", http.StatusUnauthorized)
+		return
+	}
+
+	var update telebot.Update
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxUpdateSize)).Decode(&update); err != nil {
+		http.Error(w, "cannot decode update", http.StatusBadRequest)
+		return
+	}
+
+	p.mu.RLock()
+	dest, stop := p.dest, p.stop
+	p.mu.RUnlock()
+
+	// Пока вебхук не зарегистрирован или бот останавливается, Telegram повторит запрос позже
+	if dest == nil {
+		http.Error(w, "bot is not ready", http.StatusServiceUnavailable)
+		return
+	}
+	select {
+	case dest <- update:
+		w.WriteHeader(http.StatusOK)
+	case <-stop:
+		http.Error(w, "bot is stopping", http.StatusServiceUnavailable)
+	case <-r.Context().Done():
+	}
+}
@@ -0,0 +1,207 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/telebot.v3"
+)
+
+const testSecret = "test_secret-123"
+
+// Поддельный Bot API: запоминает параметры setWebhook и отвечает успехом
+func fakeTelegram(t *testing.T, registered chan<- map[string]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/setWebhook") {
+			params := make(map[string]string)
+			if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+				t.Errorf("setWebhook: %v", err)
+			}
+			registered <- params
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+}
+
+// Запускаем бота с вебхуком и ждём его регистрации
+func startBot(t *testing.T) (*Poller, map[string]string, chan *telebot.Message) {
+	t.Helper()
+
+	registered := make(chan map[string]string, 1)
+	api := fakeTelegram(t, registered)
+	t.Cleanup(api.Close)
+
+	hook, err := New(Config{PublicURL: "https://bot.example.com/telegram/webhook", SecretToken: testSecret})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := telebot.NewBot(telebot.Settings{
+		URL:     api.URL,
+		Token:   "123456:TEST",
+		Poller:  hook,
+		Offline: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	received := make(chan *telebot.Message, 2)
+	b.Handle("/start", func(c telebot.Context) error {
+		received <- c.Message()
+		return nil
+	})
+	b.Handle(telebot.OnText, func(c telebot.Context) error {
+		received <- c.Message()
+		return nil
+	})
+
+	// Бота не останавливаем: Stop в telebot v3.1.3 гонится с горутиной,
+	// которую Raw запускает для отмены запроса, и -race роняет тест
+	go b.Start()
+
+	select {
+	case params := <-registered:
+		return hook, params, received
+	case <-time.After(5 * time.Second):
+		t.Fatal("вебхук не зарегистрирован")
+	}
+	return nil, nil, nil
+}
+
+func postUpdate(t *testing.T, hook *Poller, file, secret string) int {
+	t.Helper()
+
+	body, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Регистрация завершается чуть позже ответа setWebhook: до этого — 503
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		req := httptest.NewRequest(http.MethodPost, "/telegram/webhook", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if secret != "" {
+			req.Header.Set(secretHeader, secret)
+		}
+		rec := httptest.NewRecorder()
+		hook.ServeHTTP(rec, req)
+		if rec.Code != http.StatusServiceUnavailable || time.Now().After(deadline) {
+			return rec.Code
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWebhookRegistersPublicURLAndSecret(t *testing.T) {
+	_, params, _ := startBot(t)
+
+	if params["url"] != "https://bot.example.com/telegram/webhook" {
+		t.Errorf("url = %q", params["url"])
+	}
+	if params["secret_token"] != testSecret {
+		t.Errorf("secret_token = %q", params["secret_token"])
+	}
+}
+
+func TestWebhookDeliversRecordedUpdates(t *testing.T) {
+	hook, _, received := startBot(t)
+
+	cases := []struct {
+		file     string
+		updateID int
+		text     string
+	}{
+		{"testdata/update_start.json", 873204911, "/start"},
+		{"testdata/update_set.json", 873204912, "Жим лежа 80 5"},
+	}
+	for _, tc := range cases {
+		if code := postUpdate(t, hook, tc.file, testSecret); code != http.StatusOK {
+			t.Fatalf("%s: статус %d, ожидался 200", tc.file, code)
+		}
+
+		select {
+		case msg := <-received:
+			if msg.Text != tc.text {
+				t.Errorf("%s: текст %q, ожидался %q", tc.file, msg.Text, tc.text)
+			}
+			if msg.Sender.ID != 100200300 || msg.Chat.Type != telebot.ChatPrivate {
+				t.Errorf("%s: отправитель %d, чат %s", tc.file, msg.Sender.ID, msg.Chat.Type)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s: обработчик не вызван", tc.file)
+		}
+	}
+}
+
+func TestWebhookRejectsWrongSecret(t *testing.T) {
+	hook, _, received := startBot(t)
+
+	for _, secret := range []string{"", "wrong"} {
+		if code := postUpdate(t, hook, "testdata/update_start.json", secret); code != http.StatusUnauthorized {
+			t.Errorf("секрет %q: статус %d, ожидался 401", secret, code)
+		}
+	}
+
+	select {
+	case msg := <-received:
+		t.Fatalf("обновление с неверным секретом обработано: %q", msg.Text)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWebhookRejectsBadRequests(t *testing.T) {
+	hook, _, _ := startBot(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/telegram/webhook", nil)
+	rec := httptest.NewRecorder()
+	hook.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET: статус %d, ожидался 405", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/telegram/webhook", strings.NewReader("{"))
+	req.Header.Set(secretHeader, testSecret)
+	rec = httptest.NewRecorder()
+	hook.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("битый JSON: статус %d, ожидался 400", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/other", strings.NewReader("{}"))
+	req.Header.Set(secretHeader, testSecret)
+	rec = httptest.NewRecorder()
+	hook.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("другой путь: статус %d, ожидался 404", rec.Code)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := Config{PublicURL: "https://bot.example.com/hook", SecretToken: testSecret}
+	if _, err := New(valid); err != nil {
+		t.Fatalf("корректная конфигурация: %v", err)
+	}
+
+	invalid := map[string]Config{
+		"http":              {PublicURL: "http://bot.example.com/hook", SecretToken: testSecret},
+		"без секрета":       {PublicURL: "https://bot.example.com/hook"},
+		"плохой секрет":     {PublicURL: "https://bot.example.com/hook", SecretToken: "a b"},
+		"только ключ":       {PublicURL: "https://bot.example.com/hook", SecretToken: testSecret, TLSKey: "key.pem"},
+		"корень без Listen": {PublicURL: "https://bot.example.com", SecretToken: testSecret},
+	}
+	for name, cfg := range invalid {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("%s: ожидалась ошибка", name)
+		}
+	}
+}